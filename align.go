@@ -0,0 +1,39 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// Align returns for every element of a the position of the element of b
+// it is matched with, or -1 if it was deleted. See Alignment for the
+// inverse mapping.
+func Align(n, m int, data Data, opts ...Option) []int {
+	ab, _ := Alignment(n, m, Diff(n, m, data, opts...))
+	return ab
+}
+
+// Alignment returns the matched pairs of the sequences with lengths n and m
+// given the changes from a to b. ab holds for every element of a the
+// position of its match in b and ba for every element of b the position of
+// its match in a, or -1 for deleted and inserted elements.
+// The changes must be ordered by ascending positions.
+func Alignment(n, m int, changes []Change) (ab, ba []int) {
+	ab, ba = make([]int, n), make([]int, m)
+	x, y := 0, 0
+	match := func(to int) {
+		for ; x < to; x, y = x+1, y+1 {
+			ab[x], ba[y] = y, x
+		}
+	}
+	for _, c := range changes {
+		match(c.A)
+		for ; x < c.A+c.Del; x++ {
+			ab[x] = -1
+		}
+		for ; y < c.B+c.Ins; y++ {
+			ba[y] = -1
+		}
+	}
+	match(n)
+	return ab, ba
+}
@@ -0,0 +1,50 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestAlign(t *testing.T) {
+	a := []int{1, 2, 3, 4}
+	b := []int{0, 1, 3, 4, 5}
+	ab := diff.Align(len(a), len(b), &ints{a, b})
+	if expect := []int{1, -1, 2, 3}; !reflect.DeepEqual(ab, expect) {
+		t.Error("expected", expect, "got", ab)
+	}
+	_, ba := diff.Alignment(len(a), len(b), diff.Ints(a, b))
+	if expect := []int{-1, 0, 2, 3, -1}; !reflect.DeepEqual(ba, expect) {
+		t.Error("expected", expect, "got", ba)
+	}
+}
+
+func TestAlignmentRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a := randomInts(r, r.Intn(20), 4)
+		b := randomInts(r, r.Intn(20), 4)
+		ab, ba := diff.Alignment(len(a), len(b), diff.Ints(a, b))
+		last := -1
+		for x, y := range ab {
+			if y < 0 {
+				continue
+			}
+			if a[x] != b[y] || ba[y] != x || y <= last {
+				t.Fatal(a, b, "invalid alignment", ab, ba)
+			}
+			last = y
+		}
+		for y, x := range ba {
+			if x >= 0 && ab[x] != y {
+				t.Fatal(a, b, "alignments disagree", ab, ba)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// A Job is one input of All: data with lengths N and M, and options that
+// apply to this job in addition to the ones passed to All.
+type Job struct {
+	N, M    int
+	Data    Data
+	Options []Option
+}
+
+// A Result holds the changes of a Job and the reason its diff stopped
+// early, like Differ.Err.
+type Result struct {
+	Changes []Change
+	Err     error
+}
+
+// All diffs the jobs concurrently with at most workers goroutines, or
+// GOMAXPROCS if workers is not positive, and returns their results in the
+// same order. Each worker reuses one Differ for all its jobs.
+// If the context of WithContext is canceled, the jobs that were not
+// started yet are not diffed and their results hold its error.
+func All(jobs []Job, workers int, opts ...Option) []Result {
+	results := make([]Result, len(jobs))
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			d := new(Differ)
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(jobs) {
+					return
+				}
+				results[i] = d.job(jobs[i], opts)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// job diffs j with opts followed by the options of j.
+func (d *Differ) job(j Job, opts []Option) Result {
+	d.opts = options{}
+	for _, opt := range opts {
+		opt(&d.opts)
+	}
+	for _, opt := range j.Options {
+		opt(&d.opts)
+	}
+	if ctx := d.opts.ctx; ctx != nil && ctx.Err() != nil {
+		return Result{Err: ctx.Err()}
+	}
+	changes := d.Diff(j.N, j.M, j.Data)
+	return Result{Changes: changes, Err: d.Err()}
+}
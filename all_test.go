@@ -0,0 +1,56 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestAll(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	jobs := make([]diff.Job, 100)
+	for i := range jobs {
+		a := randomInts(r, r.Intn(40), 5)
+		b := randomInts(r, r.Intn(40), 5)
+		jobs[i] = diff.Job{N: len(a), M: len(b), Data: &ints{a, b}}
+	}
+	// the options of a job add to the shared ones
+	jobs[3].Options = []diff.Option{diff.WithTieBreak(diff.InsertionsFirst)}
+	for _, workers := range []int{0, 1, 4, 1000} {
+		results := diff.All(jobs, workers, diff.WithTieBreak(diff.DeletionsFirst))
+		if len(results) != len(jobs) {
+			t.Fatal("expected", len(jobs), "results, got", len(results))
+		}
+		for i, res := range results {
+			j := jobs[i]
+			expect := diff.Diff(j.N, j.M, j.Data, append([]diff.Option{diff.WithTieBreak(diff.DeletionsFirst)}, j.Options...)...)
+			if res.Err != nil || !diffsEqual(res.Changes, expect) {
+				t.Fatal(workers, "workers: job", i, "expected", expect, "got", res.Changes, res.Err)
+			}
+		}
+	}
+	if res := diff.All(nil, 4); len(res) != 0 {
+		t.Error("expected no results, got", res)
+	}
+}
+
+func TestAllCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	a, b := []int{1, 2, 3}, []int{1, 3, 4}
+	jobs := []diff.Job{
+		{N: len(a), M: len(b), Data: &ints{a, b}},
+		{N: len(b), M: len(a), Data: &ints{b, a}},
+	}
+	for i, res := range diff.All(jobs, 2, diff.WithContext(ctx)) {
+		if res.Err != context.Canceled || res.Changes != nil {
+			t.Error("job", i, "expected to be canceled, got", res.Changes, res.Err)
+		}
+	}
+}
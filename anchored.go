@@ -0,0 +1,72 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "sort"
+
+// Anchored returns the differences of data like Diff, but keeps the given
+// anchor elements in common regions where possible, like git's --anchored.
+// Anchors are indices into the first sequence. An anchor is only used if it
+// equals exactly one element of the second sequence; the caller is expected
+// to pass anchors that are unique in the first sequence as well.
+// If the anchors cross each other, the longest consistent subset is kept.
+func Anchored(n, m int, data Data, anchors []int) []Change {
+	type match struct{ a, b int }
+	var matches []match
+	for _, i := range anchors {
+		if i < 0 || i >= n {
+			continue
+		}
+		j, count := -1, 0
+		for k := 0; k < m && count < 2; k++ {
+			if data.Equal(i, k) {
+				j = k
+				count++
+			}
+		}
+		if count == 1 {
+			matches = append(matches, match{i, j})
+		}
+	}
+	sort.Slice(matches, func(x, y int) bool { return matches[x].a < matches[y].a })
+
+	// keep the longest subsequence of matches increasing in both a and b
+	// tails[l] is the index of the smallest b ending an increasing run of length l+1
+	tails := make([]int, 0, len(matches))
+	prev := make([]int, len(matches))
+	for i, mt := range matches {
+		if i > 0 && mt.a == matches[i-1].a {
+			prev[i] = -2 // duplicate anchor
+			continue
+		}
+		l := sort.Search(len(tails), func(x int) bool { return matches[tails[x]].b >= mt.b })
+		if l > 0 {
+			prev[i] = tails[l-1]
+		} else {
+			prev[i] = -1
+		}
+		if l == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[l] = i
+		}
+	}
+	keep := make([]match, len(tails))
+	if len(tails) > 0 {
+		for i, l := tails[len(tails)-1], len(tails)-1; l >= 0; i, l = prev[i], l-1 {
+			keep[l] = matches[i]
+		}
+	}
+
+	c := new(context)
+	c.reset(n, m, data)
+	aoffset, boffset := 0, 0
+	for _, mt := range keep {
+		c.compare(aoffset, boffset, mt.a, mt.b)
+		aoffset, boffset = mt.a+1, mt.b+1
+	}
+	c.compare(aoffset, boffset, n, m)
+	return c.appendResult(nil, n, m)
+}
@@ -0,0 +1,59 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+type stringSlices struct{ a, b []string }
+
+func (d *stringSlices) Equal(i, j int) bool { return d.a[i] == d.b[j] }
+
+func TestAnchored(t *testing.T) {
+	a := []string{"a", "b", "c"}
+	b := []string{"c", "a", "b"}
+	data := &stringSlices{a, b}
+
+	plain := []diff.Change{{A: 0, B: 0, Del: 0, Ins: 1}, {A: 2, B: 3, Del: 1, Ins: 0}}
+	if res := diff.Diff(len(a), len(b), data); !diffsEqual(res, plain) {
+		t.Error("expected", plain, "got", res)
+	}
+	anchored := []diff.Change{{A: 0, B: 0, Del: 2, Ins: 0}, {A: 3, B: 1, Del: 0, Ins: 2}}
+	if res := diff.Anchored(len(a), len(b), data, []int{2}); !diffsEqual(res, anchored) {
+		t.Error("expected", anchored, "got", res)
+	}
+	// no anchors behaves like Diff
+	if res := diff.Anchored(len(a), len(b), data, nil); !diffsEqual(res, plain) {
+		t.Error("expected", plain, "got", res)
+	}
+}
+
+func TestAnchoredIgnored(t *testing.T) {
+	a := []string{"x", "a", "b", "x"}
+	b := []string{"a", "x", "b", "x"}
+	data := &stringSlices{a, b}
+	plain := diff.Diff(len(a), len(b), data)
+	// x is not unique in b and out of range anchors are dropped
+	for _, anchors := range [][]int{{0}, {3}, {-1, 4}} {
+		if res := diff.Anchored(len(a), len(b), data, anchors); !diffsEqual(res, plain) {
+			t.Error(anchors, "expected", plain, "got", res)
+		}
+	}
+}
+
+func TestAnchoredCrossing(t *testing.T) {
+	a := []string{"a", "b", "c", "d"}
+	b := []string{"d", "c", "a", "b"}
+	data := &stringSlices{a, b}
+	// anchors a and b are consistent, c and d cross them and each other
+	res := diff.Anchored(len(a), len(b), data, []int{0, 1, 2, 3})
+	expect := []diff.Change{{A: 0, B: 0, Del: 0, Ins: 2}, {A: 2, B: 4, Del: 2, Ins: 0}}
+	if !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// ApplyOptions configure Apply.
+type ApplyOptions struct {
+	// Reverse applies the hunks backwards, turning b into a.
+	Reverse bool
+	// Fuzz is the number of common lines at the start and end of a hunk
+	// that may be ignored if the hunk does not match otherwise, like the
+	// fuzz factor of patch -F.
+	Fuzz int
+	// SkipApplied treats hunks that are already applied as applied
+	// instead of rejecting them, see HunkResult.Applied.
+	SkipApplied bool
+}
+
+// A HunkResult tells where a hunk was applied.
+type HunkResult struct {
+	// Pos is the position of the first line of the hunk in the input,
+	// or -1 if the hunk was rejected.
+	Pos int
+	// Offset is the distance of Pos from the position in the hunk header.
+	Offset int
+	// Fuzz is the number of common lines ignored at both ends.
+	Fuzz int
+	// Out is the range of the resulting lines that the hunk put in place
+	// of the lines it matched, without the common lines ignored by fuzz.
+	Out Range
+	// Applied is set if the hunk did not match, but the lines it produces
+	// were found instead, so that it seems to be applied already. Such
+	// hunks are rejected unless ApplyOptions.SkipApplied is set, then Pos,
+	// Offset and Out refer to the lines that were found.
+	Applied bool
+}
+
+// An ApplyError is returned by Apply if hunks did not match the input.
+type ApplyError struct {
+	Rejected []int // the indices of the rejected hunks
+	Hunks    int   // the number of hunks
+}
+
+func (e *ApplyError) Error() string {
+	return fmt.Sprintf("diff: %d of %d hunks failed", len(e.Rejected), e.Hunks)
+}
+
+// Apply applies the hunks of a patch to lines like patch does and returns
+// the resulting lines with the result of every hunk, which includes the
+// range of the resulting lines the hunk produced. The hunks need their
+// Lines, see NewFilePatch and ParsePatch. A hunk that is not found at the
+// position in its header is looked for at the nearest position after the
+// previous hunk, shifted by the offset of the previous hunk. Hunks that do
+// not match anywhere are skipped and reported by an *ApplyError, while
+// the others are still applied. Hunks whose result is found instead are
+// marked as applied already.
+func Apply(lines []string, hunks []Hunk, opts *ApplyOptions) ([]string, []HunkResult, error) {
+	var o ApplyOptions
+	if opts != nil {
+		o = *opts
+	}
+	results := make([]HunkResult, len(hunks))
+	var res []string
+	var rejected []int
+	x, offset := 0, 0 // position after the last applied hunk and its offset
+	for i, h := range hunks {
+		old, new, lead, trail := hunkSides(h, o.Reverse)
+		start := h.A
+		if o.Reverse {
+			start = h.B
+		}
+		results[i].Pos = -1
+		for fuzz := 0; fuzz <= o.Fuzz; fuzz++ {
+			head, tail := min(fuzz, lead), min(fuzz, trail)
+			if fuzz > 0 && head == min(fuzz-1, lead) && tail == min(fuzz-1, trail) {
+				break // no more common lines to ignore
+			}
+			want, repl := old[head:len(old)-tail], new[head:len(new)-tail]
+			pos := findLines(lines, want, start+offset+head, x)
+			if pos < 0 {
+				continue
+			}
+			res = append(res, lines[x:pos]...)
+			out := Range{len(res), len(res) + len(repl)}
+			res = append(res, repl...)
+			x = pos + len(want)
+			offset = pos - head - start
+			results[i] = HunkResult{Pos: pos - head, Offset: offset, Fuzz: fuzz, Out: out}
+			break
+		}
+		if results[i].Pos < 0 && len(new) > 0 && (len(old) != len(new) || !equalLines(old, new)) {
+			// look for the result of the hunk
+			bstart := h.B
+			if o.Reverse {
+				bstart = h.A
+			}
+			if pos := findLines(lines, new, bstart+offset, x); pos >= 0 {
+				results[i].Applied = true
+				if o.SkipApplied {
+					res = append(res, lines[x:pos+len(new)]...)
+					x = pos + len(new)
+					offset = x - start - len(old)
+					results[i].Pos, results[i].Offset = pos, pos-bstart
+					results[i].Out = Range{len(res) - len(new), len(res)}
+				}
+			}
+		}
+		if results[i].Pos < 0 {
+			rejected = append(rejected, i)
+		}
+	}
+	res = append(res, lines[x:]...)
+	if len(rejected) > 0 {
+		return res, results, &ApplyError{Rejected: rejected, Hunks: len(hunks)}
+	}
+	return res, results, nil
+}
+
+// hunkSides returns the lines that h expects and replaces them with,
+// and the number of common lines at its start and end.
+func hunkSides(h Hunk, reverse bool) (old, new []string, lead, trail int) {
+	del, ins := byte('-'), byte('+')
+	if reverse {
+		del, ins = ins, del
+	}
+	for _, line := range h.Lines {
+		switch line[0] {
+		case ' ':
+			old = append(old, line[1:])
+			new = append(new, line[1:])
+		case del:
+			old = append(old, line[1:])
+		case ins:
+			new = append(new, line[1:])
+		}
+	}
+	for lead < len(h.Lines) && h.Lines[lead][0] == ' ' {
+		lead++
+	}
+	for trail < len(h.Lines)-lead && h.Lines[len(h.Lines)-1-trail][0] == ' ' {
+		trail++
+	}
+	return old, new, lead, trail
+}
+
+// findLines returns the position of want in lines nearest to pos
+// but not before from, or -1 if there is none.
+func findLines(lines, want []string, pos, from int) int {
+	last := len(lines) - len(want)
+	for d := 0; pos-d >= from || pos+d <= last; d++ {
+		if p := pos + d; p >= from && p <= last && equalLines(lines[p:p+len(want)], want) {
+			return p
+		}
+		if p := pos - d; d > 0 && p >= from && p <= last && equalLines(lines[p:p+len(want)], want) {
+			return p
+		}
+	}
+	return -1
+}
+
+func equalLines(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// A HunkStatus tells whether and how a hunk applies.
+type HunkStatus int
+
+const (
+	// HunkClean hunks apply at the position in their header.
+	HunkClean HunkStatus = iota + 1
+	// HunkOffset hunks apply at another position.
+	HunkOffset
+	// HunkFuzzy hunks only apply if some of their common lines are ignored.
+	HunkFuzzy
+	// HunkFailed hunks do not apply anywhere.
+	HunkFailed
+	// HunkApplied hunks are applied already and skipped,
+	// see ApplyOptions.SkipApplied.
+	HunkApplied
+)
+
+func (s HunkStatus) String() string {
+	switch s {
+	case HunkClean:
+		return "clean"
+	case HunkOffset:
+		return "offset"
+	case HunkFuzzy:
+		return "fuzzy"
+	case HunkFailed:
+		return "failed"
+	case HunkApplied:
+		return "applied"
+	}
+	return "unknown"
+}
+
+// A HunkCheck describes how a hunk applies to its file.
+type HunkCheck struct {
+	HunkResult
+	Status HunkStatus
+	// For failed hunks, Line is the position in the file of the first line
+	// that differs from the hunk when placed at the position in its header.
+	// Want is the line of the hunk and Got the line of the file, which is
+	// empty if the file ends before.
+	Line      int
+	Want, Got string
+}
+
+// A FileCheck describes how the patch of one file applies.
+type FileCheck struct {
+	// Path is the path of the file the patch applies to.
+	Path string
+	// Err is set if the patch can not be applied at all, for example
+	// because the file is missing.
+	Err   error
+	Hunks []HunkCheck
+}
+
+// OK reports whether the patch of the file applies, possibly with offsets,
+// fuzz or hunks that are applied already.
+func (c FileCheck) OK() bool {
+	if c.Err != nil {
+		return false
+	}
+	for _, h := range c.Hunks {
+		if h.Status == HunkFailed {
+			return false
+		}
+	}
+	return true
+}
+
+// Check reports how the patch would apply to the files in target, without
+// modifying anything. The paths of the patch are relative to the root of
+// target. Files are deleted by the patch if their NewPath is empty and
+// created if their OldPath is empty.
+func (p Patch) Check(target fs.FS, opts *ApplyOptions) []FileCheck {
+	var o ApplyOptions
+	if opts != nil {
+		o = *opts
+	}
+	reverse := o.Reverse
+	o.Reverse = false
+	res := make([]FileCheck, len(p))
+	for i, f := range p {
+		if reverse {
+			f = f.Invert()
+		}
+		c := &res[i]
+		c.Path = f.OldPath
+		var lines []string
+		switch {
+		case f.OldPath == "":
+			c.Path = f.NewPath
+			if _, err := fs.Stat(target, f.NewPath); err == nil {
+				c.Err = fmt.Errorf("diff: %s already exists", f.NewPath)
+				continue
+			}
+		case f.Binary:
+			c.Err = fmt.Errorf("diff: can not check the binary patch of %s", f.OldPath)
+			continue
+		default:
+			data, err := fs.ReadFile(target, f.OldPath)
+			if err != nil {
+				c.Err = err
+				continue
+			}
+			lines = splitLines(string(data))
+		}
+		applied, results, _ := Apply(lines, f.Hunks, &o)
+		c.Hunks = make([]HunkCheck, len(results))
+		for j, r := range results {
+			h := HunkCheck{HunkResult: r}
+			switch {
+			case r.Pos < 0:
+				h.Status = HunkFailed
+				h.Line, h.Want, h.Got = mismatch(lines, f.Hunks[j])
+			case r.Applied:
+				h.Status = HunkApplied
+			case r.Fuzz > 0:
+				h.Status = HunkFuzzy
+			case r.Offset != 0:
+				h.Status = HunkOffset
+			default:
+				h.Status = HunkClean
+			}
+			c.Hunks[j] = h
+		}
+		if f.NewPath == "" && c.OK() && len(applied) > 0 {
+			c.Err = fmt.Errorf("diff: %s is not empty after deleting the lines of the patch", f.OldPath)
+		}
+	}
+	return res
+}
+
+// mismatch returns the first line of lines that differs from the
+// expected lines of h at the position in its header.
+func mismatch(lines []string, h Hunk) (line int, want, got string) {
+	old, _, _, _ := hunkSides(h, false)
+	for i, w := range old {
+		line = h.A + i
+		if line >= len(lines) {
+			return line, w, ""
+		}
+		if lines[line] != w {
+			return line, w, lines[line]
+		}
+	}
+	return h.A, "", ""
+}
@@ -0,0 +1,192 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"errors"
+	"io/fs"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/echlebek/diff"
+)
+
+func TestApply(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a := randomLines(r, r.Intn(30), 5)
+		b := randomLines(r, r.Intn(30), 5)
+		f := diff.NewFilePatch("a", "b", a, b, diff.Lines(a, b), r.Intn(4))
+		res, results, err := diff.Apply(a, f.Hunks, nil)
+		if err != nil || strings.Join(res, "") != strings.Join(b, "") {
+			t.Fatal(a, b, "applied to", res, err)
+		}
+		for j, h := range f.Hunks {
+			if out := results[j].Out; out != (diff.Range{Start: h.B, End: h.B + h.LenB}) {
+				t.Fatal("expected hunk", h, "to produce", h.B, h.LenB, "got", out)
+			}
+		}
+		res, _, err = diff.Apply(b, f.Hunks, &diff.ApplyOptions{Reverse: true})
+		if err != nil || strings.Join(res, "") != strings.Join(a, "") {
+			t.Fatal(a, b, "applied in reverse to", res, err)
+		}
+	}
+
+	// a parsed patch that adds a missing newline
+	p, err := diff.ParsePatch(strings.NewReader("--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n x\n-y\n\\ No newline at end of file\n+y\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, _, err := diff.Apply([]string{"x\n", "y"}, p[0].Hunks, nil)
+	if err != nil || strings.Join(res, "") != "x\ny\n" {
+		t.Errorf("expected %q, got %q %v", "x\ny\n", strings.Join(res, ""), err)
+	}
+}
+
+func TestApplyOffset(t *testing.T) {
+	a := splitLines("1\n2\n3\n4\n5\n6\n7\n8\n9\n")
+	b := splitLines("1\n2\n3\n4\nfive\n6\n7\n8\n9\n")
+	f := diff.NewFilePatch("a", "b", a, b, diff.Lines(a, b), 3)
+	moved := append(splitLines("x\ny\n"), a...)
+	res, results, err := diff.Apply(moved, f.Hunks, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "x\ny\n" + strings.Join(b, ""); strings.Join(res, "") != expect {
+		t.Errorf("expected %q, got %q", expect, strings.Join(res, ""))
+	}
+	if expect := (diff.HunkResult{Pos: 3, Offset: 2, Out: diff.Range{Start: 3, End: 10}}); results[0] != expect {
+		t.Error("expected", expect, "got", results[0])
+	}
+}
+
+func TestApplyFuzz(t *testing.T) {
+	a := splitLines("1\n2\n3\n4\n5\n6\n7\n8\n9\n")
+	b := splitLines("1\n2\n3\n4\nfive\n6\n7\n8\n9\n")
+	f := diff.NewFilePatch("a", "b", a, b, diff.Lines(a, b), 3)
+	// the first common line of the hunk changed
+	changed := splitLines("1\nTWO\n3\n4\n5\n6\n7\n8\n9\n")
+	_, results, err := diff.Apply(changed, f.Hunks, nil)
+	var aerr *diff.ApplyError
+	if !errors.As(err, &aerr) || len(aerr.Rejected) != 1 || aerr.Hunks != 1 || results[0].Pos != -1 {
+		t.Fatal("expected the hunk to be rejected, got", results, err)
+	}
+	res, results, err := diff.Apply(changed, f.Hunks, &diff.ApplyOptions{Fuzz: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "1\nTWO\n3\n4\nfive\n6\n7\n8\n9\n"; strings.Join(res, "") != expect {
+		t.Errorf("expected %q, got %q", expect, strings.Join(res, ""))
+	}
+	if expect := (diff.HunkResult{Pos: 1, Fuzz: 1, Out: diff.Range{Start: 2, End: 7}}); results[0] != expect {
+		t.Error("expected", expect, "got", results[0])
+	}
+}
+
+func TestApplyRejected(t *testing.T) {
+	a := splitLines("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n")
+	b := splitLines("one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n")
+	f := diff.NewFilePatch("a", "b", a, b, diff.Lines(a, b), 1)
+	if len(f.Hunks) != 2 {
+		t.Fatal("expected 2 hunks, got", f.Hunks)
+	}
+	// the first hunk still applies
+	res, _, err := diff.Apply(splitLines("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\nXII\n"), f.Hunks, nil)
+	var aerr *diff.ApplyError
+	if !errors.As(err, &aerr) || len(aerr.Rejected) != 1 || aerr.Rejected[0] != 1 {
+		t.Fatal("expected the second hunk to be rejected, got", err)
+	}
+	if err.Error() != "diff: 1 of 2 hunks failed" {
+		t.Error("unexpected message", err)
+	}
+	if expect := "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\nXII\n"; strings.Join(res, "") != expect {
+		t.Errorf("expected %q, got %q", expect, strings.Join(res, ""))
+	}
+}
+
+func TestApplyApplied(t *testing.T) {
+	a := splitLines("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n")
+	b := splitLines("one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n")
+	f := diff.NewFilePatch("a", "b", a, b, diff.Lines(a, b), 1)
+	// the first hunk is applied already
+	half := splitLines("one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n")
+	_, results, err := diff.Apply(half, f.Hunks, nil)
+	var aerr *diff.ApplyError
+	if !errors.As(err, &aerr) || len(aerr.Rejected) != 1 || aerr.Rejected[0] != 0 || !results[0].Applied || results[0].Pos != -1 {
+		t.Fatal("expected the first hunk to be rejected as applied, got", results, err)
+	}
+	res, results, err := diff.Apply(half, f.Hunks, &diff.ApplyOptions{SkipApplied: true})
+	if err != nil || strings.Join(res, "") != strings.Join(b, "") {
+		t.Fatalf("expected %q, got %q %v", strings.Join(b, ""), strings.Join(res, ""), err)
+	}
+	if expect := (diff.HunkResult{Pos: 0, Out: diff.Range{Start: 0, End: 2}, Applied: true}); results[0] != expect {
+		t.Error("expected", expect, "got", results[0])
+	}
+	if results[1].Applied {
+		t.Error("expected the second hunk to be applied now, got", results[1])
+	}
+	// applying the patch again changes nothing
+	if res, _, err := diff.Apply(b, f.Hunks, &diff.ApplyOptions{SkipApplied: true}); err != nil || strings.Join(res, "") != strings.Join(b, "") {
+		t.Errorf("expected %q, got %q %v", strings.Join(b, ""), strings.Join(res, ""), err)
+	}
+	target := fstest.MapFS{"a": {Data: []byte(strings.Join(b, ""))}}
+	if c := (diff.Patch{f}).Check(target, nil)[0]; c.OK() || c.Hunks[0].Status != diff.HunkFailed || !c.Hunks[0].Applied {
+		t.Error("expected failed hunks that are applied, got", c)
+	}
+	c := (diff.Patch{f}).Check(target, &diff.ApplyOptions{SkipApplied: true})[0]
+	if !c.OK() || c.Hunks[0].Status != diff.HunkApplied || c.Hunks[1].Status != diff.HunkApplied {
+		t.Error("expected applied hunks, got", c)
+	}
+}
+
+func TestPatchCheck(t *testing.T) {
+	a := splitLines("1\n2\n3\n4\n5\n6\n7\n8\n9\n")
+	b := splitLines("1\n2\n3\n4\nfive\n6\n7\n8\n9\n")
+	f := diff.NewFilePatch("f", "f", a, b, diff.Lines(a, b), 3)
+	p := diff.Patch{
+		f,
+		{OldPath: "moved", NewPath: "moved", Hunks: f.Hunks},
+		{OldPath: "changed", NewPath: "changed", Hunks: f.Hunks},
+		{OldPath: "missing", NewPath: "missing", Hunks: f.Hunks},
+		{NewPath: "f", Hunks: f.Hunks},
+	}
+	target := fstest.MapFS{
+		"f":       {Data: []byte(strings.Join(a, ""))},
+		"moved":   {Data: []byte("x\n" + strings.Join(a, ""))},
+		"changed": {Data: []byte("1\n2\n3\n4\n5\n6\n7\neight\n9\n")},
+	}
+	res := p.Check(target, nil)
+	if len(res) != len(p) {
+		t.Fatal("expected", len(p), "results, got", res)
+	}
+	if c := res[0]; !c.OK() || c.Hunks[0].Status != diff.HunkClean {
+		t.Error("expected a clean hunk, got", c)
+	}
+	if c := res[1]; !c.OK() || c.Hunks[0].Status != diff.HunkOffset || c.Hunks[0].Offset != 1 {
+		t.Error("expected an offset of 1, got", c)
+	}
+	expect := diff.HunkCheck{HunkResult: diff.HunkResult{Pos: -1}, Status: diff.HunkFailed, Line: 7, Want: "8\n", Got: "eight\n"}
+	if c := res[2]; c.OK() || c.Hunks[0] != expect {
+		t.Error("expected", expect, "got", c)
+	}
+	if c := res[3]; c.OK() || !errors.Is(c.Err, fs.ErrNotExist) {
+		t.Error("expected a missing file, got", c)
+	}
+	if c := res[4]; c.OK() || c.Err == nil || c.Path != "f" {
+		t.Error("expected an existing file, got", c)
+	}
+
+	// fuzz is reported and the patch can be checked in reverse
+	res = diff.Patch{{OldPath: "changed", NewPath: "changed", Hunks: f.Hunks}}.Check(target, &diff.ApplyOptions{Fuzz: 1})
+	if c := res[0]; !c.OK() || c.Hunks[0].Status != diff.HunkFuzzy || c.Hunks[0].Fuzz != 1 {
+		t.Error("expected a fuzzy hunk, got", c)
+	}
+	target["f"] = &fstest.MapFile{Data: []byte(strings.Join(b, ""))}
+	if res := (diff.Patch{f}).Check(target, &diff.ApplyOptions{Reverse: true}); !res[0].OK() {
+		t.Error("expected the patch to apply in reverse, got", res[0])
+	}
+}
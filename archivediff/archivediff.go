@@ -0,0 +1,129 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package archivediff compares the members of two tar or zip archives,
+// like build artifacts.
+package archivediff
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+
+	"github.com/echlebek/diff"
+	"github.com/echlebek/diff/fsdiff"
+)
+
+// An Entry describes a member that differs between two archives.
+// Status is fsdiff.Added, fsdiff.Removed or fsdiff.Modified.
+type Entry struct {
+	Name   string
+	Status fsdiff.Status
+	// Diff holds the content changes of a modified member.
+	Diff *diff.FileDiff
+}
+
+// Compare reads two archives and returns the regular members that were
+// added, removed or modified, ordered by name. Members are matched by name
+// and modified members are diffed with diff.Contents using opts.
+// See Read for the supported formats.
+func Compare(a, b io.Reader, opts ...diff.Option) ([]Entry, error) {
+	ma, err := Read(a)
+	if err != nil {
+		return nil, err
+	}
+	mb, err := Read(b)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for name := range ma {
+		if _, ok := mb[name]; !ok {
+			entries = append(entries, Entry{Name: name, Status: fsdiff.Removed})
+		}
+	}
+	for name, db := range mb {
+		da, ok := ma[name]
+		switch {
+		case !ok:
+			entries = append(entries, Entry{Name: name, Status: fsdiff.Added})
+		case !bytes.Equal(da, db):
+			entries = append(entries, Entry{Name: name, Status: fsdiff.Modified, Diff: diff.Contents(da, db, opts...)})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// Read returns the contents of the regular members of a tar or zip archive
+// by their cleaned names. Tar archives may be compressed, see
+// diff.Decompress. The format is detected from the content.
+func Read(r io.Reader) (map[string][]byte, error) {
+	r, err := diff.Decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(r)
+	// a local file header, or the end of the central directory of an empty
+	// archive, but not a tar member named like PKG-INFO
+	if magic, _ := br.Peek(4); string(magic) == "PK\x03\x04" || string(magic) == "PK\x05\x06" {
+		return readZip(br)
+	}
+	return readTar(br)
+}
+
+func readTar(r io.Reader) (map[string][]byte, error) {
+	members := make(map[string][]byte)
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return members, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("archivediff: %v", err)
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		members[path.Clean(h.Name)] = data
+	}
+}
+
+func readZip(r io.Reader) (map[string][]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("archivediff: %v", err)
+	}
+	members := make(map[string][]byte)
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		members[path.Clean(f.Name)] = data
+	}
+	return members, nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package archivediff_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/echlebek/diff"
+	"github.com/echlebek/diff/archivediff"
+	"github.com/echlebek/diff/fsdiff"
+)
+
+type member struct{ name, content string }
+
+func tarball(t *testing.T, compress bool, members ...member) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	var zw *gzip.Writer
+	w := tar.NewWriter(&buf)
+	if compress {
+		zw = gzip.NewWriter(&buf)
+		w = tar.NewWriter(zw)
+	}
+	w.WriteHeader(&tar.Header{Name: "./dir/", Typeflag: tar.TypeDir, Mode: 0755})
+	for _, m := range members {
+		if err := w.WriteHeader(&tar.Header{Name: m.name, Size: int64(len(m.content)), Mode: 0644, Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(m.content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if compress {
+		zw.Close()
+	}
+	return &buf
+}
+
+func zipfile(t *testing.T, members ...member) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	w.Create("dir/")
+	for _, m := range members {
+		f, err := w.Create(m.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(m.content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestCompare(t *testing.T) {
+	a := []member{{"./dir/same", "x\n"}, {"./dir/text", "one\ntwo\n"}, {"bin", "\x00\x01\x02"}, {"gone", "-"}}
+	b := []member{{"dir/same", "x\n"}, {"dir/text", "one\n2\n"}, {"bin", "\x00\x01\x03"}, {"new", "+"}}
+	for name, archives := range map[string][2]*bytes.Buffer{
+		"tar":        {tarball(t, false, a...), tarball(t, false, b...)},
+		"tar.gz":     {tarball(t, true, a...), tarball(t, true, b...)},
+		"zip":        {zipfile(t, a...), zipfile(t, b...)},
+		"tar to zip": {tarball(t, false, a...), zipfile(t, b...)},
+	} {
+		entries, err := archivediff.Compare(archives[0], archives[1])
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		if len(entries) != 4 {
+			t.Fatal(name, "unexpected entries", entries)
+		}
+		if e := entries[0]; e.Name != "bin" || e.Status != fsdiff.Modified || !e.Diff.Binary {
+			t.Error(name, "expected a binary change, got", e)
+		}
+		expect := []diff.Change{{A: 1, B: 1, Del: 1, Ins: 1}}
+		if e := entries[1]; e.Name != "dir/text" || e.Diff.Binary || !diffsEqual(e.Diff.Changes, expect) {
+			t.Error(name, "expected", expect, "got", e)
+		}
+		if e := entries[2]; e.Name != "gone" || e.Status != fsdiff.Removed {
+			t.Error(name, "expected a removed member, got", e)
+		}
+		if e := entries[3]; e.Name != "new" || e.Status != fsdiff.Added {
+			t.Error(name, "expected an added member, got", e)
+		}
+	}
+}
+
+func TestReadTarPK(t *testing.T) {
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	w.WriteHeader(&tar.Header{Name: "PKG-INFO", Size: 3, Mode: 0644, Typeflag: tar.TypeReg})
+	w.Write([]byte("v=1"))
+	w.Close()
+	members, err := archivediff.Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(members["PKG-INFO"]) != "v=1" {
+		t.Error("expected the PKG-INFO member, got", members)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	if _, err := archivediff.Read(bytes.NewReader([]byte("PK garbage"))); err == nil {
+		t.Error("expected an error for an invalid zip file")
+	}
+}
+
+func diffsEqual(a, b []diff.Change) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,24 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// Bisect returns the middle snake of the region of data between aoffset and
+// alimit in a and boffset and blimit in b: a point x, y on a shortest edit
+// path that divides it into two subproblems of about equal cost.
+// Diff solves the regions aoffset, boffset, x, y and x, y, alimit, blimit
+// recursively. Like Diff, callers should strip the common prefix and suffix
+// of a region and handle regions that are empty in a or b before bisecting,
+// or the split point may be a corner of the region.
+func Bisect(data Data, aoffset, boffset, alimit, blimit int) (x, y int) {
+	d := acquire(nil)
+	c := &d.c
+	c.options = d.opts
+	c.reset(alimit, blimit, data)
+	c.max = alimit - aoffset + blimit - boffset + 1
+	x, y = c.findMiddleSnake(aoffset, boffset, alimit, blimit)
+	c.release()
+	pool.Put(d)
+	return x, y
+}
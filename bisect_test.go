@@ -0,0 +1,76 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+// bisectDiff marks deletions and insertions by recursive bisection.
+func bisectDiff(data diff.Data, del, ins []bool, aoffset, boffset, alimit, blimit int) {
+	for aoffset < alimit && boffset < blimit && data.Equal(aoffset, boffset) {
+		aoffset++
+		boffset++
+	}
+	for alimit > aoffset && blimit > boffset && data.Equal(alimit-1, blimit-1) {
+		alimit--
+		blimit--
+	}
+	if aoffset == alimit || boffset == blimit {
+		for ; aoffset < alimit; aoffset++ {
+			del[aoffset] = true
+		}
+		for ; boffset < blimit; boffset++ {
+			ins[boffset] = true
+		}
+		return
+	}
+	x, y := diff.Bisect(data, aoffset, boffset, alimit, blimit)
+	bisectDiff(data, del, ins, aoffset, boffset, x, y)
+	bisectDiff(data, del, ins, x, y, alimit, blimit)
+}
+
+func TestBisect(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		a := randomInts(r, 1+r.Intn(50), 5)
+		b := randomInts(r, 1+r.Intn(50), 5)
+		data := &ints{a, b}
+		del, ins := make([]bool, len(a)), make([]bool, len(b))
+		bisectDiff(data, del, ins, 0, 0, len(a), len(b))
+		edits := 0
+		for _, d := range del {
+			if d {
+				edits++
+			}
+		}
+		for _, d := range ins {
+			if d {
+				edits++
+			}
+		}
+		if expect := countEdits(diff.Ints(a, b)); edits != expect {
+			t.Fatal(a, b, "expected", expect, "edits, got", edits)
+		}
+	}
+}
+
+func TestBisectAfterCancel(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a := randomInts(r, 100, 10)
+	b := randomInts(r, 100, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// the pooled differ must not keep the canceled context
+	diff.Diff(len(a), len(b), &ints{a, b}, diff.WithContext(ctx))
+	x, y := diff.Bisect(&ints{a, b}, 0, 0, len(a), len(b))
+	if x < 0 || x > len(a) || y < 0 || y > len(b) {
+		t.Error("expected a point in the region, got", x, y)
+	}
+}
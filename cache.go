@@ -0,0 +1,162 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"container/list"
+	"sync"
+)
+
+// A CacheKey identifies the content of two sequences by their hashes.
+type CacheKey struct {
+	A, B uint64
+}
+
+// A Cache stores the changes of previously diffed content.
+// It must be safe for concurrent use if Differs share it.
+// Neither the cache nor the Differ modify the changes passed between them.
+type Cache interface {
+	// Get returns the changes stored for key.
+	Get(key CacheKey) ([]Change, bool)
+	// Put stores the changes for key.
+	Put(key CacheKey, changes []Change)
+}
+
+// WithCache looks up the changes in c before diffing and stores them after.
+// The key is computed from the content of the slices diffed by Ints, Runes,
+// Bytes, ByteStrings and Lines, and from the hashes of Data that implements
+// Hasher; other data is always diffed. Equal is not called on a hit, so
+// the hashes of a Hasher must tell all unequal elements apart, or a
+// collision returns the changes of other content. The options are not part
+// of the key, so a cache must only be shared by Differs with the same options.
+// Results that stopped early, see Differ.Err, are not stored.
+func WithCache(c Cache) Option {
+	return func(o *options) { o.cache = c }
+}
+
+const (
+	offset64 = 14695981039346656037
+	prime64  = 1099511628211
+)
+
+// keyHash is an FNV-1a hash of 64 bit values.
+type keyHash uint64
+
+func newKeyHash(n int) keyHash {
+	h := keyHash(offset64)
+	h.add(uint64(n))
+	return h
+}
+
+func (h *keyHash) add(x uint64) {
+	for i := 0; i < 8; i++ {
+		*h = (*h ^ keyHash(x&0xff)) * prime64
+		x >>= 8
+	}
+}
+
+// cacheKey returns the key of data with lengths n and m if it can be computed.
+func cacheKey(n, m int, data Data) (CacheKey, bool) {
+	ha, hb := newKeyHash(n), newKeyHash(m)
+	switch d := data.(type) {
+	case Hasher:
+		for i := 0; i < n; i++ {
+			ha.add(d.HashA(i))
+		}
+		for j := 0; j < m; j++ {
+			hb.add(d.HashB(j))
+		}
+	case *ints:
+		for _, x := range d.a {
+			ha.add(uint64(x))
+		}
+		for _, x := range d.b {
+			hb.add(uint64(x))
+		}
+	case *runes:
+		for _, x := range d.a {
+			ha.add(uint64(x))
+		}
+		for _, x := range d.b {
+			hb.add(uint64(x))
+		}
+	case *byteSlices:
+		for _, x := range d.a {
+			ha.add(uint64(x))
+		}
+		for _, x := range d.b {
+			hb.add(uint64(x))
+		}
+	case *byteStrings:
+		for i := 0; i < len(d.a); i++ {
+			ha.add(uint64(d.a[i]))
+		}
+		for i := 0; i < len(d.b); i++ {
+			hb.add(uint64(d.b[i]))
+		}
+	default:
+		return CacheKey{}, false
+	}
+	return CacheKey{uint64(ha), uint64(hb)}, true
+}
+
+// An LRU is a Cache that holds a limited number of results and evicts the
+// least recently used ones. It is safe for concurrent use.
+type LRU struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // of *lruEntry, most recently used first
+	entries map[CacheKey]*list.Element
+}
+
+type lruEntry struct {
+	key     CacheKey
+	changes []Change
+}
+
+// NewLRU returns an LRU that holds the results of up to size diffs.
+func NewLRU(size int) *LRU {
+	return &LRU{size: size, order: list.New(), entries: make(map[CacheKey]*list.Element)}
+}
+
+// Get returns the changes stored for key and marks them as recently used.
+func (c *LRU) Get(key CacheKey) ([]Change, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).changes, true
+}
+
+// Put stores the changes for key, evicting the least recently used
+// result if the cache is full.
+func (c *LRU) Put(key CacheKey, changes []Change) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*lruEntry).changes = changes
+		c.order.MoveToFront(e)
+		return
+	}
+	if c.size <= 0 {
+		return
+	}
+	if c.order.Len() >= c.size {
+		last := c.order.Back()
+		c.order.Remove(last)
+		delete(c.entries, last.Value.(*lruEntry).key)
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key, changes})
+}
+
+// Len returns the number of stored results.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
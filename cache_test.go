@@ -0,0 +1,88 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+// countingCache counts the lookups and hits of a cache.
+type countingCache struct {
+	diff.Cache
+	gets, hits int
+}
+
+func (c *countingCache) Get(key diff.CacheKey) ([]diff.Change, bool) {
+	c.gets++
+	res, ok := c.Cache.Get(key)
+	if ok {
+		c.hits++
+	}
+	return res, ok
+}
+
+func TestWithCache(t *testing.T) {
+	cache := &countingCache{Cache: diff.NewLRU(10)}
+	opt := diff.WithCache(cache)
+	for _, test := range tests {
+		for i := 0; i < 2; i++ {
+			res := diff.Diff(len(test.a), len(test.b), &hashedInts{countingInts{ints: ints{test.a, test.b}}}, opt)
+			if expect := diff.Ints(test.a, test.b); !diffsEqual(res, expect) {
+				t.Error(test.name, "expected", expect, "got", res)
+			}
+			// the cached result belongs to the caller
+			for j := range res {
+				res[j].A = -1
+			}
+		}
+	}
+	if cache.gets != 2*len(tests) || cache.hits != len(tests) {
+		t.Error("expected", len(tests), "hits of", 2*len(tests), "lookups, got", cache.hits, "of", cache.gets)
+	}
+
+	// lines with the same pattern of equal lines have the same changes
+	cache.gets, cache.hits = 0, 0
+	diff.Lines([]string{"a\n", "b\n"}, []string{"b\n"}, opt)
+	res := diff.Lines([]string{"x\n", "y\n"}, []string{"y\n"}, opt)
+	if expect := []diff.Change{{A: 0, B: 0, Del: 1, Ins: 0}}; !diffsEqual(res, expect) || cache.hits != 1 {
+		t.Error("expected a hit with", expect, "got", cache.hits, res)
+	}
+
+	// other data is not cached
+	cache.gets = 0
+	d := &countingInts{ints: ints{[]int{1}, []int{2}}}
+	diff.Diff(1, 1, d, opt)
+	if cache.gets != 0 {
+		t.Error("expected no lookup, got", cache.gets)
+	}
+}
+
+func TestLRU(t *testing.T) {
+	c := diff.NewLRU(2)
+	k1, k2, k3 := diff.CacheKey{A: 1}, diff.CacheKey{A: 2}, diff.CacheKey{A: 3}
+	c.Put(k1, []diff.Change{{Del: 1}})
+	c.Put(k2, []diff.Change{{Del: 2}})
+	if _, ok := c.Get(k1); !ok {
+		t.Fatal("expected k1 to be cached")
+	}
+	// k2 is the least recently used now
+	c.Put(k3, []diff.Change{{Del: 3}})
+	if _, ok := c.Get(k2); ok {
+		t.Error("expected k2 to be evicted")
+	}
+	if res, ok := c.Get(k3); !ok || res[0].Del != 3 {
+		t.Error("expected k3 to be cached, got", res)
+	}
+	if c.Len() != 2 {
+		t.Error("expected 2 entries, got", c.Len())
+	}
+	c = diff.NewLRU(0)
+	c.Put(k1, nil)
+	if c.Len() != 0 {
+		t.Error("expected an empty cache to stay empty")
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// Features lists what a build of this package supports.
+type Features struct {
+	// Algorithms are the names of the available diff algorithms.
+	Algorithms []string
+	// Formats maps the names of the supported wire formats
+	// to the semantic version of the format. Besides the output
+	// formats, json and text are the encodings of Change, Hunk and
+	// Patch with encoding/json and encoding.TextMarshaler.
+	Formats map[string]string
+	// Options are the names of the options accepted by Diff and New.
+	Options []string
+}
+
+// Capabilities returns the features compiled into this build, so that
+// services and plugins embedding different versions of the package can
+// negotiate what both ends understand.
+func Capabilities() Features {
+	return Features{
+		Algorithms: []string{"myers", "wu"},
+		Formats: map[string]string{
+			"combined":     "1.0.0",
+			"context":      "1.0.0",
+			"delta":        "1.0.0",
+			"ed":           "1.0.0",
+			"git":          "1.0.0",
+			"ifdef":        "1.0.0",
+			"json":         "1.0.0",
+			"mbox":         "1.0.0",
+			"merge":        "1.0.0",
+			"normal":       "1.0.0",
+			"side-by-side": "1.0.0",
+			"text":         "1.0.0",
+			"unified":      "1.0.0",
+		},
+		Options: []string{"heuristic", "algorithm", "strip-trailing-cr", "max-distance", "context", "cleanup", "tie-break", "slide", "cache", "max-changes", "max-memory", "observer", "trace", "decompress", "line-key"},
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCapabilities(t *testing.T) {
+	caps := diff.Capabilities()
+	for _, name := range []string{"myers", "wu"} {
+		if !contains(caps.Algorithms, name) {
+			t.Error("expected", name, "in", caps.Algorithms)
+		}
+	}
+	if !contains(caps.Options, "heuristic") {
+		t.Error("expected heuristic in", caps.Options)
+	}
+	for _, name := range []string{"combined", "context", "delta", "ed", "git", "ifdef", "json", "mbox", "merge", "normal", "side-by-side", "text", "unified"} {
+		if caps.Formats[name] == "" {
+			t.Error("expected", name, "in", caps.Formats)
+		}
+	}
+	// callers may modify the result
+	caps.Algorithms[0] = "modified"
+	caps.Formats["modified"] = "0.0.0"
+	if caps := diff.Capabilities(); contains(caps.Algorithms, "modified") || caps.Formats["modified"] != "" {
+		t.Error("modifications leaked into", caps)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "fmt"
+
+// Check applies changes to a, taking the inserted elements from b, and
+// reports an error unless the changes are valid and the result equals b.
+// It is meant for tests and fuzzing of code producing changes.
+func Check[T comparable](a, b []T, changes []Change) error {
+	if err := Validate(len(a), len(b), changes); err != nil {
+		return err
+	}
+	res := make([]T, 0, len(b))
+	x := 0
+	for _, c := range changes {
+		res = append(res, a[x:c.A]...)
+		res = append(res, b[c.B:c.B+c.Ins]...)
+		x = c.A + c.Del
+	}
+	res = append(res, a[x:]...)
+	for i := range res {
+		if res[i] != b[i] {
+			return fmt.Errorf("diff: result differs from b at %d: %v instead of %v", i, res[i], b[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestCheck(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		a, b := randomInts(r, r.Intn(30), 4), randomInts(r, r.Intn(30), 4)
+		if err := diff.Check(a, b, diff.Ints(a, b)); err != nil {
+			t.Fatal(a, b, err)
+		}
+	}
+	a, b := []rune("abcd"), []rune("abed")
+	if err := diff.Check(a, b, diff.Runes(a, b)); err != nil {
+		t.Error(err)
+	}
+	// valid but wrong changes
+	if err := diff.Check(a, b, []diff.Change{{A: 1, B: 1, Del: 1, Ins: 1}}); err == nil {
+		t.Error("expected an error for wrong changes")
+	}
+	if err := diff.Check(a, b, []diff.Change{{A: 2, B: 2, Del: 1, Ins: 2}}); err == nil {
+		t.Error("expected an error for invalid changes")
+	}
+}
+
+func FuzzCheck(f *testing.F) {
+	f.Add([]byte("abcd"), []byte("abed"))
+	f.Fuzz(func(t *testing.T, a, b []byte) {
+		for _, opts := range [][]diff.Option{nil, {diff.WithAlgorithm(diff.Wu)}} {
+			changes := diff.Diff(len(a), len(b), &bytesData{a, b}, opts...)
+			if err := diff.Check(a, b, changes); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := diff.Check(a, b, diff.Bytes(a, b)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
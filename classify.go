@@ -0,0 +1,80 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// A Class tells how significant a change is.
+// Classes of higher value are more significant.
+type Class int
+
+const (
+	// WhitespaceOnly changes only add, remove or move white space,
+	// including line breaks.
+	WhitespaceOnly Class = iota + 1
+	// CommentOnly changes only change comments and white space.
+	CommentOnly
+	// Substantive changes change anything else.
+	Substantive
+)
+
+func (c Class) String() string {
+	switch c {
+	case WhitespaceOnly:
+		return "whitespace-only"
+	case CommentOnly:
+		return "comment-only"
+	case Substantive:
+		return "substantive"
+	}
+	return "unknown"
+}
+
+// Classify tells whether a change of lines a to b is whitespace-only,
+// comment-only or substantive. Comments are the matches of comment, for
+// example `//.*` for Go line comments. Without comment no change is
+// comment-only.
+func Classify(a, b []string, c Change, comment *regexp.Regexp) Class {
+	del, ins := a[c.A:c.A+c.Del], b[c.B:c.B+c.Ins]
+	if stripSpace(del, nil) == stripSpace(ins, nil) {
+		return WhitespaceOnly
+	}
+	if comment != nil && stripSpace(del, comment) == stripSpace(ins, comment) {
+		return CommentOnly
+	}
+	return Substantive
+}
+
+// ClassifyHunk returns the most significant class of the changes of h,
+// so that review tools can collapse hunks that are not substantive.
+func ClassifyHunk(a, b []string, h Hunk, comment *regexp.Regexp) Class {
+	class := WhitespaceOnly
+	for _, c := range h.Changes {
+		if cc := Classify(a, b, c, comment); cc > class {
+			class = cc
+		}
+	}
+	return class
+}
+
+// stripSpace joins lines without white space and the matches of comment.
+func stripSpace(lines []string, comment *regexp.Regexp) string {
+	var sb strings.Builder
+	for _, line := range lines {
+		if comment != nil {
+			line = comment.ReplaceAllString(line, "")
+		}
+		for _, r := range line {
+			if !unicode.IsSpace(r) {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	return sb.String()
+}
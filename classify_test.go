@@ -0,0 +1,54 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestClassify(t *testing.T) {
+	comment := regexp.MustCompile(`//.*`)
+	for _, test := range []struct {
+		a, b    string
+		comment *regexp.Regexp
+		class   diff.Class
+	}{
+		{"x := 1\n", "x :=  1\n", nil, diff.WhitespaceOnly},
+		{"f(a, b)\n", "f(a,\n\tb)\n", comment, diff.WhitespaceOnly},
+		{"x := 1\n", "\n", nil, diff.Substantive},
+		{"x := 1 // one\n", "x := 1 // uno\n", comment, diff.CommentOnly},
+		{"// doc\n", "", comment, diff.CommentOnly},
+		{"x := 1 // one\n", "x := 1 // uno\n", nil, diff.Substantive},
+		{"x := 1 // one\n", "x := 2 // one\n", comment, diff.Substantive},
+	} {
+		a, b := splitLines(test.a), splitLines(test.b)
+		changes := diff.Lines(a, b)
+		if len(changes) != 1 {
+			t.Fatal(test.a, test.b, "expected one change, got", changes)
+		}
+		if class := diff.Classify(a, b, changes[0], test.comment); class != test.class {
+			t.Errorf("%q %q: expected %v, got %v", test.a, test.b, test.class, class)
+		}
+	}
+}
+
+func TestClassifyHunk(t *testing.T) {
+	comment := regexp.MustCompile(`#.*`)
+	a := splitLines("a\nb # x\nc\nd\n")
+	b := splitLines("a \nb # y\nc\nd\n")
+	hunks := diff.Hunks(len(a), len(b), diff.Lines(a, b), 1)
+	if len(hunks) != 1 {
+		t.Fatal("expected one hunk, got", hunks)
+	}
+	if class := diff.ClassifyHunk(a, b, hunks[0], comment); class != diff.CommentOnly {
+		t.Error("expected comment-only, got", class)
+	}
+	if class := diff.ClassifyHunk(a, b, hunks[0], nil); class != diff.Substantive {
+		t.Error("expected substantive, got", class)
+	}
+}
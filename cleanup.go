@@ -0,0 +1,43 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// Efficient merges neighboring changes whose common elements in between
+// are more expensive to keep than to replace, like diff-match-patch's
+// efficiency cleanup. cost is the price of an edit operation measured in
+// elements. An equality shorter than cost is merged if both changes around
+// it insert and delete, or if it is shorter than cost/2 and three of the
+// four sides edit. The changes must be ordered by ascending positions as
+// returned by this package and are modified in place.
+func Efficient(cost int, changes []Change) []Change {
+	res := changes[:0]
+	for _, c := range changes {
+		res = append(res, c)
+		// merging may make the equality before the merged change expensive too
+		for len(res) > 1 && expensive(cost, res[len(res)-2], res[len(res)-1]) {
+			prev, curr := res[len(res)-2], res[len(res)-1]
+			res[len(res)-2] = Change{
+				A: prev.A, B: prev.B,
+				Del: curr.A - prev.A + curr.Del,
+				Ins: curr.B - prev.B + curr.Ins,
+			}
+			res = res[:len(res)-1]
+		}
+	}
+	return res
+}
+
+// expensive reports whether the equality between prev and curr
+// costs more to keep than to replace.
+func expensive(cost int, prev, curr Change) bool {
+	equal := curr.A - (prev.A + prev.Del)
+	edits := 0
+	for _, n := range [...]int{prev.Del, prev.Ins, curr.Del, curr.Ins} {
+		if n > 0 {
+			edits++
+		}
+	}
+	return edits == 4 && equal < cost || edits == 3 && 2*equal < cost
+}
@@ -0,0 +1,60 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestEfficient(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		cost    int
+		changes []diff.Change
+		expect  []diff.Change
+	}{
+		{"empty", 4, []diff.Change{}, []diff.Change{}},
+		{"no elimination", 4,
+			[]diff.Change{{A: 0, B: 0, Del: 2, Ins: 2}, {A: 6, B: 6, Del: 2, Ins: 2}},
+			[]diff.Change{{A: 0, B: 0, Del: 2, Ins: 2}, {A: 6, B: 6, Del: 2, Ins: 2}},
+		},
+		{"four edit elimination", 4,
+			[]diff.Change{{A: 0, B: 0, Del: 2, Ins: 2}, {A: 5, B: 5, Del: 2, Ins: 2}},
+			[]diff.Change{{A: 0, B: 0, Del: 7, Ins: 7}},
+		},
+		{"three edit elimination", 4,
+			[]diff.Change{{A: 0, B: 0, Del: 0, Ins: 2}, {A: 1, B: 3, Del: 2, Ins: 2}},
+			[]diff.Change{{A: 0, B: 0, Del: 3, Ins: 5}},
+		},
+		{"three edit kept", 4,
+			[]diff.Change{{A: 0, B: 0, Del: 0, Ins: 2}, {A: 2, B: 4, Del: 2, Ins: 2}},
+			[]diff.Change{{A: 0, B: 0, Del: 0, Ins: 2}, {A: 2, B: 4, Del: 2, Ins: 2}},
+		},
+		{"backpass elimination", 4,
+			[]diff.Change{{A: 0, B: 0, Del: 2, Ins: 2}, {A: 4, B: 4, Del: 0, Ins: 1}, {A: 5, B: 6, Del: 2, Ins: 2}},
+			[]diff.Change{{A: 0, B: 0, Del: 7, Ins: 8}},
+		},
+		{"high cost", 5,
+			[]diff.Change{{A: 0, B: 0, Del: 2, Ins: 2}, {A: 6, B: 6, Del: 2, Ins: 2}},
+			[]diff.Change{{A: 0, B: 0, Del: 8, Ins: 8}},
+		},
+	} {
+		res := diff.Efficient(test.cost, test.changes)
+		if !diffsEqual(res, test.expect) {
+			t.Error(test.name, "expected", test.expect, "got", res)
+		}
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		a, b := randomInts(r, r.Intn(40), 3), randomInts(r, r.Intn(40), 3)
+		if res := diff.Efficient(4, diff.Ints(a, b)); diff.Check(a, b, res) != nil {
+			t.Fatal(a, b, "cleaned up to invalid", res)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command diff compares files line by line like GNU diff.
+//
+// Usage:
+//
+//	diff [flags] path1 path2
+//
+// The flags are:
+//
+//	-u         output the unified format with 3 lines of context
+//	-U num     output the unified format with num lines of context
+//	-y         output two columns side by side
+//	-W num     output at most num columns with -y (default 130)
+//	--color    color the output with ANSI escape sequences
+//	-b         ignore changes in the amount of white space
+//	-w         ignore all white space
+//	-I regexp  ignore changes whose lines all match regexp, may be repeated
+//	-r         compare subdirectories recursively
+//	-z         decompress files compressed with gzip or bzip2 first
+//
+// If one path is a directory, the file of the same name in it is compared.
+// With -z, the names of decompressed files are followed by "(decompressed)"
+// in the header of the unified format.
+// The exit status is 0 if the inputs are the same, 1 if they differ and 2
+// if there was trouble.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/echlebek/diff"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// regexps collects the values of a repeated flag.
+type regexps []*regexp.Regexp
+
+func (r *regexps) String() string { return fmt.Sprint(*r) }
+
+func (r *regexps) Set(s string) error {
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, re)
+	return nil
+}
+
+type format int
+
+const (
+	normal format = iota
+	unified
+	sideBySide
+)
+
+// formatNames are the names of the formats in diff.Capabilities.
+var formatNames = [...]string{
+	normal:     "normal",
+	unified:    "unified",
+	sideBySide: "side-by-side",
+}
+
+// A differ compares files with the options of the command line.
+type differ struct {
+	w         io.Writer
+	format    format
+	context   int
+	width     int
+	color     bool
+	normalize func(string) string
+	ignore    regexps
+	recursive bool
+	// decompress decompresses files with diff.WithDecompress
+	decompress bool
+	// flags are the command line flags repeated in the headers of
+	// recursive diffs.
+	flags string
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: diff [flags] path1 path2")
+		fs.PrintDefaults()
+	}
+	var d differ
+	u := fs.Bool("u", false, "output the unified format with 3 lines of context")
+	context := fs.Int("U", -1, "output the unified format with `num` lines of context")
+	y := fs.Bool("y", false, "output two columns side by side")
+	fs.IntVar(&d.width, "W", 130, "output at most `num` columns with -y")
+	fs.BoolVar(&d.color, "color", false, "color the output")
+	b := fs.Bool("b", false, "ignore changes in the amount of white space")
+	w := fs.Bool("w", false, "ignore all white space")
+	fs.Var(&d.ignore, "I", "ignore changes whose lines all match `regexp`")
+	fs.BoolVar(&d.recursive, "r", false, "compare subdirectories recursively")
+	fs.BoolVar(&d.decompress, "z", false, "decompress files compressed with gzip or bzip2 first")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+	switch {
+	case *y:
+		d.format = sideBySide
+	case *context >= 0:
+		d.format, d.context = unified, *context
+	case *u:
+		d.format, d.context = unified, 3
+	}
+	switch {
+	case *w:
+		d.normalize = removeSpace
+	case *b:
+		d.normalize = collapseSpace
+	}
+	d.w = stdout
+	for _, arg := range args[:len(args)-fs.NArg()] {
+		d.flags += " " + arg
+	}
+
+	differs, err := d.compare(fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		fmt.Fprintln(stderr, "diff:", err)
+		return 2
+	}
+	if differs {
+		return 1
+	}
+	return 0
+}
+
+// compare compares the files or directories at pathA and pathB
+// and reports whether they differ.
+func (d *differ) compare(pathA, pathB string) (bool, error) {
+	ia, err := os.Stat(pathA)
+	if err != nil {
+		return false, err
+	}
+	ib, err := os.Stat(pathB)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case ia.IsDir() && ib.IsDir():
+		return d.compareDirs(pathA, pathB)
+	case ia.IsDir():
+		return d.compareFiles(filepath.Join(pathA, filepath.Base(pathB)), pathB)
+	case ib.IsDir():
+		return d.compareFiles(pathA, filepath.Join(pathB, filepath.Base(pathA)))
+	}
+	return d.compareFiles(pathA, pathB)
+}
+
+// compareDirs compares the entries of two directories in sorted order.
+func (d *differ) compareDirs(dirA, dirB string) (bool, error) {
+	names := map[string]int{} // bit 1 in a, bit 2 in b
+	for i, dir := range []string{dirA, dirB} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return false, err
+		}
+		for _, e := range entries {
+			names[e.Name()] |= 1 << i
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	differs := false
+	for _, name := range sorted {
+		pathA, pathB := filepath.Join(dirA, name), filepath.Join(dirB, name)
+		switch names[name] {
+		case 1:
+			fmt.Fprintf(d.w, "Only in %s: %s\n", dirA, name)
+			differs = true
+			continue
+		case 2:
+			fmt.Fprintf(d.w, "Only in %s: %s\n", dirB, name)
+			differs = true
+			continue
+		}
+		ia, err := os.Stat(pathA)
+		if err != nil {
+			return differs, err
+		}
+		ib, err := os.Stat(pathB)
+		if err != nil {
+			return differs, err
+		}
+		switch {
+		case ia.IsDir() && ib.IsDir():
+			if !d.recursive {
+				fmt.Fprintf(d.w, "Common subdirectories: %s and %s\n", pathA, pathB)
+				continue
+			}
+			sub, err := d.compareDirs(pathA, pathB)
+			differs = differs || sub
+			if err != nil {
+				return differs, err
+			}
+		case ia.IsDir() != ib.IsDir():
+			fmt.Fprintf(d.w, "File %s is a %s while file %s is a %s\n", pathA, kind(ia), pathB, kind(ib))
+			differs = true
+		default:
+			var buf bytes.Buffer
+			w := d.w
+			d.w = &buf
+			sub, err := d.compareFiles(pathA, pathB)
+			d.w = w
+			if err != nil {
+				return differs, err
+			}
+			if sub {
+				differs = true
+				if !strings.HasPrefix(buf.String(), "Binary files ") {
+					fmt.Fprintf(d.w, "diff%s %s %s\n", d.flags, pathA, pathB)
+				}
+				if _, err := buf.WriteTo(d.w); err != nil {
+					return differs, err
+				}
+			}
+		}
+	}
+	return differs, nil
+}
+
+func kind(fi os.FileInfo) string {
+	if fi.IsDir() {
+		return "directory"
+	}
+	return "regular file"
+}
+
+// compareFiles writes the differences of two files and reports whether there are any.
+func (d *differ) compareFiles(pathA, pathB string) (bool, error) {
+	var opts []diff.Option
+	if d.decompress {
+		opts = append(opts, diff.WithDecompress())
+	}
+	fd, err := diff.Files(pathA, pathB, opts...)
+	if err != nil {
+		return false, err
+	}
+	if fd.Binary {
+		if fd.HashA == fd.HashB {
+			return false, nil
+		}
+		_, err := fmt.Fprintf(d.w, "Binary files %s and %s differ\n", pathA, pathB)
+		return true, err
+	}
+	la, lb := fd.A, fd.B
+	ka, kb := la, lb
+	if d.normalize != nil {
+		ka, kb = mapLines(la, d.normalize), mapLines(lb, d.normalize)
+	}
+	changes := diff.Lines(ka, kb)
+	if len(d.ignore) > 0 {
+		changes = diff.IgnoreMatchingLines(la, lb, changes, d.ignore...)
+	}
+	if len(changes) == 0 {
+		return false, nil
+	}
+
+	var sb strings.Builder
+	switch d.format {
+	case unified:
+		err = diff.WriteUnified(&sb, header(pathA, fd.DecompressedA), header(pathB, fd.DecompressedB), la, lb, changes, d.context)
+	case sideBySide:
+		err = diff.WriteSideBySide(&sb, la, lb, changes, &diff.SideBySideOptions{Width: d.width})
+	default:
+		err = diff.WriteNormal(&sb, la, lb, changes)
+	}
+	if err != nil {
+		return true, err
+	}
+	out := sb.String()
+	if d.color {
+		out = d.colorize(out)
+	}
+	_, err = io.WriteString(d.w, out)
+	return true, err
+}
+
+// header returns the name and modification time of a file
+// for the header of the unified format.
+func header(path string, decompressed bool) string {
+	name := path
+	if decompressed {
+		name += " (decompressed)"
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return name
+	}
+	return name + "\t" + fi.ModTime().Format("2006-01-02 15:04:05.000000000 -0700")
+}
+
+// splitLines splits s after every newline, keeping a last line without one.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func mapLines(lines []string, f func(string) string) []string {
+	res := make([]string, len(lines))
+	for i, line := range lines {
+		res[i] = f(line)
+	}
+	return res
+}
+
+// collapseSpace replaces runs of white space with one space and drops
+// white space at the end of a line, like diff -b.
+func collapseSpace(line string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range strings.TrimRightFunc(line, unicode.IsSpace) {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// removeSpace drops all white space, like diff -w.
+func removeSpace(line string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, line)
+}
+
+const (
+	red   = "\x1b[31m"
+	green = "\x1b[32m"
+	cyan  = "\x1b[36m"
+	bold  = "\x1b[1m"
+	reset = "\x1b[0m"
+)
+
+// colorize colors the lines of out written in the format of d.
+func (d *differ) colorize(out string) string {
+	var sb strings.Builder
+	for _, line := range splitLines(out) {
+		text := strings.TrimSuffix(line, "\n")
+		code := ""
+		switch d.format {
+		case unified:
+			switch {
+			case strings.HasPrefix(text, "--- "), strings.HasPrefix(text, "+++ "):
+				code = bold
+			case strings.HasPrefix(text, "@@"):
+				code = cyan
+			case strings.HasPrefix(text, "-"):
+				code = red
+			case strings.HasPrefix(text, "+"):
+				code = green
+			}
+		case sideBySide:
+			if r := []rune(text); len(r) > (d.width-3)/2+1 {
+				switch r[(d.width-3)/2+1] {
+				case '<':
+					code = red
+				case '>':
+					code = green
+				case '|':
+					code = cyan
+				}
+			}
+		default:
+			switch {
+			case strings.HasPrefix(text, "< "):
+				code = red
+			case strings.HasPrefix(text, "> "):
+				code = green
+			case text != "" && text[0] >= '0' && text[0] <= '9':
+				code = cyan
+			}
+		}
+		if code == "" || text == "" {
+			sb.WriteString(line)
+			continue
+		}
+		sb.WriteString(code + text + reset + line[len(text):])
+	}
+	return sb.String()
+}
@@ -0,0 +1,149 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+// writeFiles writes the files of a tree below dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a":     "one\ntwo  words\nthree\n",
+		"b":     "one\ntwo words\nTHREE\n",
+		"c":     "one\ntwo  words\nthree\n",
+		"space": "one\ntwowords\nthree\n",
+		"bin1":  "x\x00y",
+		"bin2":  "x\x00z",
+	})
+	a, b, c := filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "c")
+	for _, test := range []struct {
+		args   []string
+		status int
+		out    string
+	}{
+		{[]string{a, c}, 0, ""},
+		{[]string{a, b}, 1, "2,3c2,3\n< two  words\n< three\n---\n> two words\n> THREE\n"},
+		{[]string{"-b", a, b}, 1, "3c3\n< three\n---\n> THREE\n"},
+		{[]string{"-w", a, filepath.Join(dir, "space")}, 0, ""},
+		{[]string{"-b", "-I", "^[Tt][Hh]", a, b}, 0, ""},
+		{[]string{"-U", "0", "-b", a, b}, 1, "@@ -3 +3 @@\n-three\n+THREE\n"},
+		{[]string{"-y", "-W", "21", "-b", a, b}, 1, "one         one\ntwo  word   two words\nthree     | THREE\n"},
+		{[]string{"--color", "-b", a, b}, 1, "\x1b[36m3c3\x1b[0m\n\x1b[31m< three\x1b[0m\n---\n\x1b[32m> THREE\x1b[0m\n"},
+		{[]string{filepath.Join(dir, "bin1"), filepath.Join(dir, "bin2")}, 1, "Binary files " + filepath.Join(dir, "bin1") + " and " + filepath.Join(dir, "bin2") + " differ\n"},
+		{[]string{a}, 2, ""},
+		{[]string{a, filepath.Join(dir, "missing")}, 2, ""},
+	} {
+		var stdout, stderr strings.Builder
+		status := run(test.args, &stdout, &stderr)
+		out := stdout.String()
+		// the unified header holds time stamps
+		if i := strings.Index(out, "@@"); strings.HasPrefix(out, "--- ") && i >= 0 {
+			out = out[i:]
+		}
+		if status != test.status || out != test.out {
+			t.Errorf("%q: expected %d with\n%q\ngot %d with\n%q\n%s", test.args, test.status, test.out, status, out, stderr.String())
+		}
+	}
+}
+
+func TestRunRecursive(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a/same":      "x\n",
+		"a/changed":   "x\n",
+		"a/onlya":     "x\n",
+		"a/sub/deep":  "x\n",
+		"b/same":      "x\n",
+		"b/changed":   "y\n",
+		"b/onlyb":     "x\n",
+		"b/sub/deep":  "z\n",
+		"b/sub/extra": "x\n",
+	})
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	var stdout, stderr strings.Builder
+	if status := run([]string{a, b}, &stdout, &stderr); status != 1 {
+		t.Fatal("expected status 1, got", status, stderr.String())
+	}
+	expect := "diff " + a + "/changed " + b + "/changed\n1c1\n< x\n---\n> y\n" +
+		"Only in " + a + ": onlya\n" +
+		"Only in " + b + ": onlyb\n" +
+		"Common subdirectories: " + a + "/sub and " + b + "/sub\n"
+	if stdout.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, stdout.String())
+	}
+
+	stdout.Reset()
+	run([]string{"-r", a, b}, &stdout, &stderr)
+	expect = "diff -r " + a + "/changed " + b + "/changed\n1c1\n< x\n---\n> y\n" +
+		"Only in " + a + ": onlya\n" +
+		"Only in " + b + ": onlyb\n" +
+		"diff -r " + a + "/sub/deep " + b + "/sub/deep\n1c1\n< x\n---\n> z\n" +
+		"Only in " + b + "/sub: extra\n"
+	if stdout.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, stdout.String())
+	}
+
+	// a file is compared with the file of the same name in a directory
+	stdout.Reset()
+	if status := run([]string{filepath.Join(a, "same"), b}, &stdout, &stderr); status != 0 {
+		t.Error("expected status 0, got", status, stdout.String())
+	}
+}
+
+func TestFormats(t *testing.T) {
+	formats := diff.Capabilities().Formats
+	for _, name := range formatNames {
+		if formats[name] == "" {
+			t.Error("expected", name, "in", formats)
+		}
+	}
+}
+
+func TestRunDecompress(t *testing.T) {
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("one\ntwo\n"))
+	zw.Close()
+	writeFiles(t, dir, map[string]string{"a.gz": buf.String(), "b": "one\n2\n"})
+	a, b := filepath.Join(dir, "a.gz"), filepath.Join(dir, "b")
+	var stdout, stderr strings.Builder
+	if status := run([]string{"-u", a, b}, &stdout, &stderr); status != 1 || !strings.HasPrefix(stdout.String(), "Binary files ") {
+		t.Errorf("expected the compressed file to be binary, got %d with %q", status, stdout.String())
+	}
+	stdout.Reset()
+	if status := run([]string{"-z", "-u", a, b}, &stdout, &stderr); status != 1 {
+		t.Fatal("expected status 1, got", status, stderr.String())
+	}
+	out := stdout.String()
+	if !strings.HasPrefix(out, "--- "+a+" (decompressed)\t") || !strings.Contains(out, "\n+++ "+b+"\t") {
+		t.Errorf("expected the header to mark a as decompressed, got %q", out)
+	}
+	if !strings.HasSuffix(out, "@@ -1,2 +1,2 @@\n one\n-two\n+2\n") {
+		t.Errorf("expected the decompressed lines to differ, got %q", out)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command merge3 merges the changes of two files to a common base like
+// git merge-file, and can be used as a git merge driver.
+//
+// Usage:
+//
+//	merge3 [flags] base ours theirs
+//
+// The merged result replaces ours unless -p is given. The flags are:
+//
+//	-p        write the result to the standard output
+//	-L label  label the conflict markers of ours, base and theirs in this
+//	          order, may be repeated; the file names are used by default
+//	--diff3   include the base of conflicts
+//	--ours    resolve conflicts in favor of ours
+//	--theirs  resolve conflicts in favor of theirs
+//	--union   resolve conflicts by keeping the lines of both sides
+//
+// The exit status is 0 for a clean merge, 1 if conflicts remain and 2 if
+// there was trouble. To use merge3 as a merge driver, configure it with
+//
+//	git config merge.merge3.driver "merge3 -L ours -L base -L theirs %O %A %B"
+//
+// and select it for some files in .gitattributes with "merge=merge3".
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/echlebek/diff"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// labels collects the values of a repeated flag.
+type labels []string
+
+func (l *labels) String() string { return strings.Join(*l, ",") }
+
+func (l *labels) Set(s string) error {
+	if len(*l) == 3 {
+		return errors.New("at most three labels")
+	}
+	*l = append(*l, s)
+	return nil
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("merge3", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: merge3 [flags] base ours theirs")
+		flags.PrintDefaults()
+	}
+	toStdout := flags.Bool("p", false, "write the result to the standard output")
+	var names labels
+	flags.Var(&names, "L", "label the conflict markers of ours, base and theirs in this order")
+	var opts diff.MergeOptions
+	flags.BoolVar(&opts.Diff3, "diff3", false, "include the base of conflicts")
+	ours := flags.Bool("ours", false, "resolve conflicts in favor of ours")
+	theirs := flags.Bool("theirs", false, "resolve conflicts in favor of theirs")
+	union := flags.Bool("union", false, "resolve conflicts by keeping the lines of both sides")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 3 {
+		flags.Usage()
+		return 2
+	}
+	basePath, oursPath, theirsPath := flags.Arg(0), flags.Arg(1), flags.Arg(2)
+	switch {
+	case *ours:
+		opts.Resolve = diff.ResolveOurs
+	case *theirs:
+		opts.Resolve = diff.ResolveTheirs
+	case *union:
+		opts.Resolve = diff.ResolveUnion
+	}
+	opts.Ours, opts.Base, opts.Theirs = oursPath, basePath, theirsPath
+	targets := []*string{&opts.Ours, &opts.Base, &opts.Theirs}
+	for i, label := range names {
+		*targets[i] = label
+	}
+
+	var files [3][]string
+	for i, path := range []string{basePath, oursPath, theirsPath} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(stderr, "merge3:", err)
+			return 2
+		}
+		files[i] = splitLines(string(data))
+	}
+	base, o, t := files[0], files[1], files[2]
+	chunks := diff.Merge3(base, o, t)
+
+	// count the conflicts the resolver leaves
+	conflicts := 0
+	resolve := opts.Resolve
+	opts.Resolve = func(base, ours, theirs []string) ([]string, bool) {
+		if resolve != nil {
+			if lines, ok := resolve(base, ours, theirs); ok {
+				return lines, true
+			}
+		}
+		conflicts++
+		return nil, false
+	}
+	var sb strings.Builder
+	if err := diff.WriteMerge(&sb, base, o, t, chunks, &opts); err != nil {
+		fmt.Fprintln(stderr, "merge3:", err)
+		return 2
+	}
+	if *toStdout {
+		if _, err := io.WriteString(stdout, sb.String()); err != nil {
+			fmt.Fprintln(stderr, "merge3:", err)
+			return 2
+		}
+	} else {
+		fi, err := os.Stat(oursPath)
+		if err == nil {
+			err = os.WriteFile(oursPath, []byte(sb.String()), fi.Mode().Perm())
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "merge3:", err)
+			return 2
+		}
+	}
+	if conflicts > 0 {
+		if conflicts == 1 {
+			fmt.Fprintln(stderr, "merge3: 1 conflict")
+		} else {
+			fmt.Fprintf(stderr, "merge3: %d conflicts\n", conflicts)
+		}
+		return 1
+	}
+	return 0
+}
+
+// splitLines splits s after every newline, keeping a last line without one.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,89 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeVersions writes the base, ours and theirs versions of a file
+// and returns their paths.
+func writeVersions(t *testing.T, base, ours, theirs string) (string, string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	var paths [3]string
+	for i, content := range []string{base, ours, theirs} {
+		paths[i] = filepath.Join(dir, []string{"base", "ours", "theirs"}[i])
+		if err := os.WriteFile(paths[i], []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths[0], paths[1], paths[2]
+}
+
+func TestRunClean(t *testing.T) {
+	base, ours, theirs := writeVersions(t, "a\nb\nc\nd\ne\n", "A\nb\nc\nd\ne\n", "a\nb\nc\nd\nE\n")
+	var stdout, stderr strings.Builder
+	if status := run([]string{base, ours, theirs}, &stdout, &stderr); status != 0 {
+		t.Fatal("expected status 0, got", status, stderr.String())
+	}
+	data, err := os.ReadFile(ours)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "A\nb\nc\nd\nE\n"; string(data) != expect {
+		t.Errorf("expected %q, got %q", expect, data)
+	}
+}
+
+func TestRunConflict(t *testing.T) {
+	base, ours, theirs := writeVersions(t, "a\nb\nc\n", "a\nB\nc\n", "a\nX\nc\n")
+	var stdout, stderr strings.Builder
+	status := run([]string{"-p", "--diff3", "-L", "HEAD", "-L", "merged common ancestors", "-L", "topic", base, ours, theirs}, &stdout, &stderr)
+	if status != 1 {
+		t.Fatal("expected status 1, got", status, stderr.String())
+	}
+	expect := "a\n<<<<<<< HEAD\nB\n||||||| merged common ancestors\nb\n=======\nX\n>>>>>>> topic\nc\n"
+	if stdout.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, stdout.String())
+	}
+	if stderr.String() != "merge3: 1 conflict\n" {
+		t.Errorf("unexpected message %q", stderr.String())
+	}
+	// -p leaves ours alone
+	if data, _ := os.ReadFile(ours); string(data) != "a\nB\nc\n" {
+		t.Errorf("expected ours to be unchanged, got %q", data)
+	}
+
+	for flag, expect := range map[string]string{
+		"--ours":   "a\nB\nc\n",
+		"--theirs": "a\nX\nc\n",
+		"--union":  "a\nB\nX\nc\n",
+	} {
+		stdout.Reset()
+		if status := run([]string{"-p", flag, base, ours, theirs}, &stdout, &stderr); status != 0 {
+			t.Error(flag, "expected status 0, got", status)
+		}
+		if stdout.String() != expect {
+			t.Errorf("%s: expected %q, got %q", flag, expect, stdout.String())
+		}
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr strings.Builder
+	for _, args := range [][]string{
+		{"base", "ours"},
+		{"-L", "a", "-L", "b", "-L", "c", "-L", "d", "x", "y", "z"},
+		{"missing", "files", "here"},
+	} {
+		if status := run(args, &stdout, &stderr); status != 2 {
+			t.Error(args, "expected status 2, got", status)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command patch applies unified diffs and git patches to files like GNU patch.
+//
+// Usage:
+//
+//	patch [flags] < file.patch
+//
+// The flags are:
+//
+//	-i file    read the patch from file instead of the standard input
+//	-d dir     change to dir before applying the patch
+//	-p num     strip num leading components from the file names in the patch,
+//	           also written -pnum
+//	-R         apply the patch in reverse
+//	-F num     ignore up to num common lines at the ends of hunks (default 2),
+//	           also written -Fnum
+//	--dry-run  print what would happen without changing any files
+//
+// Without -p, the a/ and b/ prefixes of the file names in git patches are
+// removed, so git patches apply like with -p 1. File names that are absolute
+// or contain .. components are rejected.
+// Hunks that do not apply are saved to a file with the suffix .rej.
+// The exit status is 0 if all hunks applied, 1 if some were rejected and 2
+// if there was trouble.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/echlebek/diff"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// A patcher applies patches with the options of the command line.
+type patcher struct {
+	w       io.Writer
+	dir     string
+	strip   int // -1 without -p
+	reverse bool
+	opts    diff.ApplyOptions
+	dryRun  bool
+	// headers holds the file names as they appear in the patch
+	headers map[string]bool
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("patch", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: patch [flags] < file.patch")
+		flags.PrintDefaults()
+	}
+	p := patcher{w: stdout}
+	input := flags.String("i", "", "read the patch from `file`")
+	flags.StringVar(&p.dir, "d", "", "change to `dir` before applying the patch")
+	flags.IntVar(&p.strip, "p", -1, "strip `num` leading components from the file names in the patch")
+	flags.BoolVar(&p.reverse, "R", false, "apply the patch in reverse")
+	flags.IntVar(&p.opts.Fuzz, "F", 2, "ignore up to `num` common lines at the ends of hunks")
+	flags.BoolVar(&p.dryRun, "dry-run", false, "print what would happen without changing any files")
+	if err := flags.Parse(splitAttached(args)); err != nil {
+		return 2
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return 2
+	}
+	r := stdin
+	if *input != "" {
+		f, err := os.Open(*input)
+		if err != nil {
+			fmt.Fprintln(stderr, "patch:", err)
+			return 2
+		}
+		defer f.Close()
+		r = f
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		fmt.Fprintln(stderr, "patch:", err)
+		return 2
+	}
+	p.headers = headerNames(string(data))
+	patch, err := diff.ParsePatch(bytes.NewReader(data))
+	if err != nil {
+		fmt.Fprintln(stderr, "patch:", err)
+		return 2
+	}
+	if len(patch) == 0 {
+		fmt.Fprintln(stderr, "patch: no patch found in the input")
+		return 2
+	}
+	status := 0
+	for _, f := range patch {
+		ok, err := p.apply(f)
+		if err != nil {
+			fmt.Fprintln(stderr, "patch:", err)
+			return 2
+		}
+		if !ok {
+			status = 1
+		}
+	}
+	return status
+}
+
+// apply applies the patch of one file and reports whether all hunks applied.
+func (p *patcher) apply(f diff.FilePatch) (bool, error) {
+	oldPath, newPath := p.header(f.OldPath, "a/"), p.header(f.NewPath, "b/")
+	if p.reverse {
+		f = f.Invert()
+		oldPath, newPath = newPath, oldPath
+	}
+	var err error
+	if oldPath != "" {
+		if oldPath, err = p.path(oldPath); err != nil {
+			return false, err
+		}
+	}
+	if newPath != "" {
+		if newPath, err = p.path(newPath); err != nil {
+			return false, err
+		}
+	}
+	target := oldPath
+	if target == "" {
+		target = newPath
+	}
+	if f.Binary {
+		return false, fmt.Errorf("can not apply the binary patch of %s", target)
+	}
+	verb := "patching"
+	if p.dryRun {
+		verb = "checking"
+	}
+	fmt.Fprintf(p.w, "%s file %s\n", verb, target)
+
+	var lines []string
+	mode := fs.FileMode(0o644)
+	if oldPath != "" {
+		data, err := os.ReadFile(oldPath)
+		if err != nil {
+			return false, err
+		}
+		lines = splitLines(string(data))
+		if fi, err := os.Stat(oldPath); err == nil {
+			mode = fi.Mode().Perm()
+		}
+	}
+	res, results, err := diff.Apply(lines, f.Hunks, &p.opts)
+	var rejected *diff.ApplyError
+	if err != nil && !errors.As(err, &rejected) {
+		return false, err
+	}
+	for i, r := range results {
+		switch {
+		case r.Pos < 0:
+			fmt.Fprintf(p.w, "Hunk #%d FAILED at %d.\n", i+1, f.Hunks[i].A+1)
+		case r.Fuzz > 0 && r.Offset != 0:
+			fmt.Fprintf(p.w, "Hunk #%d succeeded at %d with fuzz %d (offset %d %s).\n", i+1, r.Pos+1, r.Fuzz, r.Offset, plural(r.Offset))
+		case r.Fuzz > 0:
+			fmt.Fprintf(p.w, "Hunk #%d succeeded at %d with fuzz %d.\n", i+1, r.Pos+1, r.Fuzz)
+		case r.Offset != 0:
+			fmt.Fprintf(p.w, "Hunk #%d succeeded at %d (offset %d %s).\n", i+1, r.Pos+1, r.Offset, plural(r.Offset))
+		}
+	}
+	if rejected != nil {
+		fmt.Fprintf(p.w, "%d out of %d hunks FAILED", len(rejected.Rejected), rejected.Hunks)
+		if !p.dryRun {
+			fmt.Fprintf(p.w, " -- saving rejects to file %s.rej", target)
+		}
+		fmt.Fprintln(p.w)
+	}
+	if p.dryRun {
+		return rejected == nil, nil
+	}
+	if rejected != nil {
+		if err := p.writeRejects(target, f, rejected.Rejected); err != nil {
+			return false, err
+		}
+	}
+
+	if newPath == "" {
+		if len(res) > 0 {
+			fmt.Fprintf(p.w, "Not deleting file %s as content differs from patch\n", oldPath)
+			return false, nil
+		}
+		return rejected == nil, os.Remove(oldPath)
+	}
+	if dir := filepath.Dir(newPath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return false, err
+		}
+	}
+	if err := os.WriteFile(newPath, []byte(strings.Join(res, "")), mode); err != nil {
+		return false, err
+	}
+	if oldPath != "" && oldPath != newPath {
+		if err := os.Remove(oldPath); err != nil {
+			return false, err
+		}
+	}
+	return rejected == nil, nil
+}
+
+// header returns the name of a file as it appears in the patch header if
+// -p was given. ParsePatch removes the prefix of the old or new name.
+func (p *patcher) header(name, prefix string) string {
+	if p.strip < 0 || name == "" || !p.headers[prefix+name] {
+		return name
+	}
+	return prefix + name
+}
+
+// path returns the name of a file in the patch with p.strip leading
+// components removed, relative to p.dir.
+func (p *patcher) path(name string) (string, error) {
+	parts := strings.Split(name, "/")
+	strip := max(p.strip, 0)
+	if strip >= len(parts) {
+		return "", fmt.Errorf("can not strip %d components from %s", strip, name)
+	}
+	// empty components, like in a//tmp/x, leave an absolute path
+	rel := filepath.FromSlash(strings.Join(parts[strip:], "/"))
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("refusing to patch %s outside of the directory", name)
+	}
+	return filepath.Join(p.dir, rel), nil
+}
+
+// splitAttached splits the flags -p and -F from attached numbers,
+// so that -p1 is accepted like with GNU patch.
+func splitAttached(args []string) []string {
+	var res []string
+	for i, arg := range args {
+		if arg == "--" {
+			return append(res, args[i:]...)
+		}
+		if len(arg) > 2 && (arg[:2] == "-p" || arg[:2] == "-F") && isDigits(arg[2:]) {
+			res = append(res, arg[:2], arg[2:])
+			continue
+		}
+		res = append(res, arg)
+	}
+	return res
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// headerNames returns the file names of the ---, +++ and diff --git
+// lines of a patch with their a/ and b/ prefixes.
+func headerNames(patch string) map[string]bool {
+	names := make(map[string]bool)
+	for _, line := range strings.Split(patch, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+			name, _, _ := strings.Cut(line[4:], "\t")
+			names[name] = true
+		case strings.HasPrefix(line, "diff --git a/"):
+			s := line[len("diff --git "):]
+			if i := strings.Index(s, " b/"); i >= 0 {
+				names[s[:i]] = true
+				names[s[i+1:]] = true
+			}
+		}
+	}
+	return names
+}
+
+// writeRejects writes the rejected hunks of f to the file target.rej.
+func (p *patcher) writeRejects(target string, f diff.FilePatch, rejected []int) error {
+	rej := diff.FilePatch{OldPath: f.OldPath, NewPath: f.NewPath}
+	for _, i := range rejected {
+		rej.Hunks = append(rej.Hunks, f.Hunks[i])
+	}
+	var sb strings.Builder
+	if err := diff.WritePatch(&sb, diff.Patch{rej}); err != nil {
+		return err
+	}
+	// the rejects are a plain unified diff
+	out := sb.String()
+	if i := strings.Index(out, "\n--- "); i >= 0 {
+		out = out[i+1:]
+	}
+	return os.WriteFile(target+".rej", []byte(out), 0o644)
+}
+
+// plural returns the unit of an offset of n lines.
+func plural(n int) string {
+	if n == 1 || n == -1 {
+		return "line"
+	}
+	return "lines"
+}
+
+// splitLines splits s after every newline, keeping a last line without one.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,183 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const gitPatch = `diff --git a/f b/f
+--- a/f
++++ b/f
+@@ -1,3 +1,3 @@
+ one
+-two
++TWO
+ three
+diff --git a/new b/new
+new file mode 100644
+--- /dev/null
++++ b/new
+@@ -0,0 +1 @@
++created
+diff --git a/old b/old
+deleted file mode 100644
+--- a/old
++++ /dev/null
+@@ -1 +0,0 @@
+-removed
+`
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func setup(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{"f": "one\ntwo\nthree\n", "old": "removed\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRun(t *testing.T) {
+	dir := setup(t)
+	var stdout, stderr strings.Builder
+	if status := run([]string{"-d", dir, "--dry-run"}, strings.NewReader(gitPatch), &stdout, &stderr); status != 0 {
+		t.Fatal("expected status 0, got", status, stderr.String())
+	}
+	if got := readFile(t, filepath.Join(dir, "f")); got != "one\ntwo\nthree\n" {
+		t.Error("dry run changed f to", got)
+	}
+	if expect := "checking file " + filepath.Join(dir, "f") + "\n"; !strings.HasPrefix(stdout.String(), expect) {
+		t.Errorf("expected output to start with %q, got %q", expect, stdout.String())
+	}
+
+	if status := run([]string{"-d", dir}, strings.NewReader(gitPatch), &stdout, &stderr); status != 0 {
+		t.Fatal("expected status 0, got", status, stderr.String())
+	}
+	if got := readFile(t, filepath.Join(dir, "f")); got != "one\nTWO\nthree\n" {
+		t.Error("expected f to be patched, got", got)
+	}
+	if got := readFile(t, filepath.Join(dir, "new")); got != "created\n" {
+		t.Error("expected new to be created, got", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old")); !os.IsNotExist(err) {
+		t.Error("expected old to be deleted, got", err)
+	}
+
+	// reversing restores all files
+	if status := run([]string{"-d", dir, "-R"}, strings.NewReader(gitPatch), &stdout, &stderr); status != 0 {
+		t.Fatal("expected status 0, got", status, stderr.String())
+	}
+	if got := readFile(t, filepath.Join(dir, "f")); got != "one\ntwo\nthree\n" {
+		t.Error("expected f to be restored, got", got)
+	}
+	if got := readFile(t, filepath.Join(dir, "old")); got != "removed\n" {
+		t.Error("expected old to be restored, got", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new")); !os.IsNotExist(err) {
+		t.Error("expected new to be deleted, got", err)
+	}
+}
+
+func TestRunStrip(t *testing.T) {
+	dir := setup(t)
+	patch := "--- orig/f\t2024-01-01\n+++ changed/f\t2024-01-02\n@@ -1,3 +1,3 @@\n one\n-two\n+TWO\n three\n"
+	input := filepath.Join(t.TempDir(), "p.diff")
+	if err := os.WriteFile(input, []byte(patch), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr strings.Builder
+	if status := run([]string{"-d", dir, "-p", "1", "-i", input}, nil, &stdout, &stderr); status != 0 {
+		t.Fatal("expected status 0, got", status, stderr.String())
+	}
+	if got := readFile(t, filepath.Join(dir, "f")); got != "one\nTWO\nthree\n" {
+		t.Error("expected f to be patched, got", got)
+	}
+	if status := run([]string{"-d", dir, "-p", "2", "-i", input}, nil, &stdout, &stderr); status != 2 {
+		t.Error("expected status 2 for too many stripped components, got", status)
+	}
+}
+
+func TestRunStripGit(t *testing.T) {
+	dir := setup(t)
+	var stdout, stderr strings.Builder
+	// -p applies to the names with their a/ and b/ prefixes
+	if status := run([]string{"-d", dir, "-p1"}, strings.NewReader(gitPatch), &stdout, &stderr); status != 0 {
+		t.Fatal("expected status 0, got", status, stderr.String())
+	}
+	if got := readFile(t, filepath.Join(dir, "f")); got != "one\nTWO\nthree\n" {
+		t.Error("expected f to be patched, got", got)
+	}
+	if got := readFile(t, filepath.Join(dir, "new")); got != "created\n" {
+		t.Error("expected new to be created, got", got)
+	}
+}
+
+func TestRunUnsafePaths(t *testing.T) {
+	dir := setup(t)
+	victim := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(victim, []byte("one\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name  string
+		strip string
+	}{
+		{"/etc/f", "0"},
+		{"../f", "0"},
+		{"x/../../f", "0"},
+		// a double slash after the stripped prefix
+		{"a/" + filepath.ToSlash(victim), "1"},
+		{"x/y/" + filepath.ToSlash(victim), "2"},
+	} {
+		patch := "--- " + test.name + "\n+++ " + test.name + "\n@@ -1 +1 @@\n-one\n+ONE\n"
+		var stdout, stderr strings.Builder
+		if status := run([]string{"-d", dir, "-p" + test.strip}, strings.NewReader(patch), &stdout, &stderr); status != 2 || !strings.Contains(stderr.String(), "refusing") {
+			t.Error(test.name, "expected status 2, got", status, stderr.String())
+		}
+	}
+	if got := readFile(t, victim); got != "one\n" {
+		t.Error("expected the file outside of the directory to be kept, got", got)
+	}
+}
+
+func TestRunRejects(t *testing.T) {
+	dir := t.TempDir()
+	f := filepath.Join(dir, "f")
+	if err := os.WriteFile(f, []byte("zero\none\ntwo\nthree\nfour\nfive\nsix\nseven\nEIGHT\nnine\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	patch := "--- a/f\n+++ b/f\n@@ -1,3 +1,3 @@\n one\n-two\n+TWO\n three\n@@ -7,3 +7,3 @@\n seven\n-eight\n+8\n nine\n"
+	var stdout, stderr strings.Builder
+	if status := run([]string{"-d", dir, "-F", "0"}, strings.NewReader(patch), &stdout, &stderr); status != 1 {
+		t.Fatal("expected status 1, got", status, stderr.String())
+	}
+	expect := "patching file " + f + "\n" +
+		"Hunk #1 succeeded at 2 (offset 1 line).\n" +
+		"Hunk #2 FAILED at 7.\n" +
+		"1 out of 2 hunks FAILED -- saving rejects to file " + f + ".rej\n"
+	if stdout.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, stdout.String())
+	}
+	if got := readFile(t, f); got != "zero\none\nTWO\nthree\nfour\nfive\nsix\nseven\nEIGHT\nnine\n" {
+		t.Error("expected the first hunk to be applied, got", got)
+	}
+	if got, expect := readFile(t, f+".rej"), "--- a/f\n+++ b/f\n@@ -7,3 +7,3 @@\n seven\n-eight\n+8\n nine\n"; got != expect {
+		t.Errorf("expected rejects\n%s\ngot\n%s", expect, got)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// Split splits every change into changes of at most one deleted and one
+// inserted element, pairing the deleted and inserted elements in order.
+// For line diffs this results in one change per line.
+func Split(changes []Change) []Change {
+	var res []Change
+	for _, c := range changes {
+		for k := 0; k < c.Del || k < c.Ins; k++ {
+			s := Change{A: c.A + k, B: c.B + k}
+			if k < c.Del {
+				s.Del = 1
+			} else {
+				s.A = c.A + c.Del
+			}
+			if k < c.Ins {
+				s.Ins = 1
+			} else {
+				s.B = c.B + c.Ins
+			}
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// Coalesce merges neighboring changes if merge returns true for the
+// common elements between them, which are a[gapStart:gapStart+gapLen].
+// Merged changes are compared with the next change as a whole.
+// The changes must be ordered by ascending positions and are merged in place.
+func Coalesce(changes []Change, merge func(gapStart, gapLen int) bool) []Change {
+	if len(changes) == 0 {
+		return changes
+	}
+	n := 1
+	for _, curr := range changes[1:] {
+		prev := &changes[n-1]
+		if end := prev.A + prev.Del; merge(end, curr.A-end) {
+			prev.Del = curr.A - prev.A + curr.Del
+			prev.Ins = curr.B - prev.B + curr.Ins
+			continue
+		}
+		changes[n] = curr
+		n++
+	}
+	return changes[:n]
+}
+
+// GranularFunc merges neighboring changes like Granular, but measures the
+// common elements between them with distance, for example by counting the
+// words or lines of a byte diff. Changes are merged if the distance of the
+// gap a[gapStart:gapStart+gapLen] is at most granularity.
+func GranularFunc(granularity int, changes []Change, distance func(gapStart, gapLen int) int) []Change {
+	return Coalesce(changes, func(start, n int) bool { return distance(start, n) <= granularity })
+}
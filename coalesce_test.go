@@ -0,0 +1,71 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestSplit(t *testing.T) {
+	changes := []diff.Change{{A: 1, B: 1, Del: 3, Ins: 1}, {A: 6, B: 4, Del: 0, Ins: 2}}
+	expect := []diff.Change{
+		{A: 1, B: 1, Del: 1, Ins: 1},
+		{A: 2, B: 2, Del: 1, Ins: 0},
+		{A: 3, B: 2, Del: 1, Ins: 0},
+		{A: 6, B: 4, Del: 0, Ins: 1},
+		{A: 6, B: 5, Del: 0, Ins: 1},
+	}
+	if res := diff.Split(changes); !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		a, b := randomInts(r, r.Intn(20), 4), randomInts(r, r.Intn(20), 4)
+		if res := diff.Split(diff.Ints(a, b)); diff.Check(a, b, res) != nil {
+			t.Fatal(a, b, "split to invalid", res)
+		}
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	a := []int{1, 0, 2, 0, 0, 3, 0, 0, 0, 4}
+	b := []int{5, 0, 6, 0, 0, 7, 0, 0, 0, 8}
+	// merge only the gaps that start at even positions
+	res := diff.Coalesce(diff.Ints(a, b), func(start, n int) bool { return start%2 == 0 })
+	expect := []diff.Change{{A: 0, B: 0, Del: 1, Ins: 1}, {A: 2, B: 2, Del: 1, Ins: 1}, {A: 5, B: 5, Del: 5, Ins: 5}}
+	if !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	// coalescing is Granular with a predicate
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		a, b := randomInts(r, r.Intn(20), 4), randomInts(r, r.Intn(20), 4)
+		g := r.Intn(3)
+		res := diff.Coalesce(diff.Ints(a, b), func(_, n int) bool { return n <= g })
+		if expect := diff.Granular(g, diff.Ints(a, b)); !diffsEqual(res, expect) {
+			t.Fatal(a, b, "expected", expect, "got", res)
+		}
+	}
+}
+
+func TestGranularFunc(t *testing.T) {
+	a := "the quick brown fox jumps over the lazy dog"
+	b := "the quick brawn fox jumps over the lazy dug"
+	words := func(start, n int) int { return len(strings.Fields(a[start : start+n])) }
+	// the gap between the changes touches 7 words
+	changes := diff.ByteStrings(a, b)
+	if res := diff.GranularFunc(6, changes, words); len(res) != 2 {
+		t.Error("expected two changes, got", res)
+	}
+	changes = diff.ByteStrings(a, b)
+	expect := []diff.Change{{A: 12, B: 12, Del: 30, Ins: 30}}
+	if res := diff.GranularFunc(7, changes, words); !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+}
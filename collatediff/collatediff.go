@@ -0,0 +1,27 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package collatediff compares lines by the collation rules of a language.
+// It is separate from package diff to keep golang.org/x/text and its
+// tables out of programs that do not need them.
+package collatediff
+
+import (
+	"golang.org/x/text/collate"
+
+	"github.com/echlebek/diff"
+)
+
+// WithCollator makes line diffs like diff.Lines compare strings with the
+// collation keys of c, so that strings that are equal in its language
+// match, like differently normalized ones or, with collate.IgnoreCase,
+// differently cased ones. See diff.WithLineKey. A Collator is not safe
+// for concurrent use, so the option must not be shared by concurrent diffs.
+func WithCollator(c *collate.Collator) diff.Option {
+	var buf collate.Buffer
+	return diff.WithLineKey(func(s string) string {
+		defer buf.Reset()
+		return string(c.KeyFromString(&buf, s))
+	})
+}
@@ -0,0 +1,30 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collatediff_test
+
+import (
+	"testing"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+
+	"github.com/echlebek/diff"
+	"github.com/echlebek/diff/collatediff"
+)
+
+func TestWithCollator(t *testing.T) {
+	a := []string{"caf\u00e9\n", "Hello\n", "end\n"}
+	b := []string{"cafe\u0301\n", "hello\n", "end\n"}
+	if res := diff.Lines(a, b); len(res) != 1 || res[0].Del != 2 {
+		t.Error("expected the lines to differ without a collator, got", res)
+	}
+	expect := diff.Change{A: 1, B: 1, Del: 1, Ins: 1}
+	if res := diff.Lines(a, b, collatediff.WithCollator(collate.New(language.French))); len(res) != 1 || res[0] != expect {
+		t.Error("expected", expect, "got", res)
+	}
+	if res := diff.Lines(a, b, collatediff.WithCollator(collate.New(language.French, collate.IgnoreCase))); len(res) != 0 {
+		t.Error("expected no changes ignoring case, got", res)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"io"
+	"strings"
+)
+
+// WriteCombined writes how the lines of result differ from the lines of
+// each of the parents in git's combined diff format, with context common
+// lines around the changes. Every line has one column per parent that is
+// '+' if the parent lacks a line of the result, '-' if the result lacks
+// the line of the parent and ' ' otherwise. Nothing is written if the
+// result equals all parents.
+func WriteCombined(w io.Writer, name string, parents [][]string, result []string, context int) error {
+	seqs := append([][]string{result}, parents...)
+	cols := MultiAlign(0, seqs...)
+	// pos[i][k] is the number of lines of seqs[k] before column i
+	pos := make([][]int, len(cols)+1)
+	pos[0] = make([]int, len(seqs))
+	var changed []int
+	for i, col := range cols {
+		pos[i+1] = make([]int, len(seqs))
+		for k, y := range col {
+			pos[i+1][k] = pos[i][k]
+			if y != -1 {
+				pos[i+1][k]++
+			}
+		}
+		for _, y := range col {
+			if y == -1 {
+				changed = append(changed, i)
+				break
+			}
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("diff --combined " + name + "\n")
+	sb.WriteString("--- a/" + name + "\n")
+	sb.WriteString("+++ b/" + name + "\n")
+	marker := strings.Repeat("@", len(parents)+1)
+	for i := 0; i < len(changed); {
+		// merge the changes that share context lines
+		j := i + 1
+		for j < len(changed) && changed[j]-changed[j-1] <= 2*context+1 {
+			j++
+		}
+		lo, hi := changed[i]-context, changed[j-1]+1+context
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(cols) {
+			hi = len(cols)
+		}
+		sb.WriteString(marker)
+		for k := range parents {
+			sb.WriteString(" -" + unifiedRange(pos[lo][k+1], pos[hi][k+1]-pos[lo][k+1]))
+		}
+		sb.WriteString(" +" + unifiedRange(pos[lo][0], pos[hi][0]-pos[lo][0]) + " " + marker + "\n")
+		for _, col := range cols[lo:hi] {
+			line := ""
+			for k, y := range col[1:] {
+				switch {
+				case col[0] != -1 && y == -1:
+					sb.WriteByte('+')
+				case col[0] == -1 && y != -1:
+					sb.WriteByte('-')
+					line = parents[k][y]
+				default:
+					sb.WriteByte(' ')
+				}
+			}
+			if col[0] != -1 {
+				line = result[col[0]]
+			}
+			sb.WriteString(line)
+			if !strings.HasSuffix(line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = j
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
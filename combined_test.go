@@ -0,0 +1,43 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestWriteCombined(t *testing.T) {
+	ours := splitLines("a\nb\nc\nd\ne\nf\ng\nh\n")
+	theirs := splitLines("a\nb\nc\nD\ne\nf\ng\nh\n")
+	result := splitLines("a\nb\nc\nd\nD\ne\nf\ng\nH\n")
+	var sb strings.Builder
+	if err := diff.WriteCombined(&sb, "file", [][]string{ours, theirs}, result, 1); err != nil {
+		t.Fatal(err)
+	}
+	expect := `diff --combined file
+--- a/file
++++ b/file
+@@@ -3,3 -3,3 +3,4 @@@
+  c
+ +d
++ D
+  e
+@@@ -7,2 -7,2 +8,2 @@@
+  g
+--h
+++H
+`
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+
+	sb.Reset()
+	if err := diff.WriteCombined(&sb, "file", [][]string{ours, ours}, ours, 3); err != nil || sb.Len() != 0 {
+		t.Error("expected no output, got", sb.String(), err)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A CommHandler receives the lines compared by Comm without their newline.
+// Nil functions are not called. An error returned by a function stops Comm.
+type CommHandler struct {
+	OnlyA, OnlyB, Both func(line string) error
+}
+
+// Comm compares two streams of lines sorted by byte value like comm and
+// calls the handler for every line in order. Only one line of each stream
+// is held in memory at a time, so the streams may be arbitrarily long.
+// Lines that occur several times are matched as often as they occur in both.
+// An error is returned if a stream is not sorted.
+func Comm(a, b io.Reader, h CommHandler) error {
+	la, lb := &lineReader{r: bufio.NewReader(a), name: "a"}, &lineReader{r: bufio.NewReader(b), name: "b"}
+	if err := la.next(); err != nil {
+		return err
+	}
+	if err := lb.next(); err != nil {
+		return err
+	}
+	for la.ok || lb.ok {
+		var err error
+		switch {
+		case !lb.ok || la.ok && la.line < lb.line:
+			err = callLine(h.OnlyA, la.line)
+			if err == nil {
+				err = la.next()
+			}
+		case !la.ok || lb.line < la.line:
+			err = callLine(h.OnlyB, lb.line)
+			if err == nil {
+				err = lb.next()
+			}
+		default:
+			err = callLine(h.Both, la.line)
+			if err == nil {
+				err = la.next()
+			}
+			if err == nil {
+				err = lb.next()
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func callLine(fn func(string) error, line string) error {
+	if fn == nil {
+		return nil
+	}
+	return fn(line)
+}
+
+// lineReader reads the lines of a sorted stream one at a time.
+type lineReader struct {
+	r    *bufio.Reader
+	name string
+	line string
+	ok   bool
+	n    int
+}
+
+// next reads the next line, ok is false at the end of the stream.
+func (l *lineReader) next() error {
+	line, err := l.r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if line == "" && err == io.EOF {
+		l.ok = false
+		return nil
+	}
+	line = strings.TrimSuffix(line, "\n")
+	l.n++
+	if l.n > 1 && line < l.line {
+		return fmt.Errorf("diff: line %d of %s is not in sorted order", l.n, l.name)
+	}
+	l.line, l.ok = line, true
+	return nil
+}
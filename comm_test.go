@@ -0,0 +1,49 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestComm(t *testing.T) {
+	var res []string
+	record := func(prefix string) func(string) error {
+		return func(line string) error {
+			res = append(res, prefix+line)
+			return nil
+		}
+	}
+	a := strings.NewReader("apple\nbanana\nbanana\ncherry\nfig")
+	b := strings.NewReader("banana\ncherry\ndate\nfig\ngrape\n")
+	err := diff.Comm(a, b, diff.CommHandler{OnlyA: record("<"), OnlyB: record(">"), Both: record("=")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"<apple", "=banana", "<banana", "=cherry", ">date", "=fig", ">grape"}
+	if strings.Join(res, " ") != strings.Join(expect, " ") {
+		t.Error("expected", expect, "got", res)
+	}
+}
+
+func TestCommErrors(t *testing.T) {
+	err := diff.Comm(strings.NewReader("a\nc\nb\n"), strings.NewReader(""), diff.CommHandler{})
+	if err == nil || !strings.Contains(err.Error(), "line 3 of a") {
+		t.Error("expected an unsorted error, got", err)
+	}
+	stop := errors.New("stop")
+	calls := 0
+	err = diff.Comm(strings.NewReader("a\nb\n"), strings.NewReader("a\nb\n"), diff.CommHandler{Both: func(string) error {
+		calls++
+		return stop
+	}})
+	if err != stop || calls != 1 {
+		t.Error("expected to stop after the first line, got", err, calls)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "sort"
+
+// Squash diffs a chain of versions. lens holds the length of each version
+// and step(i) compares version i (as a) with version i+1 (as b).
+// It returns the changes of every step and the changes from the first to the
+// last version, which are composed from the steps instead of being diffed again.
+// The squashed changes are valid but not necessarily minimal: an element that
+// is deleted in one step and inserted again in another is reported as changed.
+func Squash(lens []int, step func(i int) Data) (steps [][]Change, squashed []Change) {
+	if len(lens) < 2 {
+		return nil, nil
+	}
+	steps = make([][]Change, len(lens)-1)
+	for i := range steps {
+		steps[i] = Diff(lens[i], lens[i+1], step(i))
+		if i == 0 {
+			squashed = steps[i]
+		} else {
+			squashed = Compose(squashed, steps[i])
+		}
+	}
+	return steps, squashed
+}
+
+// Compose returns the changes from a to c given the changes ab from a to b
+// and bc from b to c, without diffing a and c. Both must be ordered by
+// ascending positions. Like Squash, the result is valid but not necessarily
+// minimal.
+func Compose(ab, bc []Change) []Change {
+	// every change touches an interval of b: the inserted elements of ab
+	// and the deleted elements of bc. Overlapping or touching intervals
+	// form one change from a to c.
+	type span struct {
+		lo, hi int
+		ab     bool
+		c      Change
+	}
+	spans := make([]span, 0, len(ab)+len(bc))
+	for _, c := range ab {
+		spans = append(spans, span{c.B, c.B + c.Ins, true, c})
+	}
+	for _, c := range bc {
+		spans = append(spans, span{c.A, c.A + c.Del, false, c})
+	}
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].lo != spans[j].lo {
+			return spans[i].lo < spans[j].lo
+		}
+		return spans[i].hi < spans[j].hi
+	})
+
+	var res []Change
+	dab, dbc := 0, 0 // net length difference of ab and bc before the current position
+	for i := 0; i < len(spans); {
+		lo, hi := spans[i].lo, spans[i].hi
+		astart, cstart := lo-dab, lo+dbc
+		for ; i < len(spans) && spans[i].lo <= hi; i++ {
+			if spans[i].hi > hi {
+				hi = spans[i].hi
+			}
+			if c := spans[i].c; spans[i].ab {
+				dab += c.Ins - c.Del
+			} else {
+				dbc += c.Ins - c.Del
+			}
+		}
+		del, ins := hi-dab-astart, hi+dbc-cstart
+		if del != 0 || ins != 0 {
+			res = append(res, Change{astart, cstart, del, ins})
+		}
+	}
+	return res
+}
+
+// Commute reorders two consecutive patches, ab from a to b and bc from b to c.
+// It returns the changes of bc rebased onto a, which turn a into some b2,
+// and the changes of ab rebased onto b2, which turn b2 into c.
+// This is only possible if no change of bc modifies what a change of ab
+// inserted or inserts into it; otherwise ok is false.
+// The changes of one patch never depend on each other and can always be
+// applied in any order once their positions are adjusted.
+func Commute(ab, bc []Change) (bc2, ab2 []Change, ok bool) {
+	// intervals of b touched by the changes
+	pspan := func(c Change) (int, int) { return c.B, c.B + c.Ins }
+	qspan := func(c Change) (int, int) { return c.A, c.A + c.Del }
+	for _, p := range ab {
+		ps, pe := pspan(p)
+		for _, q := range bc {
+			qs, qe := qspan(q)
+			if qs > pe {
+				break
+			}
+			if ps < qe && qs < pe {
+				return nil, nil, false
+			}
+		}
+	}
+	bc2 = make([]Change, len(bc))
+	dab, i := 0, 0
+	for k, q := range bc {
+		qs, _ := qspan(q)
+		// p comes before q
+		for ; i < len(ab) && ab[i].B+ab[i].Ins <= qs; i++ {
+			dab += ab[i].Ins - ab[i].Del
+		}
+		bc2[k] = Change{q.A - dab, q.B - dab, q.Del, q.Ins}
+	}
+	ab2 = make([]Change, len(ab))
+	dbc, j := 0, 0
+	for k, p := range ab {
+		ps, pe := pspan(p)
+		// q comes before p, a deletion of ab goes before an insertion of bc at the same spot
+		for ; j < len(bc); j++ {
+			qs, qe := qspan(bc[j])
+			if qe > ps || qe == ps && qs == qe && ps == pe {
+				break
+			}
+			dbc += bc[j].Ins - bc[j].Del
+		}
+		ab2[k] = Change{p.A + dbc, p.B + dbc, p.Del, p.Ins}
+	}
+	return bc2, ab2, true
+}
@@ -0,0 +1,167 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func randomInts(r *rand.Rand, n, alphabet int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = r.Intn(alphabet)
+	}
+	return s
+}
+
+func TestSquash(t *testing.T) {
+	versions := [][]int{
+		{1, 2, 3, 4, 5},
+		{1, 3, 4, 5, 6},
+		{0, 1, 3, 4, 6},
+		{0, 1, 2, 3, 4, 6},
+	}
+	lens := make([]int, len(versions))
+	for i, v := range versions {
+		lens[i] = len(v)
+	}
+	steps, squashed := diff.Squash(lens, func(i int) diff.Data {
+		return &ints{versions[i], versions[i+1]}
+	})
+	if len(steps) != len(versions)-1 {
+		t.Fatal("expected", len(versions)-1, "steps, got", len(steps))
+	}
+	for i, s := range steps {
+		if !diffsEqual(s, diff.Ints(versions[i], versions[i+1])) {
+			t.Error("step", i, "got", s)
+		}
+	}
+	// 2 is deleted and inserted again; composing does not look at elements
+	expect := []diff.Change{{A: 0, B: 0, Del: 0, Ins: 1}, {A: 1, B: 2, Del: 1, Ins: 1}, {A: 4, B: 5, Del: 1, Ins: 1}}
+	if !diffsEqual(squashed, expect) {
+		t.Error("expected", expect, "got", squashed)
+	}
+}
+
+func TestSquashRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		versions := make([][]int, 2+r.Intn(4))
+		lens := make([]int, len(versions))
+		for j := range versions {
+			versions[j] = randomInts(r, r.Intn(12), 4)
+			lens[j] = len(versions[j])
+		}
+		_, squashed := diff.Squash(lens, func(i int) diff.Data {
+			return &ints{versions[i], versions[i+1]}
+		})
+		first, last := versions[0], versions[len(versions)-1]
+		if err := diff.Check(first, last, squashed); err != nil {
+			t.Fatal(versions, "squashed to invalid", squashed, err)
+		}
+	}
+}
+
+func TestSquashShort(t *testing.T) {
+	steps, squashed := diff.Squash([]int{3}, nil)
+	if steps != nil || squashed != nil {
+		t.Error("expected nothing for a single version, got", steps, squashed)
+	}
+}
+
+func TestCompose(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a := randomInts(r, r.Intn(12), 4)
+		b := randomInts(r, r.Intn(12), 4)
+		c := randomInts(r, r.Intn(12), 4)
+		ac := diff.Compose(diff.Ints(a, b), diff.Ints(b, c))
+		if err := diff.Check(a, c, ac); err != nil {
+			t.Fatal(a, b, c, "composed to invalid", ac, err)
+		}
+	}
+	// composing with no changes keeps the other side
+	ab := []diff.Change{{A: 1, B: 1, Del: 2, Ins: 1}}
+	if res := diff.Compose(ab, nil); !diffsEqual(res, ab) {
+		t.Error("expected", ab, "got", res)
+	}
+	if res := diff.Compose(nil, ab); !diffsEqual(res, ab) {
+		t.Error("expected", ab, "got", res)
+	}
+	// an insertion that is deleted again vanishes
+	if res := diff.Compose([]diff.Change{{A: 2, B: 2, Ins: 3}}, []diff.Change{{A: 2, B: 2, Del: 3}}); len(res) != 0 {
+		t.Error("expected no changes, got", res)
+	}
+}
+
+// patch applies changes to a taking inserted elements from src at the positions of from.
+func patch(a, src []int, changes, from []diff.Change) []int {
+	var res []int
+	x := 0
+	for i, c := range changes {
+		res = append(res, a[x:c.A]...)
+		res = append(res, src[from[i].B:from[i].B+c.Ins]...)
+		x = c.A + c.Del
+	}
+	return append(res, a[x:]...)
+}
+
+func TestCommute(t *testing.T) {
+	a := []int{1, 2, 3, 4, 5}
+	b := []int{1, 3, 4, 5, 6}
+	c := []int{1, 3, 7, 4, 5, 6}
+	bc2, ab2, ok := diff.Commute(diff.Ints(a, b), diff.Ints(b, c))
+	if !ok {
+		t.Fatal("expected patches to commute")
+	}
+	if expect := []diff.Change{{A: 3, B: 3, Del: 0, Ins: 1}}; !diffsEqual(bc2, expect) {
+		t.Error("expected", expect, "got", bc2)
+	}
+	if expect := []diff.Change{{A: 1, B: 1, Del: 1, Ins: 0}, {A: 6, B: 5, Del: 0, Ins: 1}}; !diffsEqual(ab2, expect) {
+		t.Error("expected", expect, "got", ab2)
+	}
+
+	// 6 is inserted by the first patch and deleted by the second
+	if _, _, ok := diff.Commute(diff.Ints(a, b), diff.Ints(b, a)); ok {
+		t.Error("expected conflicting patches not to commute")
+	}
+}
+
+func TestCommuteRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	commuted := 0
+	for i := 0; i < 2000; i++ {
+		a := randomInts(r, r.Intn(10), 5)
+		b := randomInts(r, r.Intn(10), 5)
+		c := randomInts(r, r.Intn(10), 5)
+		ab, bc := diff.Ints(a, b), diff.Ints(b, c)
+		bc2, ab2, ok := diff.Commute(ab, bc)
+		if !ok {
+			continue
+		}
+		commuted++
+		b2 := patch(a, c, bc2, bc)
+		if err := diff.Check(a, b2, bc2); err != nil {
+			t.Fatal(a, b, c, "invalid first patch", bc2, err)
+		}
+		if err := diff.Check(b2, c, ab2); err != nil {
+			t.Fatal(a, b, c, "invalid second patch", ab2, err)
+		}
+		// the second patch inserts what the original first patch inserted
+		for k, p := range ab2 {
+			for x := 0; x < p.Ins; x++ {
+				if c[p.B+x] != b[ab[k].B+x] {
+					t.Fatal(a, b, c, "second patch inserts", c[p.B:p.B+p.Ins])
+				}
+			}
+		}
+	}
+	if commuted == 0 {
+		t.Error("no random patches commuted")
+	}
+}
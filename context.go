@@ -0,0 +1,70 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"io"
+	"strings"
+)
+
+// WriteContext writes the changes of lines a to b in the context format of
+// diff -c, with context common lines around the changes. nameA and nameB
+// are written to the header lines and may contain a tab separated time stamp.
+// Changed lines are marked with "! ", deleted lines with "- " and inserted
+// lines with "+ ". Nothing is written if there are no changes.
+func WriteContext(w io.Writer, nameA, nameB string, a, b []string, changes []Change, context int) error {
+	hunks := Hunks(len(a), len(b), changes, context)
+	if len(hunks) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("*** " + nameA + "\n")
+	sb.WriteString("--- " + nameB + "\n")
+	for _, h := range hunks {
+		sb.WriteString("***************\n")
+		sb.WriteString("*** " + lineRange(h.A, h.LenA) + " ****\n")
+		writeContextSection(&sb, a, h.A, h.A+h.LenA, h.Changes, false)
+		sb.WriteString("--- " + lineRange(h.B, h.LenB) + " ----\n")
+		writeContextSection(&sb, b, h.B, h.B+h.LenB, h.Changes, true)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeContextSection writes lines[start:end] of a hunk of either a or b.
+// The section is left out if it contains no deleted or inserted lines.
+func writeContextSection(sb *strings.Builder, lines []string, start, end int, changes []Change, inserts bool) {
+	pos := func(c Change) (int, int) {
+		if inserts {
+			return c.B, c.Ins
+		}
+		return c.A, c.Del
+	}
+	empty := true
+	for _, c := range changes {
+		if _, n := pos(c); n > 0 {
+			empty = false
+		}
+	}
+	if empty {
+		return
+	}
+	mark := "- "
+	if inserts {
+		mark = "+ "
+	}
+	x := start
+	for _, c := range changes {
+		p, n := pos(c)
+		writeLines(sb, "  ", lines[x:p])
+		if c.Del > 0 && c.Ins > 0 {
+			writeLines(sb, "! ", lines[p:p+n])
+		} else {
+			writeLines(sb, mark, lines[p:p+n])
+		}
+		x = p + n
+	}
+	writeLines(sb, "  ", lines[x:end])
+}
@@ -0,0 +1,90 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestHunks(t *testing.T) {
+	changes := []diff.Change{
+		{A: 0, B: 0, Del: 0, Ins: 1},
+		{A: 1, B: 2, Del: 1, Ins: 1},
+		{A: 3, B: 4, Del: 1, Ins: 0},
+		{A: 13, B: 13, Del: 0, Ins: 1},
+	}
+	expect := []diff.Hunk{
+		{A: 0, B: 0, LenA: 7, LenB: 7, Changes: changes[:3]},
+		{A: 10, B: 10, LenA: 3, LenB: 4, Changes: changes[3:]},
+	}
+	if res := diff.Hunks(13, 14, changes, 3); !reflect.DeepEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	// with enough context all changes share a hunk
+	if res := diff.Hunks(13, 14, changes, 5); len(res) != 1 || res[0].LenA != 13 || res[0].LenB != 14 {
+		t.Error("expected one hunk, got", res)
+	}
+	if res := diff.Hunks(0, 0, nil, 3); len(res) != 0 {
+		t.Error("expected no hunks, got", res)
+	}
+}
+
+func TestWriteContext(t *testing.T) {
+	a := splitLines("a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n")
+	b := append(splitLines("x\na\nB\nc\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"), "n")
+	var sb strings.Builder
+	if err := diff.WriteContext(&sb, "a.txt", "b.txt", a, b, diff.Lines(a, b), 3); err != nil {
+		t.Fatal(err)
+	}
+	// the same as GNU diff -c
+	expect := "" +
+		"*** a.txt\n" +
+		"--- b.txt\n" +
+		"***************\n" +
+		"*** 1,7 ****\n" +
+		"  a\n" +
+		"! b\n" +
+		"  c\n" +
+		"- d\n" +
+		"  e\n" +
+		"  f\n" +
+		"  g\n" +
+		"--- 1,7 ----\n" +
+		"+ x\n" +
+		"  a\n" +
+		"! B\n" +
+		"  c\n" +
+		"  e\n" +
+		"  f\n" +
+		"  g\n" +
+		"***************\n" +
+		"*** 11,13 ****\n" +
+		"--- 11,14 ----\n" +
+		"  k\n" +
+		"  l\n" +
+		"  m\n" +
+		"+ n\n" +
+		"\\ No newline at end of file\n"
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+
+	sb.Reset()
+	if err := diff.WriteContext(&sb, "a", "b", a, a, nil, 3); err != nil || sb.Len() != 0 {
+		t.Error("expected no output, got", sb.String(), err)
+	}
+	empty := []string{}
+	if err := diff.WriteContext(&sb, "e", "a", empty, a[:2], diff.Lines(empty, a[:2]), 3); err != nil {
+		t.Fatal(err)
+	}
+	expect = "*** e\n--- a\n***************\n*** 0 ****\n--- 1,2 ----\n+ a\n+ b\n"
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+}
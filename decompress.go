@@ -0,0 +1,71 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"io"
+)
+
+// ErrZstd is returned for input compressed with zstd. The standard library
+// has no zstd decoder, so zstd is not supported; it is only detected to fail
+// clearly instead of diffing the compressed bytes.
+var ErrZstd = errors.New("diff: zstd compressed input is not supported")
+
+// WithDecompress makes Files decompress files compressed with gzip or
+// bzip2 before diffing them, and set FileDiff.DecompressedA and
+// DecompressedB. The compression is detected by the magic bytes at the
+// start of a file, other files are diffed as they are. Files compressed
+// with zstd fail with ErrZstd.
+func WithDecompress() Option {
+	return func(o *options) { o.decompress = true }
+}
+
+// Decompress returns a reader of the decompressed content of r if it is
+// compressed with gzip or bzip2, detected by its magic bytes, and a reader
+// of r as it is otherwise. Input compressed with zstd fails with ErrZstd.
+func Decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(10)
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return gzip.NewReader(br)
+	case isBzip2(magic):
+		return bzip2.NewReader(br), nil
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return nil, ErrZstd
+	}
+	return br, nil
+}
+
+// isBzip2 reports whether magic is the start of a bzip2 stream: "BZh",
+// the block size from 1 to 9 and the magic of the first block, or of the
+// end of the stream if it is empty. Text starting with "BZh" is not.
+func isBzip2(magic []byte) bool {
+	if len(magic) < 10 || string(magic[:3]) != "BZh" || magic[3] < '1' || magic[3] > '9' {
+		return false
+	}
+	block := magic[4:]
+	return bytes.Equal(block, []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}) ||
+		bytes.Equal(block, []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90})
+}
+
+// decompress returns the decompressed content of data, or data itself
+// if it is not compressed, and whether it was.
+func decompress(data []byte) ([]byte, bool, error) {
+	r, err := Decompress(bytes.NewReader(data))
+	if err != nil {
+		return nil, false, err
+	}
+	if _, ok := r.(*bufio.Reader); ok {
+		return data, false, nil
+	}
+	data, err = io.ReadAll(r)
+	return data, true, err
+}
@@ -0,0 +1,72 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func gzipped(t *testing.T, s string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(s))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+// bzipped is "one\ntwo\n" compressed with bzip2.
+const bzipped = "\x42\x5a\x68\x39\x31\x41\x59\x26\x53\x59\xa7\x14\x2b\x77\x00\x00\x02\xc1\x80\x00\x10\x02\x01\x84\x80\x20\x00\x21\x80\x0c\x02\x38\xf5\x1b\x8b\xb9\x22\x9c\x28\x48\x53\x8a\x15\xbb\x80"
+
+func TestDecompress(t *testing.T) {
+	for _, s := range []string{gzipped(t, "one\ntwo\n"), bzipped, "one\ntwo\n"} {
+		r, err := diff.Decompress(strings.NewReader(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, err := io.ReadAll(r); err != nil || string(data) != "one\ntwo\n" {
+			t.Errorf("expected %q, got %q %v", "one\ntwo\n", data, err)
+		}
+	}
+	if _, err := diff.Decompress(strings.NewReader("\x28\xb5\x2f\xfd...")); !errors.Is(err, diff.ErrZstd) {
+		t.Error("expected ErrZstd, got", err)
+	}
+	// text that only starts like bzip2
+	r, err := diff.Decompress(strings.NewReader("BZh9 is not compressed\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := io.ReadAll(r); err != nil || string(data) != "BZh9 is not compressed\n" {
+		t.Errorf("expected the text as it is, got %q %v", data, err)
+	}
+}
+
+func TestFilesDecompress(t *testing.T) {
+	a := writeFile(t, "a.gz", gzipped(t, "one\ntwo\nthree\n"))
+	b := writeFile(t, "b", "one\n2\nthree\n")
+	res, err := diff.Files(a, b, diff.WithDecompress())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []diff.Change{{A: 1, B: 1, Del: 1, Ins: 1}}
+	if res.Binary || !diffsEqual(res.Changes, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	if !res.DecompressedA || res.DecompressedB {
+		t.Error("expected only a to be decompressed, got", res.DecompressedA, res.DecompressedB)
+	}
+	if res, err := diff.Files(a, b); err != nil || !res.Binary || res.DecompressedA {
+		t.Error("expected the compressed file to be binary without the option, got", res, err)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ToDelta encodes the changes from a to b in a compact delta format modeled
+// on that of diff-match-patch, a tab separated list of operations: "=n" keeps
+// n bytes, "-n" deletes n bytes and "+text" inserts the text escaped with
+// url.PathEscape. The deltas are not compatible with diff-match-patch, which
+// counts UTF-16 code units and escapes like encodeURI.
+// Only b's inserted text is included, so the delta is usually much smaller
+// than b. The changes must be byte offsets ordered by ascending positions,
+// as returned by ByteStrings or Words.
+func ToDelta(a, b string, changes []Change) string {
+	var ops []string
+	x := 0
+	for _, c := range changes {
+		if c.A > x {
+			ops = append(ops, "="+strconv.Itoa(c.A-x))
+		}
+		if c.Del > 0 {
+			ops = append(ops, "-"+strconv.Itoa(c.Del))
+		}
+		if c.Ins > 0 {
+			ops = append(ops, "+"+url.PathEscape(b[c.B:c.B+c.Ins]))
+		}
+		x = c.A + c.Del
+	}
+	if x < len(a) {
+		ops = append(ops, "="+strconv.Itoa(len(a)-x))
+	}
+	return strings.Join(ops, "\t")
+}
+
+// FromDelta reconstructs b from a and a delta returned by ToDelta.
+// It also returns the changes from a to b in bytes.
+func FromDelta(a, delta string) (string, []Change, error) {
+	var b strings.Builder
+	var changes []Change
+	x := 0
+	for _, op := range strings.Split(delta, "\t") {
+		if op == "" {
+			continue
+		}
+		if op[0] == '+' {
+			text, err := url.PathUnescape(op[1:])
+			if err != nil {
+				return "", nil, fmt.Errorf("diff: invalid delta insert %q: %v", op, err)
+			}
+			changes = appendChange(changes, Change{x, b.Len(), 0, len(text)})
+			b.WriteString(text)
+			continue
+		}
+		n, err := strconv.Atoi(op[1:])
+		if err != nil || n < 0 {
+			return "", nil, fmt.Errorf("diff: invalid delta count %q", op)
+		}
+		if x+n > len(a) {
+			return "", nil, fmt.Errorf("diff: delta %q exceeds the source length %d", op, len(a))
+		}
+		switch op[0] {
+		case '=':
+			b.WriteString(a[x : x+n])
+		case '-':
+			changes = appendChange(changes, Change{x, b.Len(), n, 0})
+		default:
+			return "", nil, fmt.Errorf("diff: invalid delta operation %q", op)
+		}
+		x += n
+	}
+	if x != len(a) {
+		return "", nil, fmt.Errorf("diff: delta covers %d bytes of a source of length %d", x, len(a))
+	}
+	return b.String(), changes, nil
+}
+
+// appendChange appends c to changes, merging it with the last change if they touch.
+func appendChange(changes []Change, c Change) []Change {
+	if c.Del == 0 && c.Ins == 0 {
+		return changes
+	}
+	if n := len(changes); n > 0 {
+		last := &changes[n-1]
+		if last.A+last.Del == c.A && last.B+last.Ins == c.B {
+			last.Del += c.Del
+			last.Ins += c.Ins
+			return changes
+		}
+	}
+	return append(changes, c)
+}
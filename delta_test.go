@@ -0,0 +1,49 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestDelta(t *testing.T) {
+	for _, test := range []struct {
+		a, b, delta string
+	}{
+		{"", "", ""},
+		{"abc", "abc", "=3"},
+		{"", "abc", "+abc"},
+		{"abc", "", "-3"},
+		{"jumps over the lazy", "jumped over a lazy", "=4\t-1\t+ed\t=6\t-3\t+a\t=5"},
+		{"tab\there", "tab\tthere 100%", "=4\t+t\t=4\t+%20100%25"},
+	} {
+		changes := diff.ByteStrings(test.a, test.b)
+		delta := diff.ToDelta(test.a, test.b, changes)
+		if delta != test.delta {
+			t.Errorf("ToDelta(%q, %q) = %q, expected %q", test.a, test.b, delta, test.delta)
+		}
+		b, res, err := diff.FromDelta(test.a, delta)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if b != test.b {
+			t.Errorf("FromDelta(%q, %q) = %q, expected %q", test.a, delta, b, test.b)
+		}
+		if !diffsEqual(res, changes) {
+			t.Error("expected", changes, "got", res)
+		}
+	}
+}
+
+func TestFromDeltaInvalid(t *testing.T) {
+	for _, delta := range []string{"=4", "-1\t=1", "=x", "=-1", "*1", "+%zz", "=1"} {
+		if _, _, err := diff.FromDelta("abc", delta); err == nil {
+			t.Errorf("expected an error for %q", delta)
+		}
+	}
+}
@@ -6,8 +6,11 @@
 // The algorithm is described in "An O(ND) Difference Algorithm and its Variations", Eugene Myers, Algorithmica Vol. 1 No. 2, 1986, pp. 251-266.
 package diff
 
+import "math"
+
 // A type that satisfies diff.Data can be diffed by this package.
 // It typically has two sequences A and B of comparable elements.
+// It may also implement Hasher to speed up expensive comparisons.
 type Data interface {
 	// Equal returns whether the elements at i and j are considered equal.
 	Equal(i, j int) bool
@@ -15,21 +18,29 @@ type Data interface {
 
 // ByteStrings returns the differences of two strings in bytes.
 func ByteStrings(a, b string) []Change {
-	return Diff(len(a), len(b), &strings{a, b})
+	p := commonPrefixString(a, b)
+	a, b = a[p:], b[p:]
+	s := commonSuffixString(a, b)
+	a, b = a[:len(a)-s], b[:len(b)-s]
+	return shiftChanges(Diff(len(a), len(b), &byteStrings{a, b}), p)
 }
 
-type strings struct{ a, b string }
+type byteStrings struct{ a, b string }
 
-func (d *strings) Equal(i, j int) bool { return d.a[i] == d.b[j] }
+func (d *byteStrings) Equal(i, j int) bool { return d.a[i] == d.b[j] }
 
 // Bytes returns the difference of two byte slices
 func Bytes(a, b []byte) []Change {
-	return Diff(len(a), len(b), &bytes{a, b})
+	p := CommonPrefixBytes(a, b)
+	a, b = a[p:], b[p:]
+	s := CommonSuffixBytes(a, b)
+	a, b = a[:len(a)-s], b[:len(b)-s]
+	return shiftChanges(Diff(len(a), len(b), &byteSlices{a, b}), p)
 }
 
-type bytes struct{ a, b []byte }
+type byteSlices struct{ a, b []byte }
 
-func (d *bytes) Equal(i, j int) bool { return d.a[i] == d.b[j] }
+func (d *byteSlices) Equal(i, j int) bool { return d.a[i] == d.b[j] }
 
 // Ints returns the difference of two int slices
 func Ints(a, b []int) []Change {
@@ -76,32 +87,165 @@ func Granular(granularity int, changes []Change) []Change {
 
 // Diff returns the differences of data.
 // data.Equal is called repeatedly with 0<=i<n and 0<=j<m
-func Diff(n, m int, data Data) []Change {
-	c := &context{data: data}
-	if n > m {
-		c.flags = make([]byte, n)
-	} else {
-		c.flags = make([]byte, m)
-	}
-	c.max = n + m + 1
-	c.compare(0, 0, n, m)
-	return c.result(n, m)
+func Diff(n, m int, data Data, opts ...Option) []Change {
+	d := acquire(opts)
+	res := d.Diff(n, m, data)
+	pool.Put(d)
+	return res
+}
+
+// AppendChanges appends the differences of data to dst like Diff
+// and returns the extended slice.
+func AppendChanges(dst []Change, n, m int, data Data, opts ...Option) []Change {
+	d := acquire(opts)
+	dst = d.Append(dst, n, m, data)
+	pool.Put(d)
+	return dst
+}
+
+// Each calls fn with the differences of data in ascending order until fn
+// returns false. Unlike Diff it does not build a slice of changes, which
+// helps consumers that only stream or count them.
+func Each(n, m int, data Data, fn func(Change) bool, opts ...Option) {
+	d := acquire(opts)
+	d.Each(n, m, data, fn)
+	pool.Put(d)
 }
 
 // A Change contains one or more deletions or inserts
 // at one position in two sequences.
+// It is encoded in JSON as {"a":A,"b":B,"del":Del,"ins":Ins}
+// and as text like "A,B-Del+Ins".
 type Change struct {
-	A, B int // position in input a and b
-	Del  int // delete Del elements from input a
-	Ins  int // insert Ins elements from input b
+	A   int `json:"a"`   // position in input a
+	B   int `json:"b"`   // position in input b
+	Del int `json:"del"` // delete Del elements from input a
+	Ins int `json:"ins"` // insert Ins elements from input b
 }
 
 type context struct {
+	options
 	data  Data
 	flags []byte // element bits 1 delete, 2 insert
 	max   int
+	// cost at which the search for a middle snake gives up, 0 for never
+	tooExpensive int
+	// done is closed when the search is canceled, err is the reason
+	done <-chan struct{}
+	err  error
+	// hashes of the elements if data is a Hasher
+	hashed hashed
+	// counts the calls of Equal for an observer
+	counted counted
+	// the largest number of edits a search reached
+	maxD int
 	// forward and reverse d-path endpoint x components
-	forward, reverse []int
+	v32 vectors[int32]
+	v64 vectors[int]
+}
+
+// An index is the type of the positions in the d-path arrays.
+type index interface{ ~int32 | ~int }
+
+// vectors holds the x components of the forward and reverse d-paths.
+type vectors[I index] struct {
+	forward, reverse []I
+}
+
+// grow makes sure that both arrays have length n.
+func (v *vectors[I]) grow(n int) {
+	if cap(v.forward) < n {
+		v.forward = make([]I, n)
+		v.reverse = make([]I, n)
+	}
+	v.forward = v.forward[:n]
+	v.reverse = v.reverse[:n]
+}
+
+// reset prepares c to diff data with lengths n and m, reusing its buffers.
+func (c *context) reset(n, m int, data Data) {
+	c.data = data
+	if h, ok := data.(Hasher); ok {
+		c.hashed.reset(n, m, data, h)
+		c.data = &c.hashed
+	}
+	if c.observer != nil {
+		c.counted = counted{data: c.data}
+		c.data = &c.counted
+	}
+	c.maxD = 0
+	size := n
+	if m > n {
+		size = m
+	}
+	if cap(c.flags) < size {
+		c.flags = make([]byte, size)
+	} else {
+		c.flags = c.flags[:size]
+		for i := range c.flags {
+			c.flags[i] = 0
+		}
+	}
+	c.max = n + m + 1
+	c.tooExpensive = 0
+	if c.heuristic {
+		c.tooExpensive = tooExpensive(n, m)
+	}
+	if d := c.maxDistance; d > 0 && (c.tooExpensive == 0 || d < c.tooExpensive) {
+		c.tooExpensive = d
+	}
+	c.done, c.err = nil, nil
+	if c.ctx != nil {
+		c.done = c.ctx.Done()
+	}
+}
+
+// release drops the references to the data after a diff.
+func (c *context) release() {
+	c.data, c.hashed.data, c.counted.data = nil, nil, nil
+}
+
+// canceled reports whether the context of the options is done
+// and records its error.
+func (c *context) canceled() bool {
+	if c.done == nil {
+		return false
+	}
+	select {
+	case <-c.done:
+		c.err = c.ctx.Err()
+		return true
+	default:
+		return false
+	}
+}
+
+// tooExpensive returns the cost limit GNU diff uses for inputs of length n and m,
+// roughly the square root of n+m but at least 4096.
+func tooExpensive(n, m int) int {
+	cost := 1
+	for diags := n + m + 3; diags != 0; diags >>= 2 {
+		cost <<= 1
+	}
+	if cost < 4096 {
+		cost = 4096
+	}
+	return cost
+}
+
+// diff marks the differences of the data with the selected algorithm.
+func (c *context) diff(n, m int) {
+	if c.tieBreak != 0 {
+		c.ordered(n, m)
+		return
+	}
+	if c.algorithm == Wu {
+		c.wu(n, m)
+		return
+	}
+	// compare only strips the common ends if there is not enough memory
+	c.exceeds(c.baseMemory(n, m) + myersMemory(n, m))
+	c.compare(0, 0, n, m)
 }
 
 func (c *context) compare(aoffset, boffset, alimit, blimit int) {
@@ -131,12 +275,31 @@ func (c *context) compare(aoffset, boffset, alimit, blimit int) {
 		}
 		return
 	}
+	// after a cancellation everything in between is changed
+	if c.err != nil {
+		for ; aoffset < alimit; aoffset++ {
+			c.flags[aoffset] |= 1
+		}
+		for ; boffset < blimit; boffset++ {
+			c.flags[boffset] |= 2
+		}
+		return
+	}
 	x, y := c.findMiddleSnake(aoffset, boffset, alimit, blimit)
 	c.compare(aoffset, boffset, x, y)
 	c.compare(x, y, alimit, blimit)
 }
 
+// findMiddleSnake returns the middle snake of the box, keeping the d-path
+// arrays as int32 if the total length allows it to halve their memory.
 func (c *context) findMiddleSnake(aoffset, boffset, alimit, blimit int) (int, int) {
+	if 2*c.max <= math.MaxInt32 {
+		return middleSnake(c, &c.v32, aoffset, boffset, alimit, blimit)
+	}
+	return middleSnake(c, &c.v64, aoffset, boffset, alimit, blimit)
+}
+
+func middleSnake[I index](c *context, v *vectors[I], aoffset, boffset, alimit, blimit int) (int, int) {
 	// midpoints
 	fmid := aoffset - boffset
 	rmid := alimit - blimit
@@ -146,59 +309,128 @@ func (c *context) findMiddleSnake(aoffset, boffset, alimit, blimit int) (int, in
 	isodd := (rmid-fmid)&1 != 0
 	maxd := (alimit - aoffset + blimit - boffset + 2) / 2
 	// allocate when first used
-	if c.forward == nil {
-		c.forward = make([]int, 2*c.max)
-		c.reverse = make([]int, 2*c.max)
-	}
-	c.forward[c.max+1] = aoffset
-	c.reverse[c.max-1] = alimit
+	v.grow(2 * c.max)
+	v.forward[c.max+1] = I(aoffset)
+	v.reverse[c.max-1] = I(alimit)
 	var x, y int
 	for d := 0; d <= maxd; d++ {
+		if d > c.maxD {
+			c.maxD = d
+		}
+		if c.trace != nil {
+			c.trace.Steps = append(c.trace.Steps, TraceStep{AOffset: aoffset, BOffset: boffset, ALimit: alimit, BLimit: blimit, D: d})
+		}
 		// forward search
 		for k := fmid - d; k <= fmid+d; k += 2 {
-			if k == fmid-d || k != fmid+d && c.forward[foff+k+1] > c.forward[foff+k-1] {
-				x = c.forward[foff+k+1] // down
+			if k == fmid-d || k != fmid+d && v.forward[foff+k+1] > v.forward[foff+k-1] {
+				x = int(v.forward[foff+k+1]) // down
 			} else {
-				x = c.forward[foff+k-1] + 1 // right
+				x = int(v.forward[foff+k-1]) + 1 // right
 			}
 			y = x - k
 			for x < alimit && y < blimit && c.data.Equal(x, y) {
 				x++
 				y++
 			}
-			c.forward[foff+k] = x
+			v.forward[foff+k] = I(x)
+			if c.trace != nil {
+				c.trace.forward(x)
+			}
 			if isodd && k > rmid-d && k < rmid+d {
-				if c.reverse[roff+k] <= c.forward[foff+k] {
+				if v.reverse[roff+k] <= v.forward[foff+k] {
 					return x, x - k
 				}
 			}
 		}
 		// reverse search x,y correspond to u,v
 		for k := rmid - d; k <= rmid+d; k += 2 {
-			if k == rmid+d || k != rmid-d && c.reverse[roff+k-1] < c.reverse[roff+k+1] {
-				x = c.reverse[roff+k-1] // up
+			if k == rmid+d || k != rmid-d && v.reverse[roff+k-1] < v.reverse[roff+k+1] {
+				x = int(v.reverse[roff+k-1]) // up
 			} else {
-				x = c.reverse[roff+k+1] - 1 // left
+				x = int(v.reverse[roff+k+1]) - 1 // left
 			}
 			y = x - k
 			for x > aoffset && y > boffset && c.data.Equal(x-1, y-1) {
 				x--
 				y--
 			}
-			c.reverse[roff+k] = x
+			v.reverse[roff+k] = I(x)
+			if c.trace != nil {
+				c.trace.reverse(x)
+			}
 			if !isodd && k >= fmid-d && k <= fmid+d {
-				if c.reverse[roff+k] <= c.forward[foff+k] {
+				if v.reverse[roff+k] <= v.forward[foff+k] {
 					// lookup opposite end
-					x = c.forward[foff+k]
+					x = int(v.forward[foff+k])
 					return x, x - k
 				}
 			}
 		}
+		if c.tooExpensive > 0 && d >= c.tooExpensive || c.canceled() {
+			return furthest(c, v, aoffset, boffset, alimit, blimit, d)
+		}
 	}
 	panic("should never be reached")
 }
 
-func (c *context) result(n, m int) (res []Change) {
+// furthest returns the end of the forward or reverse d-path that got furthest,
+// to be used as a split point when finding the middle snake is too expensive.
+func furthest[I index](c *context, v *vectors[I], aoffset, boffset, alimit, blimit, d int) (int, int) {
+	fmid := aoffset - boffset
+	rmid := alimit - blimit
+	foff := c.max - fmid
+	roff := c.max - rmid
+	// only diagonals that cross the current box
+	kmin, kmax := aoffset-blimit, alimit-boffset
+	fbest, fx := -1, 0
+	for k := fmid - d; k <= fmid+d; k += 2 {
+		if k < kmin || k > kmax {
+			continue
+		}
+		x := int(v.forward[foff+k])
+		if x > alimit {
+			x = alimit
+		}
+		if x-k > blimit {
+			x = blimit + k
+		}
+		if s := x + x - k; s > fbest {
+			fbest, fx = s, x
+		}
+	}
+	rbest, rx := alimit+blimit+1, 0
+	for k := rmid - d; k <= rmid+d; k += 2 {
+		if k < kmin || k > kmax {
+			continue
+		}
+		x := int(v.reverse[roff+k])
+		if x < aoffset {
+			x = aoffset
+		}
+		if x-k < boffset {
+			x = boffset + k
+		}
+		if s := x + x - k; s < rbest {
+			rbest, rx = s, x
+		}
+	}
+	if alimit+blimit-rbest < fbest-aoffset-boffset {
+		return fx, fbest - fx
+	}
+	return rx, rbest - rx
+}
+
+// appendResult appends the changes to dst in ascending order.
+func (c *context) appendResult(dst []Change, n, m int) []Change {
+	c.each(n, m, func(ch Change) bool {
+		dst = append(dst, ch)
+		return true
+	})
+	return dst
+}
+
+// each calls fn with the changes in ascending order until fn returns false.
+func (c *context) each(n, m int, fn func(Change) bool) {
 	var x, y int
 	for x < n || y < m {
 		if x < n && y < m && c.flags[x]&1 == 0 && c.flags[y]&2 == 0 {
@@ -214,9 +446,10 @@ func (c *context) result(n, m int) (res []Change) {
 				y++
 			}
 			if a < x || b < y {
-				res = append(res, Change{a, b, x - a, y - b})
+				if !fn(Change{a, b, x - a, y - b}) {
+					return
+				}
 			}
 		}
 	}
-	return
 }
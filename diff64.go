@@ -0,0 +1,52 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "errors"
+
+// ErrTooLarge is returned if the inputs are too long to be diffed
+// on this platform.
+var ErrTooLarge = errors.New("diff: input too large")
+
+// Data64 is like Data for sequences with 64-bit positions.
+type Data64 interface {
+	// Equal returns whether the elements at i and j are considered equal.
+	Equal(i, j int64) bool
+}
+
+// A Change64 is like Change with 64-bit positions.
+type Change64 struct {
+	A   int64 `json:"a"`   // position in input a
+	B   int64 `json:"b"`   // position in input b
+	Del int64 `json:"del"` // delete Del elements from input a
+	Ins int64 `json:"ins"` // insert Ins elements from input b
+}
+
+// maxLen is the largest total length of two inputs. The algorithm keeps
+// two arrays of 2*(n+m+1) positions, which must be addressable by int.
+const maxLen = int64(int(^uint(0)>>1)/2 - 1)
+
+// Diff64 returns the differences of data like Diff for sequences with
+// 64-bit lengths n and m. It returns ErrTooLarge instead of overflowing
+// if the lengths can not be represented on this platform, for example
+// with a 32-bit int.
+func Diff64(n, m int64, data Data64, opts ...Option) ([]Change64, error) {
+	if n < 0 || m < 0 {
+		return nil, errors.New("diff: negative length")
+	}
+	if n > maxLen || m > maxLen-n {
+		return nil, ErrTooLarge
+	}
+	var res []Change64
+	Each(int(n), int(m), data64{data}, func(c Change) bool {
+		res = append(res, Change64{int64(c.A), int64(c.B), int64(c.Del), int64(c.Ins)})
+		return true
+	}, opts...)
+	return res, nil
+}
+
+type data64 struct{ Data64 }
+
+func (d data64) Equal(i, j int) bool { return d.Data64.Equal(int64(i), int64(j)) }
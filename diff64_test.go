@@ -0,0 +1,50 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+type ints64 struct{ a, b []int }
+
+func (d *ints64) Equal(i, j int64) bool { return d.a[i] == d.b[j] }
+
+func TestDiff64(t *testing.T) {
+	for _, test := range tests {
+		res, err := diff.Diff64(int64(len(test.a)), int64(len(test.b)), &ints64{test.a, test.b})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect := diff.Ints(test.a, test.b)
+		if len(res) != len(expect) {
+			t.Fatal(test.name, "expected", expect, "got", res)
+		}
+		for i, c := range expect {
+			if r := res[i]; r.A != int64(c.A) || r.B != int64(c.B) || r.Del != int64(c.Del) || r.Ins != int64(c.Ins) {
+				t.Error(test.name, "expected", c, "got", r)
+			}
+		}
+	}
+}
+
+func TestDiff64TooLarge(t *testing.T) {
+	data := &ints64{}
+	for _, l := range [][2]int64{
+		{math.MaxInt64, 1},
+		{math.MaxInt64 / 2, math.MaxInt64 / 2},
+		{1, math.MaxInt64},
+	} {
+		if _, err := diff.Diff64(l[0], l[1], data); err != diff.ErrTooLarge {
+			t.Error("expected ErrTooLarge for", l, "got", err)
+		}
+	}
+	if _, err := diff.Diff64(-1, 0, data); err == nil {
+		t.Error("expected an error for a negative length")
+	}
+}
@@ -105,16 +105,8 @@ func TestDiffBA(t *testing.T) {
 	}
 	for _, test := range tests {
 		res := diff.Ints(test.b, test.a)
-		if len(res) != len(test.res) {
-			t.Error(test.name, "expected length", len(test.res), "for", res)
-			continue
-		}
-		for i, c := range test.res {
-			// flip change data also
-			rc := diff.Change{A: c.B, B: c.A, Del: c.Ins, Ins: c.Del}
-			if rc != res[i] {
-				t.Error(test.name, "expected ", rc, "got", res[i])
-			}
+		if expect := diff.Invert(test.res); !diffsEqual(res, expect) {
+			t.Error(test.name, "expected", expect, "got", res)
 		}
 	}
 }
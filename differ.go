@@ -0,0 +1,179 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// A Differ computes differences like Diff, but keeps its internal buffers
+// between calls so that diffing many inputs allocates little more than the
+// results. A Differ must not be used concurrently.
+type Differ struct {
+	opts      options
+	c         context
+	truncated bool
+}
+
+// pool holds the Differs used by Diff.
+var pool = sync.Pool{New: func() interface{} { return new(Differ) }}
+
+// acquire returns a Differ from the pool configured with opts.
+// It must be returned with pool.Put.
+func acquire(opts []Option) *Differ {
+	d := pool.Get().(*Differ)
+	d.opts = options{}
+	for _, opt := range opts {
+		opt(&d.opts)
+	}
+	return d
+}
+
+// New returns a Differ configured with opts.
+func New(opts ...Option) *Differ {
+	d := new(Differ)
+	for _, opt := range opts {
+		opt(&d.opts)
+	}
+	return d
+}
+
+// Diff returns the differences of data.
+// data.Equal is called repeatedly with 0<=i<n and 0<=j<m
+func (d *Differ) Diff(n, m int, data Data) []Change {
+	return d.Append(nil, n, m, data)
+}
+
+// Append appends the differences of data to dst and returns the extended
+// slice. With a large enough dst and buffers, see Grow, it does not
+// allocate unless data is a Hasher or a cache is used.
+func (d *Differ) Append(dst []Change, n, m int, data Data) []Change {
+	c := &d.c
+	var t time.Time
+	if d.opts.observer != nil {
+		t = time.Now()
+	}
+	var key CacheKey
+	cached := false
+	if d.opts.cache != nil {
+		key, cached = cacheKey(n, m, data)
+	}
+	start := len(dst)
+	if cached {
+		if res, ok := d.opts.cache.Get(key); ok {
+			c.err = nil
+			dst = d.truncate(append(dst, res...), start)
+			d.phase(Collect, &t)
+			return dst
+		}
+	}
+	c.options = d.opts
+	c.reset(n, m, data)
+	d.phase(Prepare, &t)
+	c.diff(n, m)
+	d.searched(&t)
+	dst = c.appendResult(dst, n, m)
+	c.release()
+	if d.opts.slide != 0 || len(d.opts.cleanup) > 0 {
+		dst = append(dst[:start], d.finish(n, data, dst[start:])...)
+	}
+	if cached && c.err == nil {
+		d.opts.cache.Put(key, append([]Change(nil), dst[start:]...))
+	}
+	dst = d.truncate(dst, start)
+	d.phase(Collect, &t)
+	return dst
+}
+
+// truncate cuts the changes appended to dst after start to the limit of
+// WithMaxChanges and records whether any were dropped.
+func (d *Differ) truncate(dst []Change, start int) []Change {
+	max := d.opts.maxChanges
+	d.truncated = max > 0 && len(dst)-start > max
+	if d.truncated {
+		dst = dst[:start+max]
+	}
+	return dst
+}
+
+// Grow makes room in the buffers of d for diffing sequences of lengths up to
+// n and m, so that later calls do not need to allocate them.
+func (d *Differ) Grow(n, m int) {
+	c := &d.c
+	size := n
+	if m > n {
+		size = m
+	}
+	if cap(c.flags) < size {
+		c.flags = make([]byte, 0, size)
+	}
+	if v := 2 * (n + m + 1); v <= math.MaxInt32 {
+		c.v32.grow(v)
+	} else {
+		c.v64.grow(v)
+	}
+}
+
+// finish runs the post-processing passes configured by the options.
+func (d *Differ) finish(n int, data Data, res []Change) []Change {
+	if d.opts.slide != 0 {
+		res = slide(n, data, res, d.opts.slide)
+	}
+	for _, pass := range d.opts.cleanup {
+		res = pass(res)
+	}
+	return res
+}
+
+// Each calls fn with the differences of data in ascending order until fn
+// returns false. Unlike Diff it does not build a slice of changes.
+func (d *Differ) Each(n, m int, data Data, fn func(Change) bool) {
+	if d.opts.slide != 0 || len(d.opts.cleanup) > 0 || d.opts.cache != nil {
+		// the passes and the cache need all changes at once
+		for _, ch := range d.Diff(n, m, data) {
+			if !fn(ch) {
+				break
+			}
+		}
+		return
+	}
+	c := &d.c
+	var t time.Time
+	if d.opts.observer != nil {
+		t = time.Now()
+	}
+	c.options = d.opts
+	c.reset(n, m, data)
+	d.phase(Prepare, &t)
+	c.diff(n, m)
+	d.searched(&t)
+	d.truncated = false
+	count := 0
+	c.each(n, m, func(ch Change) bool {
+		if max := d.opts.maxChanges; max > 0 && count == max {
+			d.truncated = true
+			return false
+		}
+		count++
+		return fn(ch)
+	})
+	c.release()
+	d.phase(Collect, &t)
+}
+
+// Err returns the reason the last call of Diff or Each stopped searching for
+// a minimal result early, like the error of the context of WithContext or
+// a *MemoryError, or nil if the result is complete.
+func (d *Differ) Err() error {
+	return d.c.err
+}
+
+// Truncated reports whether the last call of Diff, Append or Each dropped
+// changes beyond the limit of WithMaxChanges.
+func (d *Differ) Truncated() bool {
+	return d.truncated
+}
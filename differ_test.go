@@ -0,0 +1,146 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestDifferReuse(t *testing.T) {
+	d := diff.New()
+	r := rand.New(rand.NewSource(1))
+	// alternate sizes so that buffers are both grown and reused
+	for i := 0; i < 200; i++ {
+		size := r.Intn(50)
+		if i%2 == 0 {
+			size = r.Intn(5)
+		}
+		a, b := randomInts(r, size, 4), randomInts(r, r.Intn(50), 4)
+		res := d.Diff(len(a), len(b), &ints{a, b})
+		if expect := diff.Ints(a, b); !diffsEqual(res, expect) {
+			t.Fatal(a, b, "expected", expect, "got", res)
+		}
+	}
+}
+
+func TestDifferOptions(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a, b := randomInts(r, 5000, 1000), randomInts(r, 5000, 1000)
+	data := &ints{a, b}
+	res := diff.New(diff.WithHeuristic()).Diff(len(a), len(b), data)
+	if expect := diff.Diff(len(a), len(b), data, diff.WithHeuristic()); !diffsEqual(res, expect) {
+		t.Error("Differ and Diff disagree on the same options")
+	}
+}
+
+func TestDiffConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(seed))
+			for j := 0; j < 100; j++ {
+				a, b := randomInts(r, r.Intn(30), 4), randomInts(r, r.Intn(30), 4)
+				if res := diff.Ints(a, b); diff.Check(a, b, res) != nil {
+					t.Error(a, b, "invalid result", res)
+					return
+				}
+			}
+		}(int64(i))
+	}
+	wg.Wait()
+}
+
+func BenchmarkDiffer(b *testing.B) {
+	t := tests[len(tests)-1]
+	data := &ints{t.a, t.b}
+	d := diff.New()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		d.Diff(len(t.a), len(t.b), data)
+	}
+}
+
+func TestEach(t *testing.T) {
+	for _, test := range tests {
+		expect := diff.Ints(test.a, test.b)
+		var res []diff.Change
+		diff.Each(len(test.a), len(test.b), &ints{test.a, test.b}, func(c diff.Change) bool {
+			res = append(res, c)
+			return true
+		})
+		if !diffsEqual(res, expect) {
+			t.Error(test.name, "expected", expect, "got", res)
+		}
+	}
+}
+
+func TestEachStop(t *testing.T) {
+	a := []int{1, 2, 3, 4, 5, 6}
+	b := []int{0, 2, 7, 4, 8, 6}
+	calls := 0
+	diff.New().Each(len(a), len(b), &ints{a, b}, func(c diff.Change) bool {
+		calls++
+		return calls < 2
+	})
+	if calls != 2 {
+		t.Error("expected Each to stop after 2 changes, got", calls)
+	}
+}
+
+func TestAppend(t *testing.T) {
+	prefix := []diff.Change{{A: 9, B: 9, Del: 1}}
+	for _, test := range tests {
+		expect := append(append([]diff.Change(nil), prefix...), diff.Ints(test.a, test.b)...)
+		dst := append([]diff.Change(nil), prefix...)
+		res := diff.AppendChanges(dst, len(test.a), len(test.b), &ints{test.a, test.b})
+		if !diffsEqual(res, expect) {
+			t.Error(test.name, "expected", expect, "got", res)
+		}
+	}
+	// the cleanup passes only see the appended changes
+	a, b := []int{1, 2, 3, 4}, []int{0, 2, 5, 4}
+	dst := append([]diff.Change(nil), prefix...)
+	res := diff.AppendChanges(dst, len(a), len(b), &ints{a, b}, diff.WithCleanup(func(c []diff.Change) []diff.Change {
+		return diff.Granular(1, c)
+	}))
+	if expect := []diff.Change{prefix[0], {A: 0, B: 0, Del: 3, Ins: 3}}; !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+}
+
+func TestAppendAllocs(t *testing.T) {
+	test := tests[len(tests)-1]
+	data := &ints{test.a, test.b}
+	d := diff.New()
+	d.Grow(len(test.a), len(test.b))
+	dst := make([]diff.Change, 0, 16)
+	allocs := testing.AllocsPerRun(100, func() {
+		dst = d.Append(dst[:0], len(test.a), len(test.b), data)
+	})
+	if allocs != 0 {
+		t.Error("expected no allocations, got", allocs)
+	}
+	if expect := diff.Ints(test.a, test.b); !diffsEqual(dst, expect) {
+		t.Error("expected", expect, "got", dst)
+	}
+}
+
+func BenchmarkDifferAppend(b *testing.B) {
+	t := tests[len(tests)-1]
+	data := &ints{t.a, t.b}
+	d := diff.New()
+	d.Grow(len(t.a), len(t.b))
+	dst := make([]diff.Change, 0, 16)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dst = d.Append(dst[:0], len(t.a), len(t.b), data)
+	}
+}
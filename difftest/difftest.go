@@ -0,0 +1,187 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package difftest provides test assertions that report differences
+// between the expected and actual values as a diff.
+package difftest
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+// Color enables ANSI colors in the reported diffs. It is set unless the
+// NO_COLOR environment variable is.
+var Color = os.Getenv("NO_COLOR") == ""
+
+// Context is the number of common lines shown around changes.
+var Context = 3
+
+// Equal reports an error and returns false unless want and got are deeply
+// equal. Strings are compared by line, other values by their formatted
+// structure with one field or element per line.
+func Equal(t testing.TB, want, got interface{}) bool {
+	t.Helper()
+	if reflect.DeepEqual(want, got) {
+		return true
+	}
+	var a, b string
+	ws, wok := want.(string)
+	gs, gok := got.(string)
+	if wok && gok {
+		a, b = ws, gs
+	} else {
+		a, b = Format(want), Format(got)
+	}
+	if a == b {
+		// different types or unexported details that format the same
+		t.Errorf("values differ:\nwant: %T %s\n got: %T %s", want, a, got, b)
+		return false
+	}
+	t.Errorf("values differ (-want +got):\n%s", Diff(a, b))
+	return false
+}
+
+// Diff returns the unified diff of the lines of a and b without file
+// headers, colored if Color is set.
+func Diff(a, b string) string {
+	la, lb := lines(a), lines(b)
+	var sb strings.Builder
+	p := diff.NewFilePatch("want", "got", la, lb, diff.Lines(la, lb), Context)
+	for _, h := range p.Hunks {
+		text, _ := h.MarshalText()
+		for _, line := range strings.SplitAfter(string(text), "\n") {
+			if line == "" {
+				continue
+			}
+			sb.WriteString(colorize(line))
+		}
+	}
+	return sb.String()
+}
+
+func lines(s string) []string {
+	l := strings.SplitAfter(s, "\n")
+	if l[len(l)-1] == "" {
+		l = l[:len(l)-1]
+	}
+	return l
+}
+
+func colorize(line string) string {
+	if !Color {
+		return line
+	}
+	code := ""
+	switch line[0] {
+	case '-':
+		code = "31"
+	case '+':
+		code = "32"
+	case '@':
+		code = "36"
+	default:
+		return line
+	}
+	return "\x1b[" + code + "m" + strings.TrimSuffix(line, "\n") + "\x1b[0m\n"
+}
+
+// Format formats v with one field, element or map entry per line,
+// sorting map entries by their formatted keys.
+func Format(v interface{}) string {
+	var sb strings.Builder
+	f := formatter{sb: &sb, seen: make(map[uintptr]bool)}
+	f.format(reflect.ValueOf(v), 0)
+	sb.WriteByte('\n')
+	return sb.String()
+}
+
+type formatter struct {
+	sb   *strings.Builder
+	seen map[uintptr]bool // pointers on the current path, to stop cycles
+}
+
+func (f formatter) format(v reflect.Value, depth int) {
+	if !v.IsValid() {
+		f.sb.WriteString("nil")
+		return
+	}
+	indent := strings.Repeat("\t", depth+1)
+	switch v.Kind() {
+	case reflect.String:
+		f.sb.WriteString(strconv.Quote(v.String()))
+	case reflect.Ptr:
+		if v.IsNil() {
+			f.sb.WriteString("nil")
+			return
+		}
+		if f.seen[v.Pointer()] {
+			f.sb.WriteString("<cycle>")
+			return
+		}
+		f.seen[v.Pointer()] = true
+		f.sb.WriteByte('&')
+		f.format(v.Elem(), depth)
+		delete(f.seen, v.Pointer())
+	case reflect.Interface:
+		f.format(v.Elem(), depth)
+	case reflect.Struct:
+		f.sb.WriteString(v.Type().String() + "{\n")
+		for i := 0; i < v.NumField(); i++ {
+			f.sb.WriteString(indent + v.Type().Field(i).Name + ": ")
+			f.format(v.Field(i), depth+1)
+			f.sb.WriteString(",\n")
+		}
+		f.sb.WriteString(indent[1:] + "}")
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			f.sb.WriteString(v.Type().String() + "(nil)")
+			return
+		}
+		f.sb.WriteString(v.Type().String() + "{\n")
+		for i := 0; i < v.Len(); i++ {
+			f.sb.WriteString(indent)
+			f.format(v.Index(i), depth+1)
+			f.sb.WriteString(",\n")
+		}
+		f.sb.WriteString(indent[1:] + "}")
+	case reflect.Map:
+		if v.IsNil() {
+			f.sb.WriteString(v.Type().String() + "(nil)")
+			return
+		}
+		type entry struct {
+			key string
+			val reflect.Value
+		}
+		entries := make([]entry, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			var kb strings.Builder
+			formatter{sb: &kb, seen: f.seen}.format(iter.Key(), depth+1)
+			entries = append(entries, entry{kb.String(), iter.Value()})
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+		f.sb.WriteString(v.Type().String() + "{\n")
+		for _, e := range entries {
+			f.sb.WriteString(indent + e.key + ": ")
+			f.format(e.val, depth+1)
+			f.sb.WriteString(",\n")
+		}
+		f.sb.WriteString(indent[1:] + "}")
+	default:
+		if v.CanInterface() {
+			f.sb.WriteString(fmt.Sprintf("%#v", v.Interface()))
+		} else {
+			f.sb.WriteString(fmt.Sprint(v))
+		}
+	}
+}
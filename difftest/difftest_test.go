@@ -0,0 +1,92 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package difftest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/echlebek/diff/difftest"
+)
+
+// recorder records the errors of a test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestEqualStrings(t *testing.T) {
+	difftest.Color = false
+	r := &recorder{TB: t}
+	if !difftest.Equal(r, "a\nb\n", "a\nb\n") || len(r.errors) != 0 {
+		t.Fatal("expected equal strings to pass, got", r.errors)
+	}
+	if difftest.Equal(r, "a\nb\nc\n", "a\nB\nc\n") {
+		t.Fatal("expected different strings to fail")
+	}
+	expect := "values differ (-want +got):\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"
+	if len(r.errors) != 1 || r.errors[0] != expect {
+		t.Errorf("expected %q, got %q", expect, r.errors)
+	}
+}
+
+type point struct {
+	X, Y int
+	Tags map[string]bool
+}
+
+func TestEqualValues(t *testing.T) {
+	difftest.Color = false
+	r := &recorder{TB: t}
+	want := []point{{1, 2, map[string]bool{"a": true}}, {3, 4, nil}}
+	got := []point{{1, 2, map[string]bool{"a": true}}, {3, 5, nil}}
+	if difftest.Equal(r, want, got) {
+		t.Fatal("expected different values to fail")
+	}
+	expect := "values differ (-want +got):\n" +
+		"@@ -8,7 +8,7 @@\n" +
+		" \t},\n" +
+		" \tdifftest_test.point{\n" +
+		" \t\tX: 3,\n" +
+		"-\t\tY: 4,\n" +
+		"+\t\tY: 5,\n" +
+		" \t\tTags: map[string]bool(nil),\n" +
+		" \t},\n" +
+		" }\n"
+	if len(r.errors) != 1 || r.errors[0] != expect {
+		t.Errorf("expected %q, got %q", expect, r.errors)
+	}
+
+	// values that format the same
+	r.errors = nil
+	if difftest.Equal(r, 1, int64(1)) || len(r.errors) != 1 {
+		t.Error("expected different types to fail, got", r.errors)
+	}
+}
+
+func TestFormatCycle(t *testing.T) {
+	type node struct{ Next *node }
+	n := &node{}
+	n.Next = n
+	expect := "&difftest_test.node{\n\tNext: <cycle>,\n}\n"
+	if s := difftest.Format(n); s != expect {
+		t.Errorf("expected %q, got %q", expect, s)
+	}
+}
+
+func TestDiffColor(t *testing.T) {
+	difftest.Color = true
+	defer func() { difftest.Color = false }()
+	expect := "\x1b[36m@@ -1 +1 @@\x1b[0m\n\x1b[31m-a\x1b[0m\n\x1b[32m+b\x1b[0m\n"
+	if s := difftest.Diff("a\n", "b\n"); s != expect {
+		t.Errorf("expected %q, got %q", expect, s)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// Distance returns the edit distance of data, the number of deleted and
+// inserted elements in a minimal result of Diff.
+// It runs the forward search only and skips computing the changes,
+// which makes it cheaper when only the distance is needed.
+func Distance(n, m int, data Data) int {
+	aoffset, boffset := 0, 0
+	for aoffset < n && boffset < m && data.Equal(aoffset, boffset) {
+		aoffset++
+		boffset++
+	}
+	for n > aoffset && m > boffset && data.Equal(n-1, m-1) {
+		n--
+		m--
+	}
+	n, m = n-aoffset, m-boffset
+	if n == 0 || m == 0 {
+		return n + m
+	}
+	max := n + m
+	// furthest reaching x component of each diagonal k = x-y
+	v := make([]int, 2*max+2)
+	for d := 0; ; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[max+k-1] < v[max+k+1] {
+				x = v[max+k+1] // down
+			} else {
+				x = v[max+k-1] + 1 // right
+			}
+			y := x - k
+			for x < n && y < m && data.Equal(aoffset+x, boffset+y) {
+				x++
+				y++
+			}
+			v[max+k] = x
+			if k == n-m && x >= n {
+				return d
+			}
+		}
+	}
+}
+
+// Different reports whether data has any differences, like cmp -s.
+// Sequences of different lengths always differ; otherwise it stops at the
+// first pair of elements that are not equal.
+func Different(n, m int, data Data) bool {
+	if n != m {
+		return true
+	}
+	for i := 0; i < n; i++ {
+		if !data.Equal(i, i) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestDistance(t *testing.T) {
+	for _, test := range tests {
+		expect := countEdits(diff.Ints(test.a, test.b))
+		if d := diff.Distance(len(test.a), len(test.b), &ints{test.a, test.b}); d != expect {
+			t.Error(test.name, "expected", expect, "got", d)
+		}
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a, b := randomInts(r, r.Intn(20), 3), randomInts(r, r.Intn(20), 3)
+		expect := countEdits(diff.Ints(a, b))
+		if d := diff.Distance(len(a), len(b), &ints{a, b}); d != expect {
+			t.Fatal(a, b, "expected", expect, "got", d)
+		}
+	}
+}
+
+func BenchmarkDistance(b *testing.B) {
+	d1 := []byte("lorem ipsum dolor sit amet consectetur")
+	d2 := []byte("lorem lovesum daenerys targaryen ami consecteture")
+	data := &bytesData{d1, d2}
+	for i := 0; i < b.N; i++ {
+		diff.Distance(len(d1), len(d2), data)
+	}
+}
+
+type bytesData struct{ a, b []byte }
+
+func (d *bytesData) Equal(i, j int) bool { return d.a[i] == d.b[j] }
+
+type countingData struct {
+	diff.Data
+	calls int
+}
+
+func (d *countingData) Equal(i, j int) bool {
+	d.calls++
+	return d.Data.Equal(i, j)
+}
+
+func TestDifferent(t *testing.T) {
+	for _, test := range tests {
+		expect := len(diff.Ints(test.a, test.b)) > 0
+		if res := diff.Different(len(test.a), len(test.b), &ints{test.a, test.b}); res != expect {
+			t.Error(test.name, "expected", expect, "got", res)
+		}
+	}
+	a := []int{1, 2, 3, 4, 5, 6}
+	b := []int{1, 0, 3, 4, 5, 6}
+	data := &countingData{Data: &ints{a, b}}
+	if !diff.Different(len(a), len(b), data) {
+		t.Error("expected a difference")
+	}
+	if data.calls != 2 {
+		t.Error("expected to stop after the first mismatch, compared", data.calls)
+	}
+}
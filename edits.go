@@ -0,0 +1,127 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// An Edit replaces the bytes a[Start:End] of a text a with New.
+type Edit struct {
+	Start, End int
+	New        string
+}
+
+// Edits returns the edits turning a into b, diffed by runes so that edits
+// never split a UTF-8 sequence. The edits are sorted by position and do not
+// overlap or touch, so that they can be applied to editor buffers in order.
+func Edits(a, b string) []Edit {
+	oa, ob := runeOffsets(a), runeOffsets(b)
+	changes := Diff(len(oa)-1, len(ob)-1, &encodedRunes{a, b, oa, ob})
+	if len(changes) == 0 {
+		return nil
+	}
+	edits := make([]Edit, len(changes))
+	for i, c := range changes {
+		edits[i] = Edit{
+			Start: oa[c.A],
+			End:   oa[c.A+c.Del],
+			New:   b[ob[c.B]:ob[c.B+c.Ins]],
+		}
+	}
+	return edits
+}
+
+// encodedRunes compares the runes of a and b by their encoding,
+// so that different invalid bytes are not equal.
+type encodedRunes struct {
+	a, b   string
+	oa, ob []int
+}
+
+func (d *encodedRunes) Equal(i, j int) bool {
+	return d.a[d.oa[i]:d.oa[i+1]] == d.b[d.ob[j]:d.ob[j+1]]
+}
+
+// runeOffsets returns the byte offset of every rune of s and the length of s.
+// Every invalid byte counts as one rune.
+func runeOffsets(s string) []int {
+	offsets := make([]int, 0, len(s)+1)
+	for i := range s {
+		offsets = append(offsets, i)
+	}
+	return append(offsets, len(s))
+}
+
+// ApplyEdits applies sorted, non-overlapping edits to s in one pass.
+// It also returns a function that maps byte offsets of s to offsets of the
+// result: offsets before an edit keep their place, offsets within the
+// replaced bytes move to the start of the replacement, and offsets at or
+// after the end of an edit move by the length difference of the edit.
+func ApplyEdits(s string, edits []Edit) (string, func(int) int, error) {
+	if err := checkEdits(len(s), edits); err != nil {
+		return "", nil, err
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	x := 0
+	for _, e := range edits {
+		sb.WriteString(s[x:e.Start])
+		sb.WriteString(e.New)
+		x = e.End
+	}
+	sb.WriteString(s[x:])
+	return sb.String(), offsetMap(edits), nil
+}
+
+// ApplyEditsBytes is like ApplyEdits for byte slices.
+func ApplyEditsBytes(b []byte, edits []Edit) ([]byte, func(int) int, error) {
+	if err := checkEdits(len(b), edits); err != nil {
+		return nil, nil, err
+	}
+	res := make([]byte, 0, len(b))
+	x := 0
+	for _, e := range edits {
+		res = append(res, b[x:e.Start]...)
+		res = append(res, e.New...)
+		x = e.End
+	}
+	return append(res, b[x:]...), offsetMap(edits), nil
+}
+
+// checkEdits reports an error unless the edits are sorted,
+// do not overlap and are within a text of length n.
+func checkEdits(n int, edits []Edit) error {
+	x := 0
+	for i, e := range edits {
+		switch {
+		case e.Start < x:
+			return fmt.Errorf("diff: edit %d %v overlaps the previous edit", i, e)
+		case e.End < e.Start || e.End > n:
+			return fmt.Errorf("diff: edit %d %v is out of bounds %d", i, e, n)
+		}
+		x = e.End
+	}
+	return nil
+}
+
+// offsetMap returns the offset mapping of ApplyEdits.
+func offsetMap(edits []Edit) func(int) int {
+	// the shift before every edit
+	shifts := make([]int, len(edits)+1)
+	for i, e := range edits {
+		shifts[i+1] = shifts[i] + len(e.New) - (e.End - e.Start)
+	}
+	return func(p int) int {
+		// the first edit ending after p
+		i := sort.Search(len(edits), func(i int) bool { return edits[i].End > p })
+		if i < len(edits) && edits[i].Start <= p {
+			return edits[i].Start + shifts[i]
+		}
+		return p + shifts[i]
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+// applyEdits applies sorted edits to a.
+func applyEdits(a string, edits []diff.Edit) string {
+	res, x := "", 0
+	for _, e := range edits {
+		res += a[x:e.Start] + e.New
+		x = e.End
+	}
+	return res + a[x:]
+}
+
+func TestEdits(t *testing.T) {
+	for _, test := range []struct {
+		a, b   string
+		expect []diff.Edit
+	}{
+		{"", "", nil},
+		{"same", "same", nil},
+		{"", "new", []diff.Edit{{0, 0, "new"}}},
+		{"old", "", []diff.Edit{{0, 3, ""}}},
+		{"sögen", "mögen", []diff.Edit{{0, 1, "m"}}},
+		{"brown fox", "brwn föx", []diff.Edit{{2, 3, ""}, {7, 8, "ö"}}},
+		{"a€b", "a¢b", []diff.Edit{{1, 4, "¢"}}},
+		{"a\xffb", "a\xfeb", []diff.Edit{{1, 2, "\xfe"}}},
+	} {
+		edits := diff.Edits(test.a, test.b)
+		if !reflect.DeepEqual(edits, test.expect) {
+			t.Errorf("Edits(%q, %q) = %v, expected %v", test.a, test.b, edits, test.expect)
+		}
+		if res := applyEdits(test.a, edits); res != test.b {
+			t.Errorf("applying %v to %q gives %q, expected %q", edits, test.a, res, test.b)
+		}
+		for i := 1; i < len(edits); i++ {
+			if edits[i].Start <= edits[i-1].End {
+				t.Errorf("edits %v overlap or touch", edits)
+			}
+		}
+	}
+}
+
+func TestApplyEdits(t *testing.T) {
+	a, b := "brown fox jumps", "brwn föx hops"
+	edits := diff.Edits(a, b)
+	res, pos, err := diff.ApplyEdits(a, edits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != b {
+		t.Errorf("expected %q, got %q", b, res)
+	}
+	resb, posb, err := diff.ApplyEditsBytes([]byte(a), edits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resb) != b {
+		t.Errorf("expected %q, got %q", b, resb)
+	}
+	// "fox" and "s" keep pointing at the same text
+	for _, test := range []struct{ old, new int }{
+		{0, 0}, {2, 2}, {3, 2}, {6, 5}, {7, 6}, {8, 8}, {14, 13}, {15, 14},
+	} {
+		if p := pos(test.old); p != test.new {
+			t.Errorf("pos(%d) = %d, expected %d", test.old, p, test.new)
+		}
+		if p := posb(test.old); p != test.new {
+			t.Errorf("posb(%d) = %d, expected %d", test.old, p, test.new)
+		}
+	}
+	// an offset at an insertion moves after it
+	_, pos, _ = diff.ApplyEdits("ac", []diff.Edit{{1, 1, "b"}})
+	if p := pos(1); p != 2 {
+		t.Errorf("expected 2, got %d", p)
+	}
+	for _, bad := range [][]diff.Edit{
+		{{2, 1, ""}},
+		{{0, 20, ""}},
+		{{3, 4, ""}, {1, 2, ""}},
+		{{1, 3, ""}, {2, 4, ""}},
+	} {
+		if _, _, err := diff.ApplyEdits(a, bad); err == nil {
+			t.Error("expected an error for", bad)
+		}
+	}
+}
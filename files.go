@@ -0,0 +1,219 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A FileDiff holds the differences of two files.
+type FileDiff struct {
+	// Binary is set if either file looks binary, see IsBinary.
+	// Binary files are not diffed, only their sizes and hashes are set.
+	Binary bool
+	// A and B hold the lines of text files that differ.
+	A, B    []string
+	Changes []Change
+	// SizeA and SizeB are the sizes of binary files in bytes
+	// and HashA and HashB their SHA-256 hashes.
+	SizeA, SizeB int
+	HashA, HashB [sha256.Size]byte
+	// DecompressedA and DecompressedB are set if the files were
+	// decompressed before diffing, see WithDecompress.
+	DecompressedA, DecompressedB bool
+	// PropsA and PropsB describe the files, so that files differing
+	// only in line endings or byte order marks can be told apart.
+	PropsA, PropsB Properties
+}
+
+// Properties describes the line endings and encoding of a file.
+type Properties struct {
+	// EOL is "\n" or "\r\n" if all lines end so, "mixed" if both
+	// occur and empty if the file has no line endings.
+	EOL string
+	// FinalNewline is set if the file ends in a line ending.
+	FinalNewline bool
+	// BOM is the encoding named by a leading byte order mark,
+	// "UTF-8", "UTF-16LE" or "UTF-16BE", or empty if there is none.
+	BOM string
+	// Binary is set if the file looks binary, see IsBinary.
+	Binary bool
+}
+
+// Detect returns the properties of data.
+func Detect(data []byte) Properties {
+	p := Properties{Binary: IsBinary(data)}
+	switch {
+	case bytes.HasPrefix(data, []byte("\xef\xbb\xbf")):
+		p.BOM = "UTF-8"
+	case bytes.HasPrefix(data, []byte("\xff\xfe")):
+		p.BOM = "UTF-16LE"
+	case bytes.HasPrefix(data, []byte("\xfe\xff")):
+		p.BOM = "UTF-16BE"
+	}
+	if n := bytes.Count(data, []byte("\n")); n > 0 {
+		switch crlf := bytes.Count(data, []byte("\r\n")); crlf {
+		case 0:
+			p.EOL = "\n"
+		case n:
+			p.EOL = "\r\n"
+		default:
+			p.EOL = "mixed"
+		}
+	}
+	p.FinalNewline = bytes.HasSuffix(data, []byte("\n"))
+	return p
+}
+
+// Files returns the differences of the files at pathA and pathB.
+// Text files are diffed by line, for binary files only their sizes and
+// hashes are reported, see IsBinary. Files of equal size are compared chunk
+// by chunk first, so that identical files are never read into memory
+// entirely and the returned FileDiff is empty. See WithDecompress for
+// compressed files.
+func Files(pathA, pathB string, opts ...Option) (*FileDiff, error) {
+	same, err := sameFiles(pathA, pathB)
+	if err != nil || same {
+		return &FileDiff{}, err
+	}
+	a, err := os.ReadFile(pathA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(pathB)
+	if err != nil {
+		return nil, err
+	}
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var za, zb bool
+	if o.decompress {
+		if a, za, err = decompress(a); err != nil {
+			return nil, err
+		}
+		if b, zb, err = decompress(b); err != nil {
+			return nil, err
+		}
+	}
+	d := Contents(a, b, opts...)
+	d.DecompressedA, d.DecompressedB = za, zb
+	return d, nil
+}
+
+// Contents returns the differences of the contents of two files by line.
+// If either looks binary, only their sizes and hashes are returned.
+// The properties of both are always set, see Detect.
+func Contents(a, b []byte, opts ...Option) *FileDiff {
+	pa, pb := Detect(a), Detect(b)
+	if pa.Binary || pb.Binary {
+		return &FileDiff{
+			Binary: true,
+			SizeA:  len(a), SizeB: len(b),
+			HashA: sha256.Sum256(a), HashB: sha256.Sum256(b),
+			PropsA: pa, PropsB: pb,
+		}
+	}
+	la, lb := splitLines(string(a)), splitLines(string(b))
+	return &FileDiff{
+		A: la, B: lb,
+		Changes: lineChanges(la, lb, opts...),
+		PropsA:  pa, PropsB: pb,
+	}
+}
+
+// IsBinary reports whether data looks binary, like git does by looking
+// for a NUL byte in the first 8000 bytes. Data that is not valid UTF-8
+// in these bytes looks binary too.
+func IsBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+		// don't count a rune cut in half
+		for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+			if utf8.RuneStart(data[len(data)-i]) {
+				if !utf8.FullRune(data[len(data)-i:]) {
+					data = data[:len(data)-i]
+				}
+				break
+			}
+		}
+	}
+	return bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data)
+}
+
+// WriteBinary writes that two binary files differ like diff does,
+// followed by their sizes and hashes:
+//
+//	Binary files a.png and b.png differ
+//	a.png: 1024 bytes, sha256 5891b5b5...
+//	b.png: 1031 bytes, sha256 0f8ad1c8...
+func WriteBinary(w io.Writer, nameA, nameB string, d *FileDiff) error {
+	var sb strings.Builder
+	sb.WriteString("Binary files " + nameA + " and " + nameB + " differ\n")
+	sb.WriteString(nameA + ": " + strconv.Itoa(d.SizeA) + " bytes, sha256 " + hex.EncodeToString(d.HashA[:]) + "\n")
+	sb.WriteString(nameB + ": " + strconv.Itoa(d.SizeB) + " bytes, sha256 " + hex.EncodeToString(d.HashB[:]) + "\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// sameFiles reports whether the files have the same content
+// reading them in chunks.
+func sameFiles(pathA, pathB string) (bool, error) {
+	fa, err := os.Open(pathA)
+	if err != nil {
+		return false, err
+	}
+	defer fa.Close()
+	fb, err := os.Open(pathB)
+	if err != nil {
+		return false, err
+	}
+	defer fb.Close()
+	ia, err := fa.Stat()
+	if err != nil {
+		return false, err
+	}
+	ib, err := fb.Stat()
+	if err != nil {
+		return false, err
+	}
+	if ia.Size() != ib.Size() {
+		return false, nil
+	}
+	return sameContent(fa, fb)
+}
+
+// sameContent reports whether a and b read the same bytes.
+func sameContent(a, b io.Reader) (bool, error) {
+	const chunk = 32 << 10
+	ba, bb := make([]byte, chunk), make([]byte, chunk)
+	for {
+		na, erra := io.ReadFull(a, ba)
+		nb, errb := io.ReadFull(b, bb)
+		if !bytes.Equal(ba[:na], bb[:nb]) {
+			return false, nil
+		}
+		if erra == io.EOF || erra == io.ErrUnexpectedEOF {
+			return errb == io.EOF || errb == io.ErrUnexpectedEOF, nil
+		}
+		if erra != nil {
+			return false, erra
+		}
+		if errb != nil {
+			if errb == io.EOF || errb == io.ErrUnexpectedEOF {
+				return false, nil
+			}
+			return false, errb
+		}
+	}
+}
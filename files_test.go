@@ -0,0 +1,156 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestFiles(t *testing.T) {
+	a := writeFile(t, "a", "one\ntwo\nthree\n")
+	b := writeFile(t, "b", "one\n2\nthree\nfour")
+	res, err := diff.Files(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Binary {
+		t.Error("expected text files")
+	}
+	expect := []diff.Change{{A: 1, B: 1, Del: 1, Ins: 1}, {A: 3, B: 3, Del: 0, Ins: 1}}
+	if !diffsEqual(res.Changes, expect) {
+		t.Error("expected", expect, "got", res.Changes)
+	}
+	if len(res.A) != 3 || len(res.B) != 4 || res.B[3] != "four" {
+		t.Error("unexpected lines", res.A, res.B)
+	}
+}
+
+func TestFilesIdentical(t *testing.T) {
+	content := strings.Repeat("the same line\n", 10000)
+	a := writeFile(t, "a", content)
+	b := writeFile(t, "b", content)
+	res, err := diff.Files(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Changes) != 0 || res.A != nil || res.B != nil {
+		t.Error("expected no differences, got", res)
+	}
+	// same size, different content in the last chunk
+	b = writeFile(t, "b", content[:len(content)-2]+"X\n")
+	if res, err = diff.Files(a, b); err != nil {
+		t.Fatal(err)
+	}
+	if expect := []diff.Change{{A: 9999, B: 9999, Del: 1, Ins: 1}}; !diffsEqual(res.Changes, expect) {
+		t.Error("expected", expect, "got", res.Changes)
+	}
+}
+
+func TestFilesBinary(t *testing.T) {
+	a := writeFile(t, "a", "ab\x00cd")
+	b := writeFile(t, "b", "ab\x00xd")
+	res, err := diff.Files(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Binary {
+		t.Error("expected binary files")
+	}
+	if res.Changes != nil || res.SizeA != 5 || res.SizeB != 5 || res.HashA == res.HashB {
+		t.Error("expected only sizes and hashes, got", res)
+	}
+	var sb strings.Builder
+	if err := diff.WriteBinary(&sb, "a", "b", res); err != nil {
+		t.Fatal(err)
+	}
+	expect := "Binary files a and b differ\n" +
+		"a: 5 bytes, sha256 " + fmt.Sprintf("%x", sha256.Sum256([]byte("ab\x00cd"))) + "\n" +
+		"b: 5 bytes, sha256 " + fmt.Sprintf("%x", sha256.Sum256([]byte("ab\x00xd"))) + "\n"
+	if sb.String() != expect {
+		t.Errorf("expected %q, got %q", expect, sb.String())
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	long := strings.Repeat("x", 7999) + "é" // cut in half after 8000 bytes
+	for _, test := range []struct {
+		data   string
+		expect bool
+	}{
+		{"", false},
+		{"text\n", false},
+		{"grüße\n", false},
+		{"a\x00b", true},
+		{"latin-1 \xe9t\xe9\n", true},
+		{long + "\xff", false},
+		{strings.Repeat("x", 8000) + "\x00", false},
+	} {
+		if res := diff.IsBinary([]byte(test.data)); res != test.expect {
+			t.Errorf("%.20q: expected %v, got %v", test.data, test.expect, res)
+		}
+	}
+}
+
+func TestFilesMissing(t *testing.T) {
+	a := writeFile(t, "a", "a")
+	if _, err := diff.Files(a, filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	for _, test := range []struct {
+		data   string
+		expect diff.Properties
+	}{
+		{"", diff.Properties{}},
+		{"a", diff.Properties{}},
+		{"a\nb\n", diff.Properties{EOL: "\n", FinalNewline: true}},
+		{"a\r\nb", diff.Properties{EOL: "\r\n"}},
+		{"a\r\nb\n", diff.Properties{EOL: "mixed", FinalNewline: true}},
+		{"\xef\xbb\xbfa\n", diff.Properties{EOL: "\n", FinalNewline: true, BOM: "UTF-8"}},
+		{"\xff\xfea\x00\n\x00", diff.Properties{EOL: "\n", BOM: "UTF-16LE", Binary: true}},
+		{"\xfe\xff\x00a", diff.Properties{BOM: "UTF-16BE", Binary: true}},
+	} {
+		if got := diff.Detect([]byte(test.data)); got != test.expect {
+			t.Errorf("%q: expected %+v, got %+v", test.data, test.expect, got)
+		}
+	}
+}
+
+func TestFilesProperties(t *testing.T) {
+	a := writeFile(t, "a", "one\ntwo\n")
+	b := writeFile(t, "b", "one\r\ntwo\r\n")
+	res, err := diff.Files(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.PropsA.EOL != "\n" || res.PropsB.EOL != "\r\n" || !res.PropsA.FinalNewline || !res.PropsB.FinalNewline {
+		t.Error("expected only the line endings to differ, got", res.PropsA, res.PropsB)
+	}
+	b = writeFile(t, "b", "one\x00")
+	if res, err = diff.Files(a, b); err != nil {
+		t.Fatal(err)
+	}
+	if res.PropsA.Binary || !res.PropsB.Binary {
+		t.Error("expected only the second file to be binary, got", res.PropsA, res.PropsB)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// A HunkRef refers to a hunk of a patch by the index of its file
+// and the index of the hunk in the file.
+type HunkRef struct {
+	File, Hunk int
+}
+
+// Hunks returns references to all hunks of p in order.
+func (p Patch) Hunks() []HunkRef {
+	var refs []HunkRef
+	for i, f := range p {
+		for j := range f.Hunks {
+			refs = append(refs, HunkRef{i, j})
+		}
+	}
+	return refs
+}
+
+// Filter returns the patch with only the hunks for which keep returns true,
+// like the hunks staged with git add -p. Files whose hunks are all dropped
+// are left out, files without hunks are kept.
+func (p Patch) Filter(keep func(ref HunkRef) bool) Patch {
+	var res Patch
+	for i, f := range p {
+		if len(f.Hunks) == 0 {
+			res = append(res, f)
+			continue
+		}
+		g := f.Filter(func(j int, h Hunk) bool { return keep(HunkRef{i, j}) })
+		if len(g.Hunks) == 0 {
+			continue
+		}
+		res = append(res, g)
+	}
+	return res
+}
+
+// Filter returns the patch with only the hunks for which keep returns true.
+// The positions in b of the kept hunks are moved as if the dropped hunks
+// were never made, and overlapping hunks, like neighbors returned by
+// Split, are joined again.
+func (f FilePatch) Filter(keep func(i int, h Hunk) bool) FilePatch {
+	res := f
+	res.Hunks = nil
+	delta := 0 // the growth of the kept hunks so far
+	for i, h := range f.Hunks {
+		if !keep(i, h) {
+			continue
+		}
+		h = moveHunk(h, h.A+delta)
+		delta += h.LenB - h.LenA
+		if n := len(res.Hunks); n > 0 && h.A < res.Hunks[n-1].A+res.Hunks[n-1].LenA {
+			res.Hunks[n-1] = joinHunks(res.Hunks[n-1], h)
+			continue
+		}
+		res.Hunks = append(res.Hunks, h)
+	}
+	return res
+}
+
+// moveHunk returns a copy of h at position b in b.
+func moveHunk(h Hunk, b int) Hunk {
+	d := b - h.B
+	if d == 0 {
+		return h
+	}
+	h.B = b
+	changes := make([]Change, len(h.Changes))
+	for i, c := range h.Changes {
+		c.B += d
+		changes[i] = c
+	}
+	h.Changes = changes
+	return h
+}
+
+// joinHunks joins h with the hunk g following it, whose leading common
+// elements overlap with the end of h.
+func joinHunks(h, g Hunk) Hunk {
+	overlap := h.A + h.LenA - g.A
+	res := Hunk{
+		A: h.A, B: h.B,
+		LenA:    g.A + g.LenA - h.A,
+		LenB:    g.B + g.LenB - h.B,
+		Changes: append(append([]Change(nil), h.Changes...), g.Changes...),
+		Section: h.Section,
+	}
+	if h.Lines != nil {
+		res.Lines = append(append([]string(nil), h.Lines...), g.Lines[min(overlap, len(g.Lines)):]...)
+	}
+	return res
+}
+
+// Split splits h into smaller hunks at the common elements between its
+// changes, like the split command of git add -p. Every part keeps all
+// common elements around its changes, so neighbors overlap. A hunk that
+// can not be split is returned alone.
+func (h Hunk) Split() []Hunk {
+	// the indices of the first changes of the parts
+	var starts []int
+	for i, c := range h.Changes {
+		if i == 0 || c.A > h.Changes[i-1].A+h.Changes[i-1].Del {
+			starts = append(starts, i)
+		}
+	}
+	if len(starts) < 2 {
+		return []Hunk{h}
+	}
+	res := make([]Hunk, len(starts))
+	for k, i := range starts {
+		j := len(h.Changes)
+		if k+1 < len(starts) {
+			j = starts[k+1]
+		}
+		a0, b0 := h.A, h.B
+		if i > 0 {
+			prev := h.Changes[i-1]
+			a0, b0 = prev.A+prev.Del, prev.B+prev.Ins
+		}
+		a1, b1 := h.A+h.LenA, h.B+h.LenB
+		if j < len(h.Changes) {
+			a1, b1 = h.Changes[j].A, h.Changes[j].B
+		}
+		res[k] = Hunk{A: a0, B: b0, LenA: a1 - a0, LenB: b1 - b0, Changes: h.Changes[i:j:j], Section: h.Section}
+	}
+	if h.Lines == nil {
+		return res
+	}
+	for k := range res {
+		p := &res[k]
+		x, y := h.A, h.B
+		for _, line := range h.Lines {
+			switch line[0] {
+			case ' ':
+				if x >= p.A && x < p.A+p.LenA {
+					p.Lines = append(p.Lines, line)
+				}
+				x++
+				y++
+			case '-':
+				if x >= p.A && x < p.A+p.LenA {
+					p.Lines = append(p.Lines, line)
+				}
+				x++
+			case '+':
+				if y >= p.B && y < p.B+p.LenB {
+					p.Lines = append(p.Lines, line)
+				}
+				y++
+			}
+		}
+	}
+	return res
+}
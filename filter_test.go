@@ -0,0 +1,104 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestHunkSplit(t *testing.T) {
+	a := splitLines("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n")
+	b := splitLines("1\n2\ntwo\n3\n4\n5\n6\n7\n9\n10\n")
+	f := diff.NewFilePatch("f", "f", a, b, diff.Lines(a, b), 3)
+	if len(f.Hunks) != 1 {
+		t.Fatal("expected a single hunk, got", f.Hunks)
+	}
+	parts := f.Hunks[0].Split()
+	if len(parts) != 2 {
+		t.Fatal("expected two parts, got", parts)
+	}
+	expect := []string{" 1\n", " 2\n", "+two\n", " 3\n", " 4\n", " 5\n", " 6\n", " 7\n"}
+	if !reflect.DeepEqual(parts[0].Lines, expect) {
+		t.Errorf("expected %q, got %q", expect, parts[0].Lines)
+	}
+	expect = []string{" 3\n", " 4\n", " 5\n", " 6\n", " 7\n", "-8\n", " 9\n", " 10\n"}
+	if !reflect.DeepEqual(parts[1].Lines, expect) {
+		t.Errorf("expected %q, got %q", expect, parts[1].Lines)
+	}
+	if parts[1].A != 2 || parts[1].B != 3 || parts[1].LenA != 8 || parts[1].LenB != 7 {
+		t.Error("unexpected range of", parts[1])
+	}
+
+	// staging only the second part
+	split := f
+	split.Hunks = parts
+	g := split.Filter(func(i int, h diff.Hunk) bool { return i == 1 })
+	res, _, err := diff.Apply(a, g.Hunks, nil)
+	if expect := "1\n2\n3\n4\n5\n6\n7\n9\n10\n"; err != nil || strings.Join(res, "") != expect {
+		t.Errorf("expected %q, got %q %v", expect, strings.Join(res, ""), err)
+	}
+	if g.Hunks[0].B != 2 {
+		t.Error("expected the hunk to move to 2 in b, got", g.Hunks[0])
+	}
+	// keeping both joins them again
+	g = split.Filter(func(int, diff.Hunk) bool { return true })
+	if !reflect.DeepEqual(g, f) {
+		t.Error("expected", f, "got", g)
+	}
+}
+
+func TestPatchFilter(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a := randomLines(r, r.Intn(30), 5)
+		b := randomLines(r, r.Intn(30), 5)
+		f := diff.NewFilePatch("a", "b", a, b, diff.Lines(a, b), r.Intn(4))
+		var hunks []diff.Hunk
+		for _, h := range f.Hunks {
+			hunks = append(hunks, h.Split()...)
+		}
+		f.Hunks = hunks
+		p := diff.Patch{f}
+		all := p.Filter(func(diff.HunkRef) bool { return true })
+		if len(f.Hunks) > 0 {
+			res, _, err := diff.Apply(a, all[0].Hunks, nil)
+			if err != nil || strings.Join(res, "") != strings.Join(b, "") {
+				t.Fatal(a, b, "applied all to", res, err)
+			}
+		}
+		keep := map[diff.HunkRef]bool{}
+		for _, ref := range p.Hunks() {
+			keep[ref] = r.Intn(2) == 0
+		}
+		some := p.Filter(func(ref diff.HunkRef) bool { return keep[ref] })
+		if len(some) == 0 {
+			continue
+		}
+		staged, _, err := diff.Apply(a, some[0].Hunks, nil)
+		if err != nil {
+			t.Fatal(a, b, "failed to apply", some, err)
+		}
+		if diff.Check(a, staged, diff.Lines(a, staged)) != nil {
+			t.Fatal("invalid staged lines", staged)
+		}
+	}
+}
+
+func TestPatchFilterFiles(t *testing.T) {
+	a := splitLines("1\n2\n3\n")
+	f := diff.NewFilePatch("f", "", a, nil, diff.Lines(a, nil), 3)
+	p := diff.Patch{f, {OldPath: "x", NewPath: "y"}}
+	if refs := p.Hunks(); len(refs) != 1 || refs[0] != (diff.HunkRef{}) {
+		t.Fatal("expected one hunk, got", refs)
+	}
+	if res := p.Filter(func(diff.HunkRef) bool { return false }); len(res) != 1 || res[0].OldPath != "x" {
+		t.Error("expected only the rename, got", res)
+	}
+}
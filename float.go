@@ -0,0 +1,117 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A Tolerance tells how far two floats may be apart to be equal.
+// They are equal if their difference is at most Abs, or at most Rel times
+// the larger of their magnitudes. The zero Tolerance compares exactly.
+type Tolerance struct {
+	Abs, Rel float64
+}
+
+// Equal reports whether x and y are equal within t. NaNs equal each other,
+// infinities only equal themselves.
+func (t Tolerance) Equal(x, y float64) bool {
+	if x == y || math.IsNaN(x) && math.IsNaN(y) {
+		return true
+	}
+	if math.IsInf(x, 0) || math.IsInf(y, 0) {
+		return false
+	}
+	d := math.Abs(x - y)
+	return d <= t.Abs || d <= t.Rel*math.Max(math.Abs(x), math.Abs(y))
+}
+
+// FloatData is the Data of two float slices compared with a tolerance.
+// Equality within a tolerance is not transitive, so the result depends on
+// which elements are compared; it is still a valid edit script.
+type FloatData struct {
+	A, B      []float64
+	Tolerance Tolerance
+}
+
+// Equal reports whether A[i] and B[j] are equal within the tolerance.
+func (d *FloatData) Equal(i, j int) bool { return d.Tolerance.Equal(d.A[i], d.B[j]) }
+
+// Floats returns the difference of two float slices whose elements are
+// equal within tol.
+func Floats(a, b []float64, tol Tolerance, opts ...Option) []Change {
+	return Diff(len(a), len(b), &FloatData{a, b, tol}, opts...)
+}
+
+// A FloatChange is a changed element of two float slices. A is its index
+// in a or -1 if it was added, B its index in b or -1 if it was removed.
+// Old and New are its values in a and b.
+type FloatChange struct {
+	A, B     int
+	Old, New float64
+}
+
+// Added reports whether the element was added in b.
+func (c FloatChange) Added() bool { return c.A == -1 }
+
+// Removed reports whether the element was removed from a.
+func (c FloatChange) Removed() bool { return c.B == -1 }
+
+// Delta returns New-Old, which is only meaningful if the element
+// was neither added nor removed.
+func (c FloatChange) Delta() float64 { return c.New - c.Old }
+
+// FloatChanges returns the changed elements of a and b given the changes
+// from a to b. The deleted and inserted elements of a change are paired by
+// position, the ones left over are removed or added.
+func FloatChanges(a, b []float64, changes []Change) []FloatChange {
+	var res []FloatChange
+	for _, c := range changes {
+		k := min(c.Del, c.Ins)
+		for i := 0; i < k; i++ {
+			res = append(res, FloatChange{A: c.A + i, B: c.B + i, Old: a[c.A+i], New: b[c.B+i]})
+		}
+		for i := k; i < c.Del; i++ {
+			res = append(res, FloatChange{A: c.A + i, B: -1, Old: a[c.A+i]})
+		}
+		for i := k; i < c.Ins; i++ {
+			res = append(res, FloatChange{A: -1, B: c.B + i, New: b[c.B+i]})
+		}
+	}
+	return res
+}
+
+// WriteFloatChanges writes one line for every change with its indices,
+// values and the delta of changed values:
+//
+//	changed a[1] b[1]: 0.5 -> 0.75 (+0.25)
+//	removed a[3]: 1.5
+//	added b[4]: 2.5
+func WriteFloatChanges(w io.Writer, changes []FloatChange) error {
+	var sb strings.Builder
+	for _, c := range changes {
+		switch {
+		case c.Added():
+			sb.WriteString("added b[" + strconv.Itoa(c.B) + "]: " + formatFloat(c.New))
+		case c.Removed():
+			sb.WriteString("removed a[" + strconv.Itoa(c.A) + "]: " + formatFloat(c.Old))
+		default:
+			sb.WriteString("changed a[" + strconv.Itoa(c.A) + "] b[" + strconv.Itoa(c.B) + "]: ")
+			sb.WriteString(formatFloat(c.Old) + " -> " + formatFloat(c.New) + " (")
+			if d := c.Delta(); d >= 0 {
+				sb.WriteByte('+')
+			}
+			sb.WriteString(formatFloat(c.Delta()) + ")")
+		}
+		sb.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func formatFloat(x float64) string { return strconv.FormatFloat(x, 'g', -1, 64) }
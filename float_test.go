@@ -0,0 +1,80 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestTolerance(t *testing.T) {
+	for _, test := range []struct {
+		tol    diff.Tolerance
+		x, y   float64
+		expect bool
+	}{
+		{diff.Tolerance{}, 1, 1, true},
+		{diff.Tolerance{}, 0.30000000000000004, 0.3, false},
+		{diff.Tolerance{Abs: 1e-9}, 0.30000000000000004, 0.3, true},
+		{diff.Tolerance{Abs: 0.5}, 1, 2, false},
+		{diff.Tolerance{Rel: 0.01}, 1000, 1009, true},
+		{diff.Tolerance{Rel: 0.01}, 1, 1.02, false},
+		{diff.Tolerance{}, math.NaN(), math.NaN(), true},
+		{diff.Tolerance{Abs: 1}, math.NaN(), 0, false},
+		{diff.Tolerance{}, math.Inf(1), math.Inf(1), true},
+		{diff.Tolerance{Rel: 0.01}, math.Inf(1), math.Inf(1), true},
+		{diff.Tolerance{Rel: 0.01}, math.Inf(1), 1, false},
+		{diff.Tolerance{Rel: 0.01}, 1, math.Inf(-1), false},
+		{diff.Tolerance{Rel: 0.01}, math.Inf(1), math.Inf(-1), false},
+		{diff.Tolerance{Abs: math.Inf(1)}, math.Inf(-1), 0, false},
+	} {
+		if res := test.tol.Equal(test.x, test.y); res != test.expect {
+			t.Error(test.tol, test.x, test.y, "expected", test.expect, "got", res)
+		}
+	}
+}
+
+func TestFloats(t *testing.T) {
+	a := []float64{0.1, 0.2, 0.3, 0.4}
+	b := []float64{0.1 + 1e-12, 0.25, 0.3 - 1e-12, 0.4}
+	if res := diff.Floats(a, b, diff.Tolerance{}); len(res) != 1 || res[0].Del != 3 {
+		t.Error("expected the rounded elements to differ, got", res)
+	}
+	expect := []diff.Change{{A: 1, B: 1, Del: 1, Ins: 1}}
+	if res := diff.Floats(a, b, diff.Tolerance{Abs: 1e-9}); !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+}
+
+func TestFloatChanges(t *testing.T) {
+	a := []float64{1, 2, 3, 4}
+	b := []float64{1, 2.5, 4, 5, 6}
+	res := diff.FloatChanges(a, b, diff.Floats(a, b, diff.Tolerance{}))
+	expect := []diff.FloatChange{
+		{A: 1, B: 1, Old: 2, New: 2.5},
+		{A: 2, B: -1, Old: 3},
+		{A: -1, B: 3, New: 5},
+		{A: -1, B: 4, New: 6},
+	}
+	if len(res) != len(expect) {
+		t.Fatal("expected", expect, "got", res)
+	}
+	for i := range res {
+		if res[i] != expect[i] {
+			t.Error("expected", expect[i], "got", res[i])
+		}
+	}
+	var sb strings.Builder
+	if err := diff.WriteFloatChanges(&sb, res); err != nil {
+		t.Fatal(err)
+	}
+	text := "changed a[1] b[1]: 2 -> 2.5 (+0.5)\nremoved a[2]: 3\nadded b[3]: 5\nadded b[4]: 6\n"
+	if sb.String() != text {
+		t.Errorf("expected %q, got %q", text, sb.String())
+	}
+}
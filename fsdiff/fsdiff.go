@@ -0,0 +1,288 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fsdiff compares two file system trees.
+package fsdiff
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+
+	"github.com/echlebek/diff"
+)
+
+// A Status describes how a file differs between two trees.
+type Status int
+
+const (
+	Added    Status = iota + 1 // only in the second tree
+	Removed                    // only in the first tree
+	Modified                   // in both trees with different content
+	Renamed                    // moved from OldPath, see Options.Renames
+	Copied                     // copied from OldPath, see Options.Copies
+)
+
+func (s Status) String() string {
+	switch s {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	case Renamed:
+		return "renamed"
+	case Copied:
+		return "copied"
+	}
+	return "unknown"
+}
+
+// An Entry describes a file that differs between two trees.
+type Entry struct {
+	Path   string // slash-separated path relative to the roots
+	Status Status
+	// OldPath is the source of a renamed or copied file.
+	OldPath string
+	// Score is the similarity of a renamed or copied file
+	// to its source in percent.
+	Score int
+	// Changes holds the content changes of a modified file
+	// if Options.Diff is set.
+	Changes []diff.Change
+}
+
+// String returns the entry like git diff --name-status,
+// for example "M\tpath" or "R095\told\tnew".
+func (e Entry) String() string {
+	switch e.Status {
+	case Added:
+		return "A\t" + e.Path
+	case Removed:
+		return "D\t" + e.Path
+	case Modified:
+		return "M\t" + e.Path
+	case Renamed, Copied:
+		return fmt.Sprintf("%c%03d\t%s\t%s", "RC"[e.Status-Renamed], e.Score, e.OldPath, e.Path)
+	}
+	return "?\t" + e.Path
+}
+
+// Options configure Compare.
+type Options struct {
+	// Diff computes the content changes of modified files, for example
+	// with diff.Bytes. Modified files are always reported, their Changes
+	// are only computed if it is set.
+	Diff func(a, b []byte) []diff.Change
+	// Renames is the similarity in percent at which a removed and an
+	// added file are reported as renamed, like git's -M. Renames are only
+	// detected if it is set.
+	Renames int
+	// Copies reports added files as copied if they are at least
+	// Renames percent similar to any file of the first tree, like git's
+	// --find-copies-harder.
+	Copies bool
+}
+
+// Compare walks both trees and returns the regular files that were added,
+// removed or modified, ordered by path. Files with equal paths are compared
+// by size first and by SHA-256 hash only if the sizes are equal.
+// opts may be nil.
+func Compare(a, b fs.FS, opts *Options) ([]Entry, error) {
+	if opts == nil {
+		opts = new(Options)
+	}
+	fa, err := files(a)
+	if err != nil {
+		return nil, err
+	}
+	fb, err := files(b)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for p := range fa {
+		if _, ok := fb[p]; !ok {
+			entries = append(entries, Entry{Path: p, Status: Removed})
+		}
+	}
+	for p, sb := range fb {
+		sa, ok := fa[p]
+		if !ok {
+			entries = append(entries, Entry{Path: p, Status: Added})
+			continue
+		}
+		same := false
+		if sa == sb {
+			ha, err := hash(a, p)
+			if err != nil {
+				return nil, err
+			}
+			hb, err := hash(b, p)
+			if err != nil {
+				return nil, err
+			}
+			same = bytes.Equal(ha, hb)
+		}
+		if same {
+			continue
+		}
+		e := Entry{Path: p, Status: Modified}
+		if opts.Diff != nil {
+			da, err := fs.ReadFile(a, p)
+			if err != nil {
+				return nil, err
+			}
+			db, err := fs.ReadFile(b, p)
+			if err != nil {
+				return nil, err
+			}
+			e.Changes = opts.Diff(da, db)
+		}
+		entries = append(entries, e)
+	}
+	if opts.Renames > 0 {
+		if entries, err = renames(a, b, fa, entries, opts); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+// renames replaces removed and added entries by renamed entries, and added
+// entries by copied entries if requested, pairing the most similar files first.
+func renames(a, b fs.FS, fa map[string]int64, entries []Entry, opts *Options) ([]Entry, error) {
+	var removed, added []int
+	for i, e := range entries {
+		switch e.Status {
+		case Removed:
+			removed = append(removed, i)
+		case Added:
+			added = append(added, i)
+		}
+	}
+	sources := make([]string, 0, len(removed))
+	for _, i := range removed {
+		sources = append(sources, entries[i].Path)
+	}
+	if opts.Copies {
+		sources = sources[:0]
+		for p := range fa {
+			sources = append(sources, p)
+		}
+	}
+	sort.Strings(sources)
+	isRemoved := make(map[string]int, len(removed))
+	for _, i := range removed {
+		isRemoved[entries[i].Path] = i
+	}
+
+	type candidate struct {
+		src   string
+		dst   int
+		score int
+	}
+	var candidates []candidate
+	data := make(map[string][]byte) // contents of the sources
+	for _, i := range added {
+		db, err := fs.ReadFile(b, entries[i].Path)
+		if err != nil {
+			return nil, err
+		}
+		for _, src := range sources {
+			da, ok := data[src]
+			if !ok {
+				if da, err = fs.ReadFile(a, src); err != nil {
+					return nil, err
+				}
+				data[src] = da
+			}
+			if score := similarity(da, db); score >= opts.Renames {
+				candidates = append(candidates, candidate{src, i, score})
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		ci, cj := candidates[i], candidates[j]
+		if ci.score != cj.score {
+			return ci.score > cj.score
+		}
+		if pi, pj := entries[ci.dst].Path, entries[cj.dst].Path; pi != pj {
+			return pi < pj
+		}
+		return ci.src < cj.src
+	})
+
+	drop := make(map[int]bool)
+	done := make(map[int]bool)
+	for _, c := range candidates {
+		if done[c.dst] {
+			continue
+		}
+		e := &entries[c.dst]
+		if r, ok := isRemoved[c.src]; ok && !drop[r] {
+			// the first use of a removed file is a rename
+			drop[r] = true
+			e.Status = Renamed
+		} else if opts.Copies {
+			e.Status = Copied
+		} else {
+			continue
+		}
+		e.OldPath, e.Score = c.src, c.score
+		done[c.dst] = true
+	}
+	kept := entries[:0]
+	for i, e := range entries {
+		if !drop[i] {
+			kept = append(kept, e)
+		}
+	}
+	return kept, nil
+}
+
+// similarity returns how similar a and b are in percent,
+// counting the bytes of common lines like git.
+func similarity(a, b []byte) int {
+	if bytes.Equal(a, b) {
+		return 100
+	}
+	changes := diff.PresetData.Diff(string(a), string(b))
+	return int(diff.Similarity(len(a), len(b), changes) * 100)
+}
+
+// files returns the sizes of the regular files in fsys by path.
+func files(fsys fs.FS) (map[string]int64, error) {
+	sizes := make(map[string]int64)
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		sizes[p] = info.Size()
+		return nil
+	})
+	return sizes, err
+}
+
+func hash(fsys fs.FS, name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fsdiff_test
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/echlebek/diff"
+	"github.com/echlebek/diff/fsdiff"
+)
+
+func TestCompare(t *testing.T) {
+	a := fstest.MapFS{
+		"same.txt":         {Data: []byte("same")},
+		"removed.txt":      {Data: []byte("gone")},
+		"dir/resized.txt":  {Data: []byte("short")},
+		"dir/modified.txt": {Data: []byte("abcd")},
+	}
+	b := fstest.MapFS{
+		"same.txt":         {Data: []byte("same")},
+		"added.txt":        {Data: []byte("new")},
+		"dir/resized.txt":  {Data: []byte("much longer")},
+		"dir/modified.txt": {Data: []byte("abed")},
+		"dir/empty":        {Mode: fs.ModeDir | 0755},
+	}
+	entries, err := fsdiff.Compare(a, b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []struct {
+		path   string
+		status fsdiff.Status
+	}{
+		{"added.txt", fsdiff.Added},
+		{"dir/modified.txt", fsdiff.Modified},
+		{"dir/resized.txt", fsdiff.Modified},
+		{"removed.txt", fsdiff.Removed},
+	}
+	if len(entries) != len(expect) {
+		t.Fatal("expected", expect, "got", entries)
+	}
+	for i, e := range expect {
+		if entries[i].Path != e.path || entries[i].Status != e.status || entries[i].Changes != nil {
+			t.Error("expected", e.path, e.status, "got", entries[i])
+		}
+	}
+}
+
+func TestCompareContent(t *testing.T) {
+	a := fstest.MapFS{"f": {Data: []byte("abcd")}}
+	b := fstest.MapFS{"f": {Data: []byte("abed")}}
+	entries, err := fsdiff.Compare(a, b, &fsdiff.Options{Diff: diff.Bytes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Status != fsdiff.Modified {
+		t.Fatal("expected f to be modified, got", entries)
+	}
+	expect := []diff.Change{{A: 2, B: 2, Del: 1, Ins: 1}}
+	if c := entries[0].Changes; len(c) != 1 || c[0] != expect[0] {
+		t.Error("expected", expect, "got", c)
+	}
+}
+
+func TestCompareRenames(t *testing.T) {
+	text := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	a := fstest.MapFS{
+		"old.txt":    {Data: []byte(text)},
+		"moved.txt":  {Data: []byte("moved\n")},
+		"gone.txt":   {Data: []byte("something else\n")},
+		"source.txt": {Data: []byte(text + "eleven\n")},
+	}
+	b := fstest.MapFS{
+		"new.txt":    {Data: []byte(strings.Replace(text, "two", "2", 1))},
+		"dir/moved":  {Data: []byte("moved\n")},
+		"other.txt":  {Data: []byte("unrelated\n")},
+		"source.txt": {Data: []byte(text + "eleven\n")},
+	}
+	entries, err := fsdiff.Compare(a, b, &fsdiff.Options{Renames: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{
+		"R100\tmoved.txt\tdir/moved",
+		"D\tgone.txt",
+		"R091\told.txt\tnew.txt",
+		"A\tother.txt",
+	}
+	if len(entries) != len(expect) {
+		t.Fatal("expected", expect, "got", entries)
+	}
+	for i, e := range expect {
+		if s := entries[i].String(); s != e {
+			t.Errorf("expected %q, got %q", e, s)
+		}
+	}
+
+	// without a removed file the added one is a copy
+	delete(a, "old.txt")
+	entries, err = fsdiff.Compare(a, b, &fsdiff.Options{Renames: 50, Copies: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, e := range entries {
+		if e.Path == "new.txt" {
+			found = true
+			if s := e.String(); s != "C080\tsource.txt\tnew.txt" {
+				t.Errorf("expected a copy of source.txt, got %q", s)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected new.txt in", entries)
+	}
+}
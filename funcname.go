@@ -0,0 +1,105 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"regexp"
+	"strings"
+)
+
+// A FuncMatcher reports whether line starts a function or a similar
+// section of a file and returns the text to show in hunk headers.
+type FuncMatcher func(line string) (string, bool)
+
+// maxSection is the longest section text in bytes, like git.
+const maxSection = 80
+
+// DefaultFuncMatcher matches lines that start with a letter, '_' or '$',
+// like git without a diff driver.
+func DefaultFuncMatcher(line string) (string, bool) {
+	if line == "" {
+		return "", false
+	}
+	c := line[0]
+	if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '$' {
+		return line, true
+	}
+	return "", false
+}
+
+// RegexpFuncMatcher returns a FuncMatcher for lines matching any of the
+// regular expressions, like git's xfuncname. The text of the first
+// subexpression is shown if there is one, otherwise the whole match.
+func RegexpFuncMatcher(res ...*regexp.Regexp) FuncMatcher {
+	return func(line string) (string, bool) {
+		line = strings.TrimSuffix(line, "\n")
+		for _, re := range res {
+			m := re.FindStringSubmatch(line)
+			switch {
+			case m == nil:
+				continue
+			case len(m) > 1 && m[1] != "":
+				return m[1], true
+			}
+			return m[0], true
+		}
+		return "", false
+	}
+}
+
+// languages holds the patterns of git's built-in diff drivers.
+var languages = map[string][]string{
+	"golang": {
+		`^[ \t]*(func[ \t]*.*(\{[ \t]*)?)$`,
+		`^[ \t]*(type[ \t].*(struct|interface)[ \t]*(\{[ \t]*)?)$`,
+	},
+	"python": {
+		`^[ \t]*((class|(async[ \t]+)?def)[ \t].*)$`,
+	},
+	"rust": {
+		`^[\t ]*((pub(\([^\)]+\))?[\t ]+)?((async|const|unsafe|extern([\t ]+"[^"]+"))[\t ]+)?(struct|enum|union|mod|trait|fn|impl|macro_rules!)[< \t]+[^;]*)$`,
+	},
+	"java": {
+		`^[ \t]*(([A-Za-z_][A-Za-z_0-9]*[ \t]+)+[A-Za-z_][A-Za-z_0-9]*[ \t]*\([^;]*)$`,
+	},
+}
+
+// LanguageFuncMatcher returns the FuncMatcher of git's diff driver for
+// lang, one of "golang", "java", "python" and "rust", or nil if there is none.
+func LanguageFuncMatcher(lang string) FuncMatcher {
+	patterns, ok := languages[lang]
+	if !ok {
+		return nil
+	}
+	res := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		res[i] = regexp.MustCompile(p)
+	}
+	return RegexpFuncMatcher(res...)
+}
+
+// Sections sets the Section of every hunk of lines a to the last line
+// before the hunk accepted by match, or DefaultFuncMatcher if match is nil.
+// The hunks are modified in place and returned.
+func Sections(a []string, hunks []Hunk, match FuncMatcher) []Hunk {
+	if match == nil {
+		match = DefaultFuncMatcher
+	}
+	for i := range hunks {
+		h := &hunks[i]
+		h.Section = ""
+		for j := h.A - 1; j >= 0; j-- {
+			if s, ok := match(a[j]); ok {
+				s = strings.TrimRight(s, " \t\r\n")
+				if len(s) > maxSection {
+					s = s[:maxSection]
+				}
+				h.Section = s
+				break
+			}
+		}
+	}
+	return hunks
+}
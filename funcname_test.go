@@ -0,0 +1,69 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestSections(t *testing.T) {
+	a := splitLines("package p\n\nfunc f() {\n\tone()\n\ttwo()\n\tthree()\n\tfour()\n\tfive()\n}\n\ntype T struct {\n\tA int\n\tB int\n\tC int\n\tD int\n}\n")
+	b := make([]string, len(a))
+	copy(b, a)
+	b[6] = "\tFOUR()\n"
+	b[14] = "\tE int\n"
+	changes := diff.Lines(a, b)
+
+	hunks := diff.Sections(a, diff.NewFilePatch("p.go", "p.go", a, b, changes, 1).Hunks, diff.LanguageFuncMatcher("golang"))
+	expect := []string{"func f() {", "type T struct {"}
+	if len(hunks) != len(expect) {
+		t.Fatal("expected", len(expect), "hunks, got", hunks)
+	}
+	for i, h := range hunks {
+		if h.Section != expect[i] {
+			t.Errorf("expected section %q, got %q", expect[i], h.Section)
+		}
+	}
+
+	// the default matches lines starting with a letter
+	hunks = diff.Sections(a, hunks, nil)
+	if hunks[0].Section != "func f() {" || hunks[1].Section != "type T struct {" {
+		t.Error("unexpected sections", hunks[0].Section, hunks[1].Section)
+	}
+
+	var sb strings.Builder
+	if err := diff.WritePatch(&sb, diff.Patch{{OldPath: "p.go", NewPath: "p.go", Hunks: hunks[:1]}}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "@@ -6,3 +6,3 @@ func f() {\n") {
+		t.Error("expected a section in the hunk header of", sb.String())
+	}
+}
+
+func TestRegexpFuncMatcher(t *testing.T) {
+	match := diff.RegexpFuncMatcher(regexp.MustCompile(`^def (\w+)`), regexp.MustCompile(`^class \w+`))
+	for _, test := range []struct {
+		line, expect string
+		ok           bool
+	}{
+		{"def foo(x):\n", "foo", true},
+		{"class Bar:\n", "class Bar", true},
+		{"    return x\n", "", false},
+	} {
+		if s, ok := match(test.line); s != test.expect || ok != test.ok {
+			t.Errorf("match(%q) = %q, %v, expected %q, %v", test.line, s, ok, test.expect, test.ok)
+		}
+	}
+	if diff.LanguageFuncMatcher("cobol") != nil {
+		t.Error("expected no matcher for an unknown language")
+	}
+	if s, ok := diff.LanguageFuncMatcher("python")("    async def run(self):\n"); !ok || s != "async def run(self):" {
+		t.Errorf("unexpected python section %q", s)
+	}
+}
@@ -1,3 +1,9 @@
 module github.com/echlebek/diff
 
-go 1.17
+go 1.21
+
+require (
+	golang.org/x/text v0.14.0
+	google.golang.org/protobuf v1.34.2
+	gopkg.in/yaml.v3 v3.0.1
+)
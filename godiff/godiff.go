@@ -0,0 +1,247 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package godiff compares Go source files by their syntax instead of lines.
+package godiff
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/echlebek/diff"
+)
+
+// A Status describes how a declaration or statement differs between two files.
+type Status int
+
+const (
+	Added    Status = iota + 1 // only in the second file
+	Removed                    // only in the first file
+	Modified                   // changed signature, body or statements
+)
+
+func (s Status) String() string {
+	switch s {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// A Range is the source range of a node, End is exclusive.
+type Range struct {
+	Pos, End token.Position
+}
+
+// A Change describes a declaration that differs between two files, or the
+// statements of a function body that differ.
+type Change struct {
+	Status Status
+	// Decl names the declaration like "func (*T) Name", "type T", "var x"
+	// or "import \"fmt\"".
+	Decl string
+	// From and To are the ranges of the changed source in the old and the
+	// new file. From is empty for added declarations and To for removed
+	// ones. Statements inserted into a body have an empty From range at
+	// the position of the insertion, removed statements an empty To range.
+	From, To Range
+}
+
+// String returns the change like "M\tfunc main\tmain.go:3:2".
+func (c Change) String() string {
+	pos := c.To.Pos
+	if c.Status == Removed {
+		pos = c.From.Pos
+	}
+	return string("?ADM"[c.Status]) + "\t" + c.Decl + "\t" + pos.String()
+}
+
+// CompareSource parses the Go source files a and b and compares them like
+// Compare. The names are used in the positions of the changes.
+func CompareSource(nameA string, a []byte, nameB string, b []byte) ([]Change, error) {
+	fset := token.NewFileSet()
+	fa, err := parser.ParseFile(fset, nameA, a, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	fb, err := parser.ParseFile(fset, nameB, b, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	return Compare(fset, fa, fb), nil
+}
+
+// Compare returns the differences of two parsed files. Declarations are
+// matched by name regardless of their order and compared ignoring comments
+// and formatting. Declarations with the same name, like init functions,
+// are diffed as a sequence, so a changed one is reported as removed and
+// added. The statements of functions whose signature did not change are
+// diffed to report changed statements instead of the whole function.
+// Removed declarations come first, the other changes follow in the order
+// of b.
+func Compare(fset *token.FileSet, a, b *ast.File) []Change {
+	c := &comparer{fset: fset}
+	da, db := c.decls(a), c.decls(b)
+	key := func(d decl) string { return d.name }
+	same := func(x, y decl) bool { return x.text == y.text && x.bodyText == y.bodyText }
+	for _, ch := range diff.Keyed(da, db, key, same) {
+		switch {
+		case ch.Removed():
+			d := da[ch.A]
+			c.add(Removed, d.name, c.rangeOf(d.node), Range{})
+		case ch.Added():
+			d := db[ch.B]
+			c.add(Added, d.name, Range{}, c.rangeOf(d.node))
+		case !ch.Modified:
+			// only moved
+		case da[ch.A].text == db[ch.B].text && da[ch.A].body != nil && db[ch.B].body != nil:
+			c.compareBodies(db[ch.B].name, da[ch.A].body, db[ch.B].body)
+		default:
+			c.add(Modified, db[ch.B].name, c.rangeOf(da[ch.A].node), c.rangeOf(db[ch.B].node))
+		}
+	}
+	return c.changes
+}
+
+type comparer struct {
+	fset    *token.FileSet
+	changes []Change
+}
+
+func (c *comparer) add(s Status, decl string, from, to Range) {
+	c.changes = append(c.changes, Change{Status: s, Decl: decl, From: from, To: to})
+}
+
+func (c *comparer) rangeOf(n ast.Node) Range {
+	return c.span(n.Pos(), n.End())
+}
+
+func (c *comparer) span(pos, end token.Pos) Range {
+	return Range{c.fset.Position(pos), c.fset.Position(end)}
+}
+
+// A decl is a named declaration or spec. text is the formatted source
+// without the body of functions, which is compared statement by statement.
+type decl struct {
+	name     string
+	node     ast.Node
+	text     string
+	body     *ast.BlockStmt
+	bodyText string
+}
+
+func (c *comparer) decls(f *ast.File) []decl {
+	res := []decl{{name: "package", node: f.Name, text: f.Name.Name}}
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			name := "func " + d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				name = "func (" + types.ExprString(d.Recv.List[0].Type) + ") " + d.Name.Name
+			}
+			sig := *d
+			sig.Doc, sig.Body = nil, nil
+			res = append(res, decl{name, d, c.format(&sig), d.Body, ""})
+			if d.Body != nil {
+				res[len(res)-1].bodyText = c.format(d.Body)
+			}
+		case *ast.GenDecl:
+			for _, s := range d.Specs {
+				var node ast.Node = s
+				if !d.Lparen.IsValid() {
+					// include the keyword of single specs
+					node = d
+				}
+				res = append(res, decl{specName(d.Tok, s), node, d.Tok.String() + " " + c.format(s), nil, ""})
+			}
+		}
+	}
+	return res
+}
+
+func specName(tok token.Token, s ast.Spec) string {
+	switch s := s.(type) {
+	case *ast.ImportSpec:
+		return "import " + s.Path.Value
+	case *ast.TypeSpec:
+		return "type " + s.Name.Name
+	case *ast.ValueSpec:
+		names := make([]string, len(s.Names))
+		for i, n := range s.Names {
+			names[i] = n.Name
+		}
+		return tok.String() + " " + strings.Join(names, ", ")
+	}
+	return tok.String()
+}
+
+// format prints the tokens of n separated by spaces, so that the result
+// does not depend on comments, line breaks and other formatting.
+func (c *comparer) format(n interface{}) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, c.fset, n); err != nil {
+		return ""
+	}
+	var sb strings.Builder
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	s.Init(fset.AddFile("", -1, buf.Len()), buf.Bytes(), nil, 0)
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON {
+			// explicit and automatic semicolons depend on line breaks
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		if lit == "" {
+			lit = tok.String()
+		}
+		sb.WriteString(lit)
+	}
+	return sb.String()
+}
+
+// compareBodies diffs the statements of two function bodies.
+func (c *comparer) compareBodies(name string, a, b *ast.BlockStmt) {
+	ta, tb := c.texts(a.List), c.texts(b.List)
+	for _, ch := range diff.Lines(ta, tb) {
+		c.add(Modified, name, c.stmtRange(a, ch.A, ch.Del), c.stmtRange(b, ch.B, ch.Ins))
+	}
+}
+
+func (c *comparer) texts(stmts []ast.Stmt) []string {
+	res := make([]string, len(stmts))
+	for i, s := range stmts {
+		res[i] = c.format(s)
+	}
+	return res
+}
+
+// stmtRange returns the range of n statements of the block at i,
+// which is empty at the position of the statement if n is 0.
+func (c *comparer) stmtRange(block *ast.BlockStmt, i, n int) Range {
+	if n == 0 {
+		pos := block.Rbrace
+		if i < len(block.List) {
+			pos = block.List[i].Pos()
+		}
+		return c.span(pos, pos)
+	}
+	return c.span(block.List[i].Pos(), block.List[i+n-1].End())
+}
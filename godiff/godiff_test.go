@@ -0,0 +1,104 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godiff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff/godiff"
+)
+
+const before = `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// T is a type.
+type T struct{ x int }
+
+func (t *T) Get() int { return t.x }
+
+func init() { fmt.Println("first") }
+
+func init() { fmt.Println("second") }
+
+func main() {
+	t := &T{1}
+	fmt.Println(t.Get())
+	os.Exit(0)
+}
+`
+
+const after = `package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// T is a type with a different comment.
+type T struct {
+	x int
+}
+
+func (t *T) Get() int { return t.x }
+
+func (t *T) Set(x int) { t.x = x }
+
+func init() { fmt.Println("first") }
+
+func init() { fmt.Println("changed") }
+
+func main() {
+	t := &T{1}
+	t.Set(2)
+	fmt.Println(strings.Repeat("x", t.Get()))
+}
+`
+
+func TestCompareSource(t *testing.T) {
+	changes, err := godiff.CompareSource("a.go", []byte(before), "b.go", []byte(after))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res []string
+	for _, c := range changes {
+		res = append(res, c.String())
+	}
+	expect := []string{
+		"D\timport \"os\"\ta.go:5:2",
+		"D\tfunc init\ta.go:15:1",
+		"A\timport \"strings\"\tb.go:5:2",
+		"A\tfunc (*T) Set\tb.go:15:1",
+		"A\tfunc init\tb.go:19:1",
+		"M\tfunc main\tb.go:23:2",
+	}
+	if strings.Join(res, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expect, "\n"), strings.Join(res, "\n"))
+	}
+	// the changed statements of main
+	m := changes[len(changes)-1]
+	if m.From.Pos.Line != 19 || m.From.End.Line != 20 || m.To.Pos.Line != 23 || m.To.End.Line != 24 {
+		t.Error("unexpected statement ranges", m.From, m.To)
+	}
+}
+
+func TestCompareSignature(t *testing.T) {
+	a := "package p\n\nfunc f(x int) {\n\tprintln(x)\n}\n"
+	b := "package q\n\nfunc f(x, y int) {\n\tprintln(x)\n}\n"
+	changes, err := godiff.CompareSource("a.go", []byte(a), "b.go", []byte(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Decl != "package" || changes[1].Status != godiff.Modified || changes[1].To.End.Line != 5 {
+		t.Error("expected the package and function to be modified, got", changes)
+	}
+	if _, err := godiff.CompareSource("a.go", []byte("package"), "b.go", []byte(b)); err == nil {
+		t.Error("expected a syntax error")
+	}
+}
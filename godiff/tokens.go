@@ -0,0 +1,122 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godiff
+
+import (
+	"go/scanner"
+	"go/token"
+
+	"github.com/echlebek/diff"
+)
+
+// TokenOptions configure Tokens.
+type TokenOptions struct {
+	// IgnoreComments drops comments before diffing.
+	IgnoreComments bool
+}
+
+// Tokens splits the Go source files a and b into tokens with go/scanner and
+// returns the differences of the token streams as byte ranges of a and b.
+// White space and semicolons inserted at line breaks are not tokens, so
+// changes that only reformat the source are not reported. An inserted range
+// is placed before the next token. opts may be nil.
+func Tokens(a, b []byte, opts *TokenOptions) ([]diff.Change, error) {
+	var o TokenOptions
+	if opts != nil {
+		o = *opts
+	}
+	ta, err := scan(a, o)
+	if err != nil {
+		return nil, err
+	}
+	tb, err := scan(b, o)
+	if err != nil {
+		return nil, err
+	}
+	changes := diff.Diff(len(ta), len(tb), &tokens{ta, tb})
+	for i, c := range changes {
+		changes[i] = diff.Change{
+			A: offset(ta, c.A, len(a)), B: offset(tb, c.B, len(b)),
+		}
+		changes[i].Del = end(ta, c.A, c.Del, changes[i].A)
+		changes[i].Ins = end(tb, c.B, c.Ins, changes[i].B)
+	}
+	return changes, nil
+}
+
+// A tok is a scanned token and its byte range.
+type tok struct {
+	tok        token.Token
+	lit        string
+	start, end int
+}
+
+type tokens struct{ a, b []tok }
+
+func (d *tokens) Equal(i, j int) bool {
+	return d.a[i].tok == d.b[j].tok && d.a[i].lit == d.b[j].lit
+}
+
+func scan(src []byte, o TokenOptions) ([]tok, error) {
+	var errs scanner.ErrorList
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	file := fset.AddFile("", -1, len(src))
+	mode := scanner.ScanComments
+	if o.IgnoreComments {
+		mode = 0
+	}
+	s.Init(file, src, func(pos token.Position, msg string) { errs.Add(pos, msg) }, mode)
+	var res []tok
+	for {
+		pos, t, lit := s.Scan()
+		if t == token.EOF {
+			break
+		}
+		if t == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+		start := file.Offset(pos)
+		n := len(lit)
+		if lit == "" {
+			n = len(t.String())
+		}
+		if t == token.COMMENT || t == token.STRING && lit[0] == '`' {
+			n = extent(src[start:], lit)
+		}
+		res = append(res, tok{t, lit, start, start + n})
+	}
+	return res, errs.Err()
+}
+
+// extent returns the length of lit at the start of src. The scanner
+// removes carriage returns from comments and raw strings, so they are
+// skipped while matching.
+func extent(src []byte, lit string) int {
+	n, i := 0, 0
+	for ; i < len(lit) && n < len(src); n++ {
+		if src[n] != '\r' {
+			i++
+		}
+	}
+	return n
+}
+
+// offset returns the start of the token at i or n after the last token.
+func offset(toks []tok, i, n int) int {
+	if i < len(toks) {
+		return toks[i].start
+	}
+	return n
+}
+
+// end returns the length of the byte range from start to the end
+// of the n tokens at i.
+func end(toks []tok, i, n, start int) int {
+	if n == 0 {
+		return 0
+	}
+	return toks[i+n-1].end - start
+}
@@ -0,0 +1,70 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godiff_test
+
+import (
+	"testing"
+
+	"github.com/echlebek/diff"
+	"github.com/echlebek/diff/godiff"
+)
+
+func TestTokens(t *testing.T) {
+	a := []byte("package p\n\nfunc f() int { return 1 + 2 } // sum\n")
+	b := []byte("package p\n\n// f adds.\nfunc f() int {\n\treturn 1 + 3\n}\n")
+	changes, err := godiff.Tokens(a, b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res []string
+	for _, c := range changes {
+		res = append(res, string(a[c.A:c.A+c.Del])+"|"+string(b[c.B:c.B+c.Ins]))
+	}
+	expect := []string{"|// f adds.", "2|3", "// sum|"}
+	if len(res) != len(expect) {
+		t.Fatal("expected", expect, "got", res)
+	}
+	for i := range expect {
+		if res[i] != expect[i] {
+			t.Error("expected", expect[i], "got", res[i])
+		}
+	}
+
+	changes, err = godiff.Tokens(a, b, &godiff.TokenOptions{IgnoreComments: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []diff.Change{{A: 37, B: 49, Del: 1, Ins: 1}}; len(changes) != 1 || changes[0] != expect[0] {
+		t.Error("expected", expect, "got", changes)
+	}
+}
+
+func TestTokensCRLF(t *testing.T) {
+	a := []byte("package p\r\n\r\n/* one\r\n */\r\nvar s = `a\r\nb`\r\n")
+	b := []byte("package p\r\n\r\n/* two\r\n */\r\nvar s = `a\r\nc`\r\n")
+	changes, err := godiff.Tokens(a, b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res []string
+	for _, c := range changes {
+		res = append(res, string(a[c.A:c.A+c.Del])+"|"+string(b[c.B:c.B+c.Ins]))
+	}
+	expect := []string{"/* one\r\n */|/* two\r\n */", "`a\r\nb`|`a\r\nc`"}
+	if len(res) != len(expect) {
+		t.Fatalf("expected %q, got %q", expect, res)
+	}
+	for i := range expect {
+		if res[i] != expect[i] {
+			t.Errorf("expected %q, got %q", expect[i], res[i])
+		}
+	}
+}
+
+func TestTokensErrors(t *testing.T) {
+	if _, err := godiff.Tokens([]byte("x := \"unterminated"), nil, nil); err == nil {
+		t.Error("expected a scanner error")
+	}
+}
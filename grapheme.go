@@ -0,0 +1,139 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "unicode"
+
+// Graphemes returns the differences of two strings in user-perceived
+// characters, so that changes never split a base character from its combining
+// marks, emoji from their modifiers, or flags into their regional indicators.
+// The positions and lengths of the changes are byte offsets into a and b.
+//
+// Segmentation follows the extended grapheme cluster rules of Unicode
+// Standard Annex #29, with Extended_Pictographic approximated by the emoji
+// blocks and the Prepend rule left out.
+func Graphemes(a, b string) []Change {
+	return tokenChanges(splitGraphemes(a), splitGraphemes(b))
+}
+
+type graphemeProp int
+
+const (
+	gpOther graphemeProp = iota
+	gpCR
+	gpLF
+	gpControl
+	gpExtend
+	gpZWJ
+	gpRegionalIndicator
+	gpSpacingMark
+	gpL
+	gpV
+	gpT
+	gpLV
+	gpLVT
+	gpPictographic
+)
+
+func graphemeProperty(r rune) graphemeProp {
+	switch {
+	case r == '\r':
+		return gpCR
+	case r == '\n':
+		return gpLF
+	case r == 0x200D:
+		return gpZWJ
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return gpRegionalIndicator
+	case r == 0x200C, r >= 0x1F3FB && r <= 0x1F3FF, r >= 0xE0020 && r <= 0xE007F,
+		unicode.In(r, unicode.Mn, unicode.Me):
+		return gpExtend
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp):
+		return gpControl
+	case unicode.Is(unicode.Mc, r):
+		return gpSpacingMark
+	case r >= 0x1100 && r <= 0x115F, r >= 0xA960 && r <= 0xA97C:
+		return gpL
+	case r >= 0x1160 && r <= 0x11A7, r >= 0xD7B0 && r <= 0xD7C6:
+		return gpV
+	case r >= 0x11A8 && r <= 0x11FF, r >= 0xD7CB && r <= 0xD7FB:
+		return gpT
+	case r >= 0xAC00 && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return gpLV
+		}
+		return gpLVT
+	case isPictographic(r):
+		return gpPictographic
+	}
+	return gpOther
+}
+
+func isPictographic(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF, r >= 0x2600 && r <= 0x27BF,
+		r >= 0x2194 && r <= 0x21AA, r >= 0x2300 && r <= 0x23FF,
+		r >= 0x25AA && r <= 0x25FE, r >= 0x2B05 && r <= 0x2B55:
+		return true
+	}
+	switch r {
+	case 0x00A9, 0x00AE, 0x203C, 0x2049, 0x2122, 0x2139, 0x24C2,
+		0x2934, 0x2935, 0x3030, 0x303D, 0x3297, 0x3299:
+		return true
+	}
+	return false
+}
+
+// graphemeBreak reports whether there is a cluster boundary between runes
+// with the properties prev and next. ri is the number of regional indicators
+// ending at prev and pictZWJ whether prev is a ZWJ following a pictographic.
+func graphemeBreak(prev, next graphemeProp, ri int, pictZWJ bool) bool {
+	switch {
+	case prev == gpCR && next == gpLF:
+		return false
+	case prev == gpCR, prev == gpLF, prev == gpControl,
+		next == gpCR, next == gpLF, next == gpControl:
+		return true
+	case prev == gpL && (next == gpL || next == gpV || next == gpLV || next == gpLVT),
+		(prev == gpLV || prev == gpV) && (next == gpV || next == gpT),
+		(prev == gpLVT || prev == gpT) && next == gpT:
+		return false
+	case next == gpExtend, next == gpZWJ, next == gpSpacingMark:
+		return false
+	case pictZWJ && next == gpPictographic:
+		return false
+	case prev == gpRegionalIndicator && next == gpRegionalIndicator:
+		return ri%2 == 0
+	}
+	return true
+}
+
+// splitGraphemes splits s into extended grapheme clusters.
+func splitGraphemes(s string) []string {
+	var clusters []string
+	start := 0
+	prev := gpOther
+	ri := 0
+	pict, pictZWJ := false, false
+	for i, r := range s {
+		p := graphemeProperty(r)
+		if i > 0 && graphemeBreak(prev, p, ri, pictZWJ) {
+			clusters = append(clusters, s[start:i])
+			start = i
+		}
+		if p == gpRegionalIndicator {
+			ri++
+		} else {
+			ri = 0
+		}
+		pictZWJ = pict && p == gpZWJ
+		pict = p == gpPictographic || pict && p == gpExtend
+		prev = p
+	}
+	if start < len(s) {
+		clusters = append(clusters, s[start:])
+	}
+	return clusters
+}
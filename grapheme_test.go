@@ -0,0 +1,47 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestGraphemes(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		res  []diff.Change
+	}{
+		// e with combining acute vs e with combining grave
+		{"combining", "cafe\u0301!", "cafe\u0300!", []diff.Change{{A: 3, B: 3, Del: 3, Ins: 3}}},
+		// thumbs up with two different skin tones
+		{"modifier", "ok \U0001F44D\U0001F3FB", "ok \U0001F44D\U0001F3FF", []diff.Change{{A: 3, B: 3, Del: 8, Ins: 8}}},
+		// the flags of Germany and France share no cluster
+		{"flags", "\U0001F1E9\U0001F1EA\U0001F1EB\U0001F1F7", "\U0001F1EB\U0001F1F7\U0001F1E9\U0001F1EA",
+			[]diff.Change{{A: 0, B: 0, Del: 0, Ins: 8}, {A: 8, B: 16, Del: 8, Ins: 0}}},
+		// family of three vs family of four joined by ZWJ
+		{"zwj", "a\U0001F468\u200D\U0001F469\u200D\U0001F467b", "a\U0001F468\u200D\U0001F469\u200D\U0001F467\u200D\U0001F466b",
+			[]diff.Change{{A: 1, B: 1, Del: 18, Ins: 25}}},
+		// Hangul syllables from conjoining jamo
+		{"hangul", "\u1112\u1161\u11AB", "\u1112\u1161\u11AF", []diff.Change{{A: 0, B: 0, Del: 9, Ins: 9}}},
+		{"crlf", "a\r\nb", "a\nb", []diff.Change{{A: 1, B: 1, Del: 2, Ins: 1}}},
+	}
+	for _, test := range tests {
+		res := diff.Graphemes(test.a, test.b)
+		if !diffsEqual(res, test.res) {
+			t.Error(test.name, "expected", test.res, "got", res)
+		}
+	}
+}
+
+func TestGraphemesRunes(t *testing.T) {
+	// Runes splits the combining mark from its base
+	a, b := "cafe\u0301", "cafe\u0300"
+	if res := diff.Runes([]rune(a), []rune(b)); res[0].A != 4 {
+		t.Error("expected Runes to only change the mark, got", res)
+	}
+}
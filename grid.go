@@ -0,0 +1,156 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "slices"
+
+// A GridDiff holds the differences of two grids of cells, like two
+// spreadsheets, as found by Grid.
+type GridDiff[T any] struct {
+	Rows    []Change // the changes of the rows of a to the rows of b
+	Columns []Change // the changes of the columns of a to the columns of b
+	// Cells holds the changed cells of the rows that were replaced,
+	// in the columns that are in both grids.
+	Cells []CellChange[T]
+}
+
+// A CellChange is a cell whose value changed from Old to New. It is in
+// row RowA and column ColA of a and row RowB and column ColB of b.
+type CellChange[T any] struct {
+	RowA, ColA int
+	RowB, ColB int
+	Old, New   T
+}
+
+// Grid returns the differences of two grids given as slices of rows.
+// Rows may have different lengths, missing cells differ from every value.
+//
+// The columns are aligned first by diffing them, where two columns are
+// equal if they agree in at least half of the rows paired by a diff of the
+// whole rows. Then the rows are diffed comparing only the aligned columns,
+// and the deleted and inserted rows of every change are paired by position
+// to report their changed cells. Columns only in one grid are reported by
+// Columns but not per cell.
+func Grid[T comparable](a, b [][]T) GridDiff[T] {
+	var res GridDiff[T]
+	pairs := rowPairs(Diff(len(a), len(b), &rowData[T]{a, b}))
+	res.Columns = Diff(width(a), width(b), &columnData[T]{a, b, pairs})
+	colsA, _ := Alignment(width(a), width(b), res.Columns)
+	res.Rows = Diff(len(a), len(b), &alignedRows[T]{a, b, colsA})
+	for _, c := range res.Rows {
+		if c.Del == 0 || c.Ins == 0 {
+			continue
+		}
+		similar := &similarRows[T]{a[c.A : c.A+c.Del], b[c.B : c.B+c.Ins], colsA}
+		for _, p := range commonPairs(c.Del, c.Ins, Diff(c.Del, c.Ins, similar)) {
+			ra, rb := a[c.A+p[0]], b[c.B+p[1]]
+			for x, y := range colsA {
+				if y < 0 || x >= len(ra) || y >= len(rb) || ra[x] == rb[y] {
+					continue
+				}
+				res.Cells = append(res.Cells, CellChange[T]{RowA: c.A + p[0], ColA: x, RowB: c.B + p[1], ColB: y, Old: ra[x], New: rb[y]})
+			}
+		}
+	}
+	return res
+}
+
+// rowPairs returns the common rows and the deleted and inserted rows of
+// every change paired by position.
+func rowPairs(changes []Change) [][2]int {
+	var pairs [][2]int
+	x, y := 0, 0
+	for _, c := range changes {
+		for ; x < c.A; x, y = x+1, y+1 {
+			pairs = append(pairs, [2]int{x, y})
+		}
+		for i := 0; i < min(c.Del, c.Ins); i++ {
+			pairs = append(pairs, [2]int{c.A + i, c.B + i})
+		}
+		x, y = c.A+c.Del, c.B+c.Ins
+	}
+	return pairs
+}
+
+// commonPairs returns the pairs of common elements of sequences with
+// lengths n and m given the changes between them.
+func commonPairs(n, m int, changes []Change) [][2]int {
+	ab, _ := Alignment(n, m, changes)
+	var pairs [][2]int
+	for x, y := range ab {
+		if y >= 0 {
+			pairs = append(pairs, [2]int{x, y})
+		}
+	}
+	return pairs
+}
+
+func width[T any](rows [][]T) int {
+	w := 0
+	for _, r := range rows {
+		w = max(w, len(r))
+	}
+	return w
+}
+
+type rowData[T comparable] struct{ a, b [][]T }
+
+func (d *rowData[T]) Equal(i, j int) bool { return slices.Equal(d.a[i], d.b[j]) }
+
+type columnData[T comparable] struct {
+	a, b  [][]T
+	pairs [][2]int
+}
+
+func (d *columnData[T]) Equal(i, j int) bool {
+	same := 0
+	for _, p := range d.pairs {
+		ra, rb := d.a[p[0]], d.b[p[1]]
+		if i < len(ra) && j < len(rb) && ra[i] == rb[j] {
+			same++
+		}
+	}
+	return 2*same >= len(d.pairs)
+}
+
+// alignedRows compares rows in the columns of a that are aligned with b.
+type alignedRows[T comparable] struct {
+	a, b [][]T
+	cols []int
+}
+
+func (d *alignedRows[T]) Equal(i, j int) bool {
+	ra, rb := d.a[i], d.b[j]
+	for x, y := range d.cols {
+		if y < 0 {
+			continue
+		}
+		if (x < len(ra)) != (y < len(rb)) || x < len(ra) && ra[x] != rb[y] {
+			return false
+		}
+	}
+	return true
+}
+
+// similarRows compares rows by the share of equal cells in the aligned columns.
+type similarRows[T comparable] struct {
+	a, b [][]T
+	cols []int
+}
+
+func (d *similarRows[T]) Equal(i, j int) bool {
+	ra, rb := d.a[i], d.b[j]
+	same, total := 0, 0
+	for x, y := range d.cols {
+		if y < 0 {
+			continue
+		}
+		total++
+		if x < len(ra) && y < len(rb) && ra[x] == rb[y] {
+			same++
+		}
+	}
+	return 2*same >= total
+}
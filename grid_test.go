@@ -0,0 +1,61 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestGrid(t *testing.T) {
+	a := [][]string{
+		{"name", "qty", "price"},
+		{"apple", "3", "1.00"},
+		{"pear", "5", "0.80"},
+		{"plum", "7", "0.20"},
+	}
+	// a column and a row are inserted, a row is removed and a cell changed
+	b := [][]string{
+		{"name", "color", "qty", "price"},
+		{"apple", "red", "3", "1.00"},
+		{"kiwi", "brown", "2", "0.50"},
+		{"pear", "green", "6", "0.80"},
+	}
+	res := diff.Grid(a, b)
+	if expect := []diff.Change{{A: 1, B: 1, Del: 0, Ins: 1}}; !diffsEqual(res.Columns, expect) {
+		t.Error("expected columns", expect, "got", res.Columns)
+	}
+	if expect := []diff.Change{{A: 2, B: 2, Del: 2, Ins: 2}}; !diffsEqual(res.Rows, expect) {
+		t.Error("expected rows", expect, "got", res.Rows)
+	}
+	// kiwi and plum have nothing in common, pear changed its quantity
+	expect := []diff.CellChange[string]{{RowA: 2, ColA: 1, RowB: 3, ColB: 2, Old: "5", New: "6"}}
+	if len(res.Cells) != len(expect) {
+		t.Fatal("expected cells", expect, "got", res.Cells)
+	}
+	for i := range expect {
+		if res.Cells[i] != expect[i] {
+			t.Error("expected", expect[i], "got", res.Cells[i])
+		}
+	}
+}
+
+func TestGridRagged(t *testing.T) {
+	a := [][]int{{1, 2}, {3}}
+	b := [][]int{{1, 2, 3}, {3, 4}}
+	res := diff.Grid(a, b)
+	if expect := []diff.Change{{A: 2, B: 2, Del: 0, Ins: 1}}; !diffsEqual(res.Columns, expect) {
+		t.Error("expected columns", expect, "got", res.Columns)
+	}
+	// the second row gained a cell in an aligned column
+	expect := []diff.Change{{A: 1, B: 1, Del: 1, Ins: 1}}
+	if !diffsEqual(res.Rows, expect) || len(res.Cells) != 0 {
+		t.Error("expected rows", expect, "and no cells, got", res.Rows, res.Cells)
+	}
+	if res := diff.Grid[int](nil, nil); res.Rows != nil || res.Columns != nil || res.Cells != nil {
+		t.Error("expected no differences, got", res)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// A Hasher is Data that can hash its elements. Elements with different
+// hashes are never equal, so when data implements Hasher the hashes are
+// computed once per element and compared before calling Equal. This pays
+// off when Equal is expensive, like for large structs.
+// Equal elements may share a hash with unequal ones, except when the data
+// is diffed with WithCache: the cache key is computed from the hashes
+// alone, so they must then identify the content of the elements.
+type Hasher interface {
+	// HashA returns the hash of the element at i in a.
+	HashA(i int) uint64
+	// HashB returns the hash of the element at j in b.
+	HashB(j int) uint64
+}
+
+// hashed wraps data and compares the hashes of elements before calling Equal.
+type hashed struct {
+	data Data
+	a, b []uint64
+}
+
+func (h *hashed) Equal(i, j int) bool { return h.a[i] == h.b[j] && h.data.Equal(i, j) }
+
+// reset hashes the n elements of a and m elements of b, reusing the buffers of h.
+func (h *hashed) reset(n, m int, data Data, hasher Hasher) {
+	h.data = data
+	h.a = grow(h.a, n)
+	h.b = grow(h.b, m)
+	for i := range h.a {
+		h.a[i] = hasher.HashA(i)
+	}
+	for j := range h.b {
+		h.b[j] = hasher.HashB(j)
+	}
+}
+
+// grow returns s with length n, reallocating it only if it is too small.
+func grow(s []uint64, n int) []uint64 {
+	if cap(s) < n {
+		return make([]uint64, n)
+	}
+	return s[:n]
+}
@@ -0,0 +1,58 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+// countingInts counts the calls of Equal.
+type countingInts struct {
+	ints
+	calls int
+}
+
+func (d *countingInts) Equal(i, j int) bool {
+	d.calls++
+	return d.a[i] == d.b[j]
+}
+
+// hashedInts hashes the ints by their value.
+type hashedInts struct{ countingInts }
+
+func (d *hashedInts) HashA(i int) uint64 { return uint64(d.a[i]) }
+func (d *hashedInts) HashB(j int) uint64 { return uint64(d.b[j]) }
+
+func TestHasher(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		a := randomInts(r, r.Intn(50), 8)
+		b := randomInts(r, r.Intn(50), 8)
+		plain := &countingInts{ints: ints{a, b}}
+		hashed := &hashedInts{countingInts{ints: ints{a, b}}}
+		expect := diff.Diff(len(a), len(b), plain)
+		res := diff.Diff(len(a), len(b), hashed)
+		if !diffsEqual(res, expect) {
+			t.Fatal(a, b, "expected", expect, "got", res)
+		}
+		if hashed.calls > plain.calls {
+			t.Fatal(a, b, "hashing made", hashed.calls, "calls of Equal instead of", plain.calls)
+		}
+	}
+
+	// with perfect hashes Equal is only called for equal elements
+	a := []int{1, 2, 3, 4, 5}
+	b := []int{6, 7, 8, 9}
+	d := &hashedInts{countingInts{ints: ints{a, b}}}
+	if res := diff.Diff(len(a), len(b), d); len(res) != 1 {
+		t.Error("expected one change, got", res)
+	}
+	if d.calls != 0 {
+		t.Error("expected no calls of Equal, got", d.calls)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package httpdiff compares two HTTP responses, like those of two
+// deployments of a service.
+package httpdiff
+
+import (
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/echlebek/diff/typediff"
+)
+
+// A HeaderChange is a header that differs between two responses.
+// From is nil for added headers and To for removed ones.
+type HeaderChange struct {
+	Name     string // the canonical name
+	From, To []string
+}
+
+// A Report holds the differences of two responses.
+type Report struct {
+	// StatusA and StatusB are the status codes of the responses.
+	StatusA, StatusB int
+	// Headers holds the changed headers ordered by name.
+	Headers []HeaderChange
+	// Body holds the differences of the bodies as written by the handler
+	// of their content type, empty if they are equal.
+	Body string
+}
+
+// Equal reports whether the responses did not differ.
+func (r *Report) Equal() bool {
+	return r.StatusA == r.StatusB && len(r.Headers) == 0 && r.Body == ""
+}
+
+// Options configure Compare.
+type Options struct {
+	// Registry picks the handler for the bodies,
+	// typediff.NewRegistry if nil.
+	Registry *typediff.Registry
+	// IgnoreHeaders are the names of headers that are not compared,
+	// like Date. Their case does not matter.
+	IgnoreHeaders []string
+}
+
+// Compare reads the bodies of a and b and returns their differences.
+// Headers are compared by canonical name and their values in order.
+// The bodies are diffed by the handler of the Content-Type of b, or of
+// the type sniffed from its body if it has none. opts may be nil.
+func Compare(a, b *http.Response, opts *Options) (*Report, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Registry == nil {
+		o.Registry = typediff.NewRegistry()
+	}
+	ignore := make(map[string]bool)
+	for _, name := range o.IgnoreHeaders {
+		ignore[http.CanonicalHeaderKey(name)] = true
+	}
+	r := &Report{StatusA: a.StatusCode, StatusB: b.StatusCode}
+	ha, hb := canonical(a.Header), canonical(b.Header)
+	names := make(map[string]bool)
+	for name := range ha {
+		names[name] = true
+	}
+	for name := range hb {
+		names[name] = true
+	}
+	for name := range names {
+		if ignore[name] || equal(ha[name], hb[name]) {
+			continue
+		}
+		r.Headers = append(r.Headers, HeaderChange{Name: name, From: ha[name], To: hb[name]})
+	}
+	sort.Slice(r.Headers, func(i, j int) bool { return r.Headers[i].Name < r.Headers[j].Name })
+
+	ba, err := io.ReadAll(a.Body)
+	if err != nil {
+		return nil, err
+	}
+	bb, err := io.ReadAll(b.Body)
+	if err != nil {
+		return nil, err
+	}
+	t := http.DetectContentType(bb)
+	if v := hb["Content-Type"]; len(v) > 0 {
+		t = v[0]
+	}
+	var sb strings.Builder
+	if err := o.Registry.Handler(t, bb)(&sb, "a", "b", ba, bb); err != nil {
+		return nil, err
+	}
+	r.Body = sb.String()
+	return r, nil
+}
+
+// canonical returns h with canonical names, joining the values of names
+// that only differ in case.
+func canonical(h http.Header) map[string][]string {
+	res := make(map[string][]string, len(h))
+	for name, values := range h {
+		name = http.CanonicalHeaderKey(name)
+		res[name] = append(res[name], values...)
+	}
+	return res
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Write writes the report like a diff, starting with the changed status
+// and headers prefixed with "-" and "+", followed by the differences of
+// the bodies:
+//
+//	-HTTP 200
+//	+HTTP 503
+//	-Retry-After: 10
+//	+Retry-After: 20
+func (r *Report) Write(w io.Writer) error {
+	var sb strings.Builder
+	if r.StatusA != r.StatusB {
+		sb.WriteString("-HTTP " + strconv.Itoa(r.StatusA) + "\n")
+		sb.WriteString("+HTTP " + strconv.Itoa(r.StatusB) + "\n")
+	}
+	for _, h := range r.Headers {
+		for _, v := range h.From {
+			sb.WriteString("-" + h.Name + ": " + v + "\n")
+		}
+		for _, v := range h.To {
+			sb.WriteString("+" + h.Name + ": " + v + "\n")
+		}
+	}
+	sb.WriteString(r.Body)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
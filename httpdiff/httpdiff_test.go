@@ -0,0 +1,68 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpdiff_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff/httpdiff"
+)
+
+func response(status int, header http.Header, body string) *http.Response {
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+func TestCompare(t *testing.T) {
+	a := response(200, http.Header{
+		"Content-Type": {"application/json"},
+		"Date":         {"Mon, 01 Jan 2024 00:00:00 GMT"},
+		"X-Version":    {"1"},
+		"X-Old":        {"gone"},
+	}, `{"status": "ok", "items": [1, 2]}`)
+	b := response(503, http.Header{
+		"Content-Type": {"application/json"},
+		"Date":         {"Tue, 02 Jan 2024 00:00:00 GMT"},
+		"x-version":    {"2"},
+	}, `{"status": "down", "items": [1, 2]}`)
+	r, err := httpdiff.Compare(a, b, &httpdiff.Options{IgnoreHeaders: []string{"date"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Equal() {
+		t.Error("expected differences")
+	}
+	var sb strings.Builder
+	if err := r.Write(&sb); err != nil {
+		t.Fatal(err)
+	}
+	expect := "-HTTP 200\n+HTTP 503\n-X-Old: gone\n-X-Version: 1\n+X-Version: 2\n--- a\n+++ b\nM\tstatus\n"
+	if sb.String() != expect {
+		t.Errorf("expected %q, got %q", expect, sb.String())
+	}
+}
+
+func TestCompareEqual(t *testing.T) {
+	a := response(200, http.Header{"Content-Type": {"text/plain"}}, "hello\n")
+	b := response(200, http.Header{"content-type": {"text/plain"}}, "hello\n")
+	r, err := httpdiff.Compare(a, b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Equal() {
+		t.Error("expected equal responses, got", r)
+	}
+	// without a content type the body is sniffed
+	a = response(200, nil, "one\ntwo\n")
+	b = response(200, nil, "one\n2\n")
+	if r, err = httpdiff.Compare(a, b, nil); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "--- a\n+++ b\n@@ -1,2 +1,2 @@\n one\n-two\n+2\n"; r.Body != expect {
+		t.Errorf("expected %q, got %q", expect, r.Body)
+	}
+}
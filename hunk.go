@@ -0,0 +1,57 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// A Hunk is a group of nearby changes together with the common elements
+// around them, as shown by the context and unified diff formats.
+// It is encoded in JSON with the field names a, b, lenA, lenB, changes,
+// lines and section, and as text in unified format.
+type Hunk struct {
+	A       int      `json:"a"`    // position of the hunk in a
+	B       int      `json:"b"`    // position of the hunk in b
+	LenA    int      `json:"lenA"` // number of elements of a in the hunk
+	LenB    int      `json:"lenB"` // number of elements of b in the hunk
+	Changes []Change `json:"changes"`
+	// Lines holds the lines of a line hunk in unified format, prefixed with
+	// ' ' if common, '-' if deleted or '+' if inserted. Lines that lack a
+	// newline are at the end of their file. It is only set for hunks of
+	// patches, see NewFilePatch and ParsePatch.
+	Lines []string `json:"lines,omitempty"`
+	// Section is shown after the range of a unified hunk header,
+	// usually the function around the hunk. See Sections.
+	Section string `json:"section,omitempty"`
+}
+
+// Hunks groups changes of sequences with lengths n and m into hunks with up
+// to context common elements before and after every change. Changes with
+// at most 2*context common elements in between share a hunk.
+// The changes must be ordered by ascending positions.
+func Hunks(n, m int, changes []Change, context int) []Hunk {
+	if context < 0 {
+		context = 0
+	}
+	var hunks []Hunk
+	for i := 0; i < len(changes); {
+		j := i + 1
+		for j < len(changes) && changes[j].A-(changes[j-1].A+changes[j-1].Del) <= 2*context {
+			j++
+		}
+		first, last := changes[i], changes[j-1]
+		before := first.A
+		if before > context {
+			before = context
+		}
+		after := n - (last.A + last.Del)
+		if after > context {
+			after = context
+		}
+		h := Hunk{A: first.A - before, B: first.B - before, Changes: changes[i:j]}
+		h.LenA = last.A + last.Del + after - h.A
+		h.LenB = last.B + last.Ins + after - h.B
+		hunks = append(hunks, h)
+		i = j
+	}
+	return hunks
+}
@@ -0,0 +1,55 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"io"
+	"strings"
+)
+
+// WriteIfdef writes a merged document of lines a and b like diff -D name.
+// Common lines are written once and changed regions are wrapped in
+// C preprocessor conditionals, so that the output compiles to b if name is
+// defined and to a otherwise.
+func WriteIfdef(w io.Writer, name string, a, b []string, changes []Change) error {
+	var sb strings.Builder
+	block := func(lines []string) {
+		for _, line := range lines {
+			sb.WriteString(line)
+			if !strings.HasSuffix(line, "\n") {
+				sb.WriteByte('\n')
+			}
+		}
+	}
+	x := 0
+	for _, c := range changes {
+		for _, line := range a[x:c.A] {
+			sb.WriteString(line)
+		}
+		switch {
+		case c.Del == 0 && c.Ins == 0:
+		case c.Del == 0:
+			sb.WriteString("#ifdef " + name + "\n")
+			block(b[c.B : c.B+c.Ins])
+			sb.WriteString("#endif /* " + name + " */\n")
+		case c.Ins == 0:
+			sb.WriteString("#ifndef " + name + "\n")
+			block(a[c.A : c.A+c.Del])
+			sb.WriteString("#endif /* ! " + name + " */\n")
+		default:
+			sb.WriteString("#ifndef " + name + "\n")
+			block(a[c.A : c.A+c.Del])
+			sb.WriteString("#else /* " + name + " */\n")
+			block(b[c.B : c.B+c.Ins])
+			sb.WriteString("#endif /* " + name + " */\n")
+		}
+		x = c.A + c.Del
+	}
+	for _, line := range a[x:] {
+		sb.WriteString(line)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
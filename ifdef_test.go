@@ -0,0 +1,50 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestWriteIfdef(t *testing.T) {
+	a := splitLines("a\nb\nc\nd\ne\n")
+	b := splitLines("x\na\nB\nc\ne\n")
+	var sb strings.Builder
+	if err := diff.WriteIfdef(&sb, "FOO", a, b, diff.Lines(a, b)); err != nil {
+		t.Fatal(err)
+	}
+	// the same as GNU diff -DFOO
+	expect := "" +
+		"#ifdef FOO\n" +
+		"x\n" +
+		"#endif /* FOO */\n" +
+		"a\n" +
+		"#ifndef FOO\n" +
+		"b\n" +
+		"#else /* FOO */\n" +
+		"B\n" +
+		"#endif /* FOO */\n" +
+		"c\n" +
+		"#ifndef FOO\n" +
+		"d\n" +
+		"#endif /* ! FOO */\n" +
+		"e\n"
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+
+	sb.Reset()
+	b = append(splitLines("a\n"), "b")
+	if err := diff.WriteIfdef(&sb, "FOO", a, b, diff.Lines(a, b)); err != nil {
+		t.Fatal(err)
+	}
+	expect = "a\n#ifndef FOO\nb\nc\nd\ne\n#else /* FOO */\nb\n#endif /* FOO */\n"
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "regexp"
+
+// IgnoreMatchingLines drops the changes of lines a to b whose deleted and
+// inserted lines all match at least one of the regular expressions,
+// like GNU diff's -I option. Renderers then leave them out of the output.
+// The changes are filtered in place.
+func IgnoreMatchingLines(a, b []string, changes []Change, res ...*regexp.Regexp) []Change {
+	matches := func(lines []string) bool {
+	next:
+		for _, line := range lines {
+			for _, re := range res {
+				if re.MatchString(line) {
+					continue next
+				}
+			}
+			return false
+		}
+		return true
+	}
+	kept := changes[:0]
+	for _, c := range changes {
+		if len(res) > 0 && matches(a[c.A:c.A+c.Del]) && matches(b[c.B:c.B+c.Ins]) {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	return kept
+}
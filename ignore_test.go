@@ -0,0 +1,35 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestIgnoreMatchingLines(t *testing.T) {
+	a := []string{"# generated 2012-01-01\n", "name: a\n", "id: 17\n", "size: 1\n"}
+	b := []string{"# generated 2013-02-02\n", "name: b\n", "id: 42\n", "size: 1\n", "# end\n"}
+	changes := diff.Lines(a, b)
+	if len(changes) != 2 {
+		t.Fatal("unexpected changes", changes)
+	}
+	comments := regexp.MustCompile(`^#`)
+	ids := regexp.MustCompile(`^(id|name): `)
+
+	// the first change also touches the name and id lines
+	expect := []diff.Change{{A: 0, B: 0, Del: 3, Ins: 3}}
+	if res := diff.IgnoreMatchingLines(a, b, append([]diff.Change(nil), changes...), comments); !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	if res := diff.IgnoreMatchingLines(a, b, append([]diff.Change(nil), changes...), comments, ids); len(res) != 0 {
+		t.Error("expected no changes, got", res)
+	}
+	if res := diff.IgnoreMatchingLines(a, b, append([]diff.Change(nil), changes...)); !diffsEqual(res, changes) {
+		t.Error("expected", changes, "got", res)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package imagediff compares two images, like screenshots in tests.
+package imagediff
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/echlebek/diff"
+)
+
+// A Region is an area that differs between two images, with its bounds
+// in a and in b. The bounds of inserted rows are empty in a and the bounds
+// of deleted rows are empty in b.
+type Region struct {
+	A, B image.Rectangle
+}
+
+// Compare returns the regions that differ between a and b. The images
+// are diffed as sequences of rows, so that rows inserted or deleted in b
+// don't change all the rows below. Rows are compared pixel by pixel, and
+// the pixels of changed rows are diffed again to narrow down the regions.
+// Two pixels are equal if none of their 8 bit RGBA channels differ by more
+// than tolerance. Rows of images with different widths always differ.
+func Compare(a, b image.Image, tolerance int) []Region {
+	pa, pb := pixels(a), pixels(b)
+	ra, rb := a.Bounds(), b.Bounds()
+	var res []Region
+	for _, c := range diff.Diff(len(pa), len(pb), &rows{pa, pb, tolerance}) {
+		// the columns changed in the rows of a and b
+		xa, xb := span{0, ra.Dx()}, span{0, rb.Dx()}
+		if c.Del == c.Ins {
+			xa, xb = span{ra.Dx(), 0}, span{rb.Dx(), 0}
+			for k := 0; k < c.Del; k++ {
+				row := &pixelRow{pa[c.A+k], pb[c.B+k], tolerance}
+				for _, p := range diff.Diff(len(row.a), len(row.b), row) {
+					xa.add(p.A, p.A+p.Del)
+					xb.add(p.B, p.B+p.Ins)
+				}
+			}
+		}
+		res = append(res, Region{
+			A: image.Rect(xa.start, c.A, xa.end, c.A+c.Del).Add(ra.Min),
+			B: image.Rect(xb.start, c.B, xb.end, c.B+c.Ins).Add(rb.Min),
+		})
+	}
+	return res
+}
+
+// span is a range of columns that grows to cover the ranges added to it.
+type span struct{ start, end int }
+
+func (s *span) add(start, end int) {
+	if start == end {
+		return
+	}
+	s.start, s.end = min(s.start, start), max(s.end, end)
+}
+
+func pixels(m image.Image) [][]color.RGBA {
+	r := m.Bounds()
+	res := make([][]color.RGBA, r.Dy())
+	for y := range res {
+		row := make([]color.RGBA, r.Dx())
+		for x := range row {
+			row[x] = color.RGBAModel.Convert(m.At(r.Min.X+x, r.Min.Y+y)).(color.RGBA)
+		}
+		res[y] = row
+	}
+	return res
+}
+
+func equal(p, q color.RGBA, tolerance int) bool {
+	return near(p.R, q.R, tolerance) && near(p.G, q.G, tolerance) &&
+		near(p.B, q.B, tolerance) && near(p.A, q.A, tolerance)
+}
+
+func near(x, y uint8, tolerance int) bool {
+	d := int(x) - int(y)
+	return -tolerance <= d && d <= tolerance
+}
+
+type rows struct {
+	a, b      [][]color.RGBA
+	tolerance int
+}
+
+func (d *rows) Equal(i, j int) bool {
+	ra, rb := d.a[i], d.b[j]
+	if len(ra) != len(rb) {
+		return false
+	}
+	for x := range ra {
+		if !equal(ra[x], rb[x], d.tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+type pixelRow struct {
+	a, b      []color.RGBA
+	tolerance int
+}
+
+func (d *pixelRow) Equal(i, j int) bool { return equal(d.a[i], d.b[j], d.tolerance) }
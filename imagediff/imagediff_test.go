@@ -0,0 +1,59 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package imagediff_test
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/echlebek/diff/imagediff"
+)
+
+func filled(r image.Rectangle, c color.Color) *image.RGBA {
+	m := image.NewRGBA(r)
+	draw.Draw(m, r, image.NewUniform(c), image.Point{}, draw.Src)
+	return m
+}
+
+func TestCompare(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	a := filled(image.Rect(0, 0, 10, 10), white)
+	b := filled(image.Rect(0, 0, 10, 10), white)
+	if res := imagediff.Compare(a, b, 0); len(res) != 0 {
+		t.Error("expected no regions, got", res)
+	}
+	// a box is drawn and some noise is added
+	box := image.Rect(2, 3, 5, 6)
+	draw.Draw(b, box, image.NewUniform(color.Black), image.Point{}, draw.Src)
+	b.Set(8, 8, color.RGBA{250, 252, 255, 255})
+	res := imagediff.Compare(a, b, 8)
+	if expect := (imagediff.Region{A: box, B: box}); len(res) != 1 || res[0] != expect {
+		t.Error("expected", expect, "got", res)
+	}
+	if res := imagediff.Compare(a, b, 0); len(res) != 2 {
+		t.Error("expected the noise to differ without tolerance, got", res)
+	}
+}
+
+func TestCompareInsertedRows(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	a := filled(image.Rect(0, 0, 4, 6), white)
+	for x := 0; x < 4; x++ {
+		a.Set(x, 0, color.Black)
+		a.Set(x, 5, color.Black)
+	}
+	// two red rows are inserted after the third row
+	b := image.NewRGBA(image.Rect(0, 10, 4, 18))
+	draw.Draw(b, image.Rect(0, 10, 4, 13), a, image.Point{}, draw.Src)
+	draw.Draw(b, image.Rect(0, 13, 4, 15), image.NewUniform(color.RGBA{255, 0, 0, 255}), image.Point{}, draw.Src)
+	draw.Draw(b, image.Rect(0, 15, 4, 18), a, image.Pt(0, 3), draw.Src)
+	res := imagediff.Compare(a, b, 0)
+	expect := imagediff.Region{A: image.Rect(0, 3, 4, 3), B: image.Rect(0, 13, 4, 15)}
+	if len(res) != 1 || res[0] != expect {
+		t.Error("expected", expect, "got", res)
+	}
+}
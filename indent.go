@@ -0,0 +1,225 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+type stringSlices struct{ a, b []string }
+
+func (d *stringSlices) Equal(i, j int) bool { return d.a[i] == d.b[j] }
+
+// IndentHeuristic slides changes of lines that only insert or only delete
+// to the position git's indent heuristic considers the most natural,
+// preferring boundaries at blank lines and lower indentation.
+// The changes must be ordered by ascending positions and are modified in place.
+func IndentHeuristic(a, b []string, changes []Change) []Change {
+	data := &stringSlices{a, b}
+	for i := range changes {
+		c := &changes[i]
+		var lines []string
+		var pos, size int
+		switch {
+		case c.Del > 0 && c.Ins == 0:
+			lines, pos, size = a, c.A, c.Del
+		case c.Ins > 0 && c.Del == 0:
+			lines, pos, size = b, c.B, c.Ins
+		default:
+			continue
+		}
+		up, down := slideRange(len(a), data, changes, i)
+		if up == 0 && down == 0 {
+			continue
+		}
+		earliest, latest := pos-up+size, pos+down+size
+		shift := earliest
+		if latest-size-1 > shift {
+			shift = latest - size - 1
+		}
+		if latest-maxSliding > shift {
+			shift = latest - maxSliding
+		}
+		best := -1
+		var bestScore splitScore
+		for ; shift <= latest; shift++ {
+			var score splitScore
+			score.add(measureSplit(lines, shift))
+			score.add(measureSplit(lines, shift-size))
+			if best == -1 || score.cmp(bestScore) <= 0 {
+				best, bestScore = shift, score
+			}
+		}
+		d := best - size - pos
+		c.A += d
+		c.B += d
+	}
+	return changes
+}
+
+// slideRange returns how far changes[i] can slide up and down
+// without touching its neighbors. Only pure insertions and deletions slide.
+func slideRange(n int, data Data, changes []Change, i int) (up, down int) {
+	c := changes[i]
+	if c.Del > 0 && c.Ins > 0 || c.Del == 0 && c.Ins == 0 {
+		return 0, 0
+	}
+	// keep at least one common element towards neighbors
+	lo, hi := 0, n
+	if i > 0 {
+		lo = changes[i-1].A + changes[i-1].Del + 1
+	}
+	if i+1 < len(changes) {
+		hi = changes[i+1].A - 1
+	}
+	// sliding up: the last changed element equals the common one before it
+	for c.A-up > lo && data.Equal(c.A+c.Del-1-up, c.B+c.Ins-1-up) {
+		up++
+	}
+	// sliding down: the first changed element equals the common one after it
+	for c.A+c.Del+down < hi && data.Equal(c.A+down, c.B+down) {
+		down++
+	}
+	return up, down
+}
+
+const (
+	maxIndent  = 200
+	maxBlanks  = 20
+	maxSliding = 100
+
+	startOfFilePenalty              = 1
+	endOfFilePenalty                = 21
+	totalBlankWeight                = -30
+	postBlankWeight                 = 6
+	relativeIndentPenalty           = -4
+	relativeIndentWithBlankPenalty  = 10
+	relativeOutdentPenalty          = 24
+	relativeOutdentWithBlankPenalty = 17
+	relativeDedentPenalty           = 23
+	relativeDedentWithBlankPenalty  = 17
+	indentWeight                    = 60
+)
+
+// indent returns the indentation of line with tabs expanded to 8 columns,
+// or -1 if the line is blank.
+func indent(line string) int {
+	n := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ' ':
+			n++
+		case '\t':
+			n += 8 - n%8
+		case '\n', '\r', '\f', '\v':
+		default:
+			return n
+		}
+		if n >= maxIndent {
+			return maxIndent
+		}
+	}
+	return -1
+}
+
+type splitMeasurement struct {
+	endOfFile  bool
+	indent     int
+	preBlank   int
+	preIndent  int
+	postBlank  int
+	postIndent int
+}
+
+// measureSplit describes the surroundings of a split before lines[split].
+func measureSplit(lines []string, split int) (m splitMeasurement) {
+	if split >= len(lines) {
+		m.endOfFile = true
+		m.indent = -1
+	} else {
+		m.indent = indent(lines[split])
+	}
+	m.preIndent = -1
+	for i := split - 1; i >= 0; i-- {
+		m.preIndent = indent(lines[i])
+		if m.preIndent != -1 {
+			break
+		}
+		m.preBlank++
+		if m.preBlank == maxBlanks {
+			m.preIndent = 0
+			break
+		}
+	}
+	m.postIndent = -1
+	for i := split + 1; i < len(lines); i++ {
+		m.postIndent = indent(lines[i])
+		if m.postIndent != -1 {
+			break
+		}
+		m.postBlank++
+		if m.postBlank == maxBlanks {
+			m.postIndent = 0
+			break
+		}
+	}
+	return m
+}
+
+type splitScore struct {
+	effectiveIndent int
+	penalty         int
+}
+
+func (s *splitScore) add(m splitMeasurement) {
+	if m.preIndent == -1 && m.preBlank == 0 {
+		s.penalty += startOfFilePenalty
+	}
+	if m.endOfFile {
+		s.penalty += endOfFilePenalty
+	}
+	postBlank := 0
+	if m.indent == -1 {
+		postBlank = 1 + m.postBlank
+	}
+	totalBlank := m.preBlank + postBlank
+	s.penalty += totalBlankWeight * totalBlank
+	s.penalty += postBlankWeight * postBlank
+
+	indent := m.indent
+	if indent == -1 {
+		indent = m.postIndent
+	}
+	anyBlanks := totalBlank != 0
+	s.effectiveIndent += indent
+	switch {
+	case indent == -1, m.preIndent == -1, indent == m.preIndent:
+	case indent > m.preIndent:
+		if anyBlanks {
+			s.penalty += relativeIndentWithBlankPenalty
+		} else {
+			s.penalty += relativeIndentPenalty
+		}
+	case m.postIndent != -1 && m.postIndent > indent:
+		if anyBlanks {
+			s.penalty += relativeOutdentWithBlankPenalty
+		} else {
+			s.penalty += relativeOutdentPenalty
+		}
+	default:
+		if anyBlanks {
+			s.penalty += relativeDedentWithBlankPenalty
+		} else {
+			s.penalty += relativeDedentPenalty
+		}
+	}
+}
+
+// cmp returns a negative number if s is a better split than t.
+func (s splitScore) cmp(t splitScore) int {
+	c := 0
+	if s.effectiveIndent > t.effectiveIndent {
+		c = 1
+	} else if s.effectiveIndent < t.effectiveIndent {
+		c = -1
+	}
+	return indentWeight*c + s.penalty - t.penalty
+}
@@ -0,0 +1,69 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	return lines[:len(lines)-1]
+}
+
+func TestIndentHeuristic(t *testing.T) {
+	a := splitLines("foo() {\n}\n\nbar() {\n}\n")
+	b := splitLines("foo() {\n}\n\nbaz() {\n}\n\nbar() {\n}\n")
+	expect := []diff.Change{{A: 3, B: 3, Del: 0, Ins: 3}}
+	// all of these insert the same lines
+	for _, c := range []diff.Change{
+		{A: 2, B: 2, Del: 0, Ins: 3},
+		{A: 3, B: 3, Del: 0, Ins: 3},
+	} {
+		res := diff.IndentHeuristic(a, b, []diff.Change{c})
+		if !diffsEqual(res, expect) {
+			t.Error(c, "expected", expect, "got", res)
+		}
+		// and deleting them again
+		res = diff.IndentHeuristic(b, a, []diff.Change{{A: c.B, B: c.A, Del: c.Ins, Ins: c.Del}})
+		if expect := []diff.Change{{A: 3, B: 3, Del: 3, Ins: 0}}; !diffsEqual(res, expect) {
+			t.Error(c, "expected", expect, "got", res)
+		}
+	}
+}
+
+func TestIndentHeuristicNested(t *testing.T) {
+	a := splitLines("if a {\n\tx\n}\n")
+	b := splitLines("if a {\n\tif b {\n\t\ty\n\t}\n\tx\n}\n")
+	// the insertion can't slide, x differs from the inserted lines
+	res := diff.IndentHeuristic(a, b, []diff.Change{{A: 1, B: 1, Del: 0, Ins: 3}})
+	if expect := []diff.Change{{A: 1, B: 1, Del: 0, Ins: 3}}; !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+
+	a = splitLines("{\n\tx\n}\n")
+	b = splitLines("{\n\tx\n}\n{\n\tx\n}\n")
+	res = diff.IndentHeuristic(a, b, []diff.Change{{A: 1, B: 1, Del: 0, Ins: 3}})
+	if expect := []diff.Change{{A: 3, B: 3, Del: 0, Ins: 3}}; !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+}
+
+func TestIndentHeuristicNeighbors(t *testing.T) {
+	a := splitLines("a\nx\nx\nb\n")
+	b := splitLines("A\nx\nx\nx\nB\n")
+	// the insertion may not merge with the replacements around it
+	changes := []diff.Change{{A: 0, B: 0, Del: 1, Ins: 1}, {A: 1, B: 1, Del: 0, Ins: 1}, {A: 3, B: 4, Del: 1, Ins: 1}}
+	res := diff.IndentHeuristic(a, b, append([]diff.Change(nil), changes...))
+	if diff.Check(a, b, res) != nil {
+		t.Error("invalid result", res)
+	}
+	if res[1].A != 2 {
+		t.Error("expected insertion between the common lines, got", res)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// Invert returns the changes from b to a given the changes from a to b,
+// swapping A with B and Del with Ins. The order of the changes is kept.
+func Invert(changes []Change) []Change {
+	if changes == nil {
+		return nil
+	}
+	res := make([]Change, len(changes))
+	for i, c := range changes {
+		res[i] = Change{A: c.B, B: c.A, Del: c.Ins, Ins: c.Del}
+	}
+	return res
+}
+
+// Invert returns the hunk from b to a. Its lines are reordered so that
+// deleted lines come before inserted ones like in every hunk.
+func (h Hunk) Invert() Hunk {
+	res := Hunk{A: h.B, B: h.A, LenA: h.LenB, LenB: h.LenA, Changes: Invert(h.Changes), Section: h.Section}
+	if h.Lines == nil {
+		return res
+	}
+	res.Lines = make([]string, 0, len(h.Lines))
+	for i := 0; i < len(h.Lines); {
+		if h.Lines[i][0] == ' ' {
+			res.Lines = append(res.Lines, h.Lines[i])
+			i++
+			continue
+		}
+		// a run of changed lines
+		j := i
+		for j < len(h.Lines) && h.Lines[j][0] != ' ' {
+			j++
+		}
+		for _, line := range h.Lines[i:j] {
+			if line[0] == '+' {
+				res.Lines = append(res.Lines, "-"+line[1:])
+			}
+		}
+		for _, line := range h.Lines[i:j] {
+			if line[0] == '-' {
+				res.Lines = append(res.Lines, "+"+line[1:])
+			}
+		}
+		i = j
+	}
+	return res
+}
+
+// Invert returns the patch that undoes f, with its paths and modes swapped.
+// Applying it is the same as applying f with ApplyOptions.Reverse set.
+func (f FilePatch) Invert() FilePatch {
+	res := FilePatch{
+		OldPath: f.NewPath, NewPath: f.OldPath,
+		OldMode: f.NewMode, NewMode: f.OldMode,
+		Binary: f.Binary,
+	}
+	if f.Hunks != nil {
+		res.Hunks = make([]Hunk, len(f.Hunks))
+		for i, h := range f.Hunks {
+			res.Hunks[i] = h.Invert()
+		}
+	}
+	return res
+}
+
+// Invert returns the patch that undoes p.
+func (p Patch) Invert() Patch {
+	if p == nil {
+		return nil
+	}
+	res := make(Patch, len(p))
+	for i, f := range p {
+		res[i] = f.Invert()
+	}
+	return res
+}
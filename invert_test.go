@@ -0,0 +1,82 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestInvert(t *testing.T) {
+	if res := diff.Invert(nil); res != nil {
+		t.Error("expected nil, got", res)
+	}
+	if res := diff.Invert([]diff.Change{}); res == nil || len(res) != 0 {
+		t.Error("expected empty changes, got", res)
+	}
+	changes := []diff.Change{{A: 0, B: 0, Del: 0, Ins: 3}, {A: 4, B: 7, Del: 2, Ins: 0}}
+	expect := []diff.Change{{A: 0, B: 0, Del: 3, Ins: 0}, {A: 7, B: 4, Del: 0, Ins: 2}}
+	res := diff.Invert(changes)
+	if !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	if changes[0].Ins != 3 {
+		t.Error("input was modified", changes)
+	}
+	if res := diff.Invert(res); !diffsEqual(res, changes) {
+		t.Error("expected", changes, "got", res)
+	}
+	// inverted changes transform b into a
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		a, b := randomInts(r, r.Intn(30), 4), randomInts(r, r.Intn(30), 4)
+		if res := diff.Invert(diff.Ints(a, b)); diff.Check(b, a, res) != nil {
+			t.Fatal(a, b, "inverted changes", res, "do not transform b into a")
+		}
+	}
+}
+
+func TestInvertPatch(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 300; i++ {
+		a := randomLines(r, r.Intn(20), 5)
+		b := randomLines(r, r.Intn(20), 5)
+		// the inverted patch is what diffing the other way would produce
+		context := r.Intn(3)
+		f := diff.NewFilePatch("a", "b", a, b, diff.Lines(a, b), context)
+		expect := diff.NewFilePatch("b", "a", b, a, diff.Invert(diff.Lines(a, b)), context)
+		if inv := f.Invert(); !reflect.DeepEqual(inv, expect) {
+			t.Fatal(a, b, "expected", expect, "got", inv)
+		}
+		if back := f.Invert().Invert(); !reflect.DeepEqual(back, f) {
+			t.Fatal(a, b, "inverting twice changed", f, "to", back)
+		}
+		res, _, err := diff.Apply(b, f.Invert().Hunks, nil)
+		if err != nil || strings.Join(res, "") != strings.Join(a, "") {
+			t.Fatal(a, b, "inverted patch applied to", res, err)
+		}
+	}
+
+	p := diff.Patch{{OldPath: "x", NewPath: "", OldMode: 0o100644, Hunks: []diff.Hunk{{
+		A: 0, B: 0, LenA: 2, LenB: 0,
+		Changes: []diff.Change{{A: 0, B: 0, Del: 2}},
+		Lines:   []string{"-one\n", "-two"},
+	}}}}
+	var sb strings.Builder
+	if err := diff.WritePatch(&sb, p.Invert()); err != nil {
+		t.Fatal(err)
+	}
+	expect := "diff --git a/x b/x\nnew file mode 100644\n--- /dev/null\n+++ b/x\n@@ -0,0 +1,2 @@\n+one\n+two\n\\ No newline at end of file\n"
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+	if diff.Patch(nil).Invert() != nil {
+		t.Error("expected nil")
+	}
+}
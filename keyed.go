@@ -0,0 +1,151 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "sort"
+
+// A KeyedChange is a difference of two slices of records found by Keyed.
+// A is the index in a or -1 for added records and B is the index in b
+// or -1 for removed ones.
+type KeyedChange struct {
+	A, B int
+	// Moved is set if the record changed its position relative to
+	// the other records matched by key.
+	Moved bool
+	// Modified is set if the records with the same key differ.
+	Modified bool
+}
+
+// Added reports whether the record was added in b.
+func (c KeyedChange) Added() bool { return c.A == -1 }
+
+// Removed reports whether the record was removed from a.
+func (c KeyedChange) Removed() bool { return c.B == -1 }
+
+// Keyed returns the differences of two slices of records. Records with the
+// same key are matched first, eq tells if they were modified. Records whose
+// key is the zero value or not unique in its slice are unkeyed; they are
+// compared with eq by a sequence diff of the unkeyed records of a and b.
+// The changes are ordered by their index in b, removed records come
+// first in the order of a.
+func Keyed[T any, K comparable](a, b []T, key func(T) K, eq func(T, T) bool) []KeyedChange {
+	ka, kb := uniqueKeys(a, key), uniqueKeys(b, key)
+	var res []KeyedChange
+	// the matched records in the order of a and b
+	var ma, mb []int
+	var ua, ub []int
+	for i, t := range a {
+		if matched(ka, kb, key(t)) {
+			ma = append(ma, i)
+		} else {
+			ua = append(ua, i)
+		}
+	}
+	for j, t := range b {
+		if k := key(t); matched(ka, kb, k) {
+			mb = append(mb, ka[k])
+		} else {
+			ub = append(ub, j)
+		}
+	}
+	// records that are not part of the longest common order moved
+	moved := make(map[int]bool)
+	for _, c := range Ints(ma, mb) {
+		for _, i := range ma[c.A : c.A+c.Del] {
+			moved[i] = true
+		}
+	}
+	for _, i := range mb {
+		j := kb[key(a[i])]
+		c := KeyedChange{A: i, B: j, Moved: moved[i], Modified: !eq(a[i], b[j])}
+		if c.Moved || c.Modified {
+			res = append(res, c)
+		}
+	}
+	for _, c := range Diff(len(ua), len(ub), &keyedData[T]{a, b, ua, ub, eq}) {
+		for _, i := range ua[c.A : c.A+c.Del] {
+			res = append(res, KeyedChange{A: i, B: -1})
+		}
+		for _, j := range ub[c.B : c.B+c.Ins] {
+			res = append(res, KeyedChange{A: -1, B: j})
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		ci, cj := res[i], res[j]
+		if ci.B == -1 || cj.B == -1 {
+			return ci.B == -1 && (cj.B != -1 || ci.A < cj.A)
+		}
+		return ci.B < cj.B
+	})
+	return res
+}
+
+// uniqueKeys maps the non-zero keys that occur once in s to their index.
+func uniqueKeys[T any, K comparable](s []T, key func(T) K) map[K]int {
+	var zero K
+	keys := make(map[K]int, len(s))
+	for i, t := range s {
+		k := key(t)
+		if k == zero {
+			continue
+		}
+		if _, ok := keys[k]; ok {
+			keys[k] = -1
+			continue
+		}
+		keys[k] = i
+	}
+	for k, i := range keys {
+		if i == -1 {
+			delete(keys, k)
+		}
+	}
+	return keys
+}
+
+// matched reports whether the key k is unique in both slices.
+func matched[K comparable](ka, kb map[K]int, k K) bool {
+	_, inA := ka[k]
+	_, inB := kb[k]
+	return inA && inB
+}
+
+// keyedData compares the unkeyed records a[ua[i]] and b[ub[j]].
+type keyedData[T any] struct {
+	a, b   []T
+	ua, ub []int
+	eq     func(T, T) bool
+}
+
+func (d *keyedData[T]) Equal(i, j int) bool { return d.eq(d.a[d.ua[i]], d.b[d.ub[j]]) }
+
+// A KV is an entry of an ordered map.
+type KV[K, V comparable] struct {
+	Key   K
+	Value V
+}
+
+// OrderedMap returns the differences of two ordered maps, like the members
+// of JSON objects or HTTP headers, with Keyed. Entries with the same key
+// are matched, so that a moved entry is told apart from a modified one.
+// Keys that are zero or repeated, like the Set-Cookie header, are diffed
+// as a sequence of entries. If ignoreOrder is set, moved entries are only
+// reported if they were modified too, and Moved is never set.
+func OrderedMap[K, V comparable](a, b []KV[K, V], ignoreOrder bool) []KeyedChange {
+	res := Keyed(a, b,
+		func(e KV[K, V]) K { return e.Key },
+		func(x, y KV[K, V]) bool { return x == y })
+	if !ignoreOrder {
+		return res
+	}
+	kept := res[:0]
+	for _, c := range res {
+		c.Moved = false
+		if c.Added() || c.Removed() || c.Modified {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
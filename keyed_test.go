@@ -0,0 +1,89 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+type record struct {
+	ID   int
+	Name string
+}
+
+func recordID(r record) int         { return r.ID }
+func recordsEqual(a, b record) bool { return a == b }
+
+func TestKeyed(t *testing.T) {
+	a := []record{{1, "one"}, {2, "two"}, {3, "three"}, {4, "four"}, {0, "x"}, {0, "y"}}
+	b := []record{{2, "two"}, {3, "drei"}, {1, "one"}, {5, "five"}, {0, "x"}, {0, "z"}}
+	res := diff.Keyed(a, b, recordID, recordsEqual)
+	expect := []diff.KeyedChange{
+		{A: 3, B: -1},
+		{A: 5, B: -1},
+		{A: 2, B: 1, Modified: true},
+		{A: 0, B: 2, Moved: true},
+		{A: -1, B: 3},
+		{A: -1, B: 5},
+	}
+	if !reflect.DeepEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	if !res[0].Removed() || res[0].Added() || !res[4].Added() {
+		t.Error("unexpected kinds of", res)
+	}
+}
+
+func TestKeyedDuplicates(t *testing.T) {
+	// duplicate keys are diffed as unkeyed records
+	a := []record{{1, "a"}, {1, "b"}, {2, "c"}}
+	b := []record{{1, "b"}, {2, "C"}}
+	res := diff.Keyed(a, b, recordID, recordsEqual)
+	expect := []diff.KeyedChange{
+		{A: 0, B: -1},
+		{A: 2, B: 1, Modified: true},
+	}
+	if !reflect.DeepEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	if res := diff.Keyed(a, a, recordID, recordsEqual); len(res) != 0 {
+		t.Error("expected no changes, got", res)
+	}
+}
+
+func TestOrderedMap(t *testing.T) {
+	type kv = diff.KV[string, string]
+	a := []kv{{"name", "x"}, {"version", "1"}, {"deps", "y"}, {"old", "o"}}
+	b := []kv{{"version", "2"}, {"name", "x"}, {"deps", "y"}, {"new", "n"}}
+	res := diff.OrderedMap(a, b, false)
+	expect := []diff.KeyedChange{
+		{A: 3, B: -1},
+		{A: 1, B: 0, Moved: true, Modified: true},
+		{A: -1, B: 3},
+	}
+	if !reflect.DeepEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	res = diff.OrderedMap(a, b, true)
+	expect = []diff.KeyedChange{
+		{A: 3, B: -1},
+		{A: 1, B: 0, Modified: true},
+		{A: -1, B: 3},
+	}
+	if !reflect.DeepEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	ordered := []kv{{"name", "x"}, {"deps", "y"}}
+	swapped := []kv{{"deps", "y"}, {"name", "x"}}
+	if res := diff.OrderedMap(ordered, swapped, false); len(res) != 1 || !res[0].Moved {
+		t.Error("expected a moved entry, got", res)
+	}
+	if res := diff.OrderedMap(ordered, swapped, true); len(res) != 0 {
+		t.Error("expected no changes, got", res)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package kvdiff compares lists of keys and values, like environment
+// variables, Java properties and INI files.
+package kvdiff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A Status describes how a key differs between two lists.
+type Status int
+
+const (
+	Added    Status = iota + 1 // only in the second list
+	Removed                    // only in the first list
+	Modified                   // in both lists with different values
+)
+
+func (s Status) String() string {
+	switch s {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// A Change describes a key that differs between two lists.
+// From is its old and To its new value.
+type Change struct {
+	Key      string
+	Status   Status
+	From, To string
+}
+
+// Compare returns the keys that were added, removed or modified in b,
+// ordered by key.
+func Compare(a, b map[string]string) []Change {
+	var changes []Change
+	for k, v := range a {
+		if w, ok := b[k]; !ok {
+			changes = append(changes, Change{Key: k, Status: Removed, From: v})
+		} else if v != w {
+			changes = append(changes, Change{Key: k, Status: Modified, From: v, To: w})
+		}
+	}
+	for k, w := range b {
+		if _, ok := a[k]; !ok {
+			changes = append(changes, Change{Key: k, Status: Added, To: w})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// Environ returns the variables of an environment in the form of
+// os.Environ. Later variables replace earlier ones with the same name.
+func Environ(env []string) map[string]string {
+	res := make(map[string]string, len(env))
+	for _, kv := range env {
+		k, v, _ := strings.Cut(kv, "=")
+		res[k] = v
+	}
+	return res
+}
+
+// ParseEnv parses a file of environment variables like a .env file, with
+// lines like "KEY=value" or "export KEY=value". Values may be quoted with
+// single or double quotes; double quoted values may contain Go escapes.
+// Empty lines and lines starting with # are skipped.
+func ParseEnv(r io.Reader) (map[string]string, error) {
+	return parse(r, "#", "=", func(_, k, v string) (string, string, error) {
+		k = strings.TrimSpace(strings.TrimPrefix(k, "export "))
+		v = strings.TrimSpace(v)
+		switch {
+		case len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"':
+			s, err := strconv.Unquote(v)
+			if err != nil {
+				return "", "", err
+			}
+			v = s
+		case len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'':
+			v = v[1 : len(v)-1]
+		}
+		return k, v, nil
+	})
+}
+
+// ParseProperties parses a Java properties file with lines like
+// "key=value" or "key: value". Lines starting with # or ! are comments.
+// Continued lines and escapes are not supported.
+func ParseProperties(r io.Reader) (map[string]string, error) {
+	return parse(r, "#!", "=:", func(_, k, v string) (string, string, error) {
+		return strings.TrimSpace(k), strings.TrimSpace(v), nil
+	})
+}
+
+// ParseINI parses an INI file. The keys of a section are prefixed with
+// its name and a dot, like "server.port" for the key port in [server].
+// Lines starting with ; or # are comments.
+func ParseINI(r io.Reader) (map[string]string, error) {
+	return parse(r, ";#", "=", func(section, k, v string) (string, string, error) {
+		k = strings.TrimSpace(k)
+		if section != "" {
+			k = section + "." + k
+		}
+		return k, strings.TrimSpace(v), nil
+	})
+}
+
+// parse reads lines of keys and values separated by the first of seps.
+// Lines starting with a byte of comments are skipped, and lines like
+// [name] start a section. entry returns the key and value of a line.
+func parse(r io.Reader, comments, seps string, entry func(section, k, v string) (string, string, error)) (map[string]string, error) {
+	res := make(map[string]string)
+	section := ""
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case line == "" || strings.IndexByte(comments, line[0]) >= 0:
+			continue
+		case line[0] == '[' && line[len(line)-1] == ']':
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		i := strings.IndexAny(line, seps)
+		if i < 0 {
+			return nil, fmt.Errorf("kvdiff: line %d: missing separator in %q", n, line)
+		}
+		k, v, err := entry(section, line[:i], line[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("kvdiff: line %d: %v", n, err)
+		}
+		res[k] = v
+	}
+	return res, s.Err()
+}
+
+// Secret reports whether a key looks like it holds a secret, like
+// DB_PASSWORD or api.token.
+func Secret(key string) bool {
+	key = strings.ToUpper(key)
+	for _, s := range []string{"PASSWORD", "PASSWD", "SECRET", "TOKEN", "CREDENTIAL", "PRIVATE", "API_KEY", "APIKEY"} {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes one line for every change. The values of keys for which
+// mask returns true are replaced by "***", so that secrets don't leak
+// into logs; mask may be nil, for example Secret.
+//
+//	~ PORT=80 -> 8080
+//	+ NEW=value
+//	- OLD=value
+func Write(w io.Writer, changes []Change, mask func(key string) bool) error {
+	var sb strings.Builder
+	for _, c := range changes {
+		from, to := c.From, c.To
+		if mask != nil && mask(c.Key) {
+			from, to = "***", "***"
+		}
+		switch c.Status {
+		case Added:
+			sb.WriteString("+ " + c.Key + "=" + to + "\n")
+		case Removed:
+			sb.WriteString("- " + c.Key + "=" + from + "\n")
+		default:
+			sb.WriteString("~ " + c.Key + "=" + from + " -> " + to + "\n")
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,63 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kvdiff_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff/kvdiff"
+)
+
+func TestCompare(t *testing.T) {
+	a := kvdiff.Environ([]string{"HOME=/root", "PORT=80", "DB_PASSWORD=hunter2", "OLD=1"})
+	b := kvdiff.Environ([]string{"HOME=/root", "PORT=8080", "DB_PASSWORD=hunter3", "NEW=a=b"})
+	changes := kvdiff.Compare(a, b)
+	expect := []kvdiff.Change{
+		{Key: "DB_PASSWORD", Status: kvdiff.Modified, From: "hunter2", To: "hunter3"},
+		{Key: "NEW", Status: kvdiff.Added, To: "a=b"},
+		{Key: "OLD", Status: kvdiff.Removed, From: "1"},
+		{Key: "PORT", Status: kvdiff.Modified, From: "80", To: "8080"},
+	}
+	if !reflect.DeepEqual(changes, expect) {
+		t.Fatal("expected", expect, "got", changes)
+	}
+	var sb strings.Builder
+	if err := kvdiff.Write(&sb, changes, kvdiff.Secret); err != nil {
+		t.Fatal(err)
+	}
+	text := "~ DB_PASSWORD=*** -> ***\n+ NEW=a=b\n- OLD=1\n~ PORT=80 -> 8080\n"
+	if sb.String() != text {
+		t.Errorf("expected %q, got %q", text, sb.String())
+	}
+}
+
+func TestParse(t *testing.T) {
+	env, err := kvdiff.ParseEnv(strings.NewReader("# comment\nexport A=1\nB = \"two\\nlines\"\nC='$raw'\n\nD=\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := map[string]string{"A": "1", "B": "two\nlines", "C": "$raw", "D": ""}; !reflect.DeepEqual(env, expect) {
+		t.Error("expected", expect, "got", env)
+	}
+	props, err := kvdiff.ParseProperties(strings.NewReader("! comment\na.b=1\nc: x=y\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := map[string]string{"a.b": "1", "c": "x=y"}; !reflect.DeepEqual(props, expect) {
+		t.Error("expected", expect, "got", props)
+	}
+	ini, err := kvdiff.ParseINI(strings.NewReader("top=1\n; comment\n[server]\nport = 80\n[ db ]\nname=x\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := map[string]string{"top": "1", "server.port": "80", "db.name": "x"}; !reflect.DeepEqual(ini, expect) {
+		t.Error("expected", expect, "got", ini)
+	}
+	if _, err := kvdiff.ParseEnv(strings.NewReader("A=1\nB\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Error("expected an error on line 2, got", err)
+	}
+}
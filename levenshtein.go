@@ -0,0 +1,70 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// Levenshtein returns the Levenshtein distance of data, the least number
+// of inserted, deleted and substituted elements that turn a into b.
+// Unlike Distance, a substitution counts as one edit. It takes time
+// proportional to n*m and memory proportional to m.
+func Levenshtein(n, m int, data Data) int {
+	return levenshtein(n, m, data, false)
+}
+
+// Damerau returns the Damerau-Levenshtein distance of data, which also
+// counts swapping two neighboring elements as one edit. It is the optimal
+// string alignment variant, which edits no element more than once.
+func Damerau(n, m int, data Data) int {
+	return levenshtein(n, m, data, true)
+}
+
+// LevenshteinString returns the Levenshtein distance of the runes of two strings.
+func LevenshteinString(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	return Levenshtein(len(ra), len(rb), &runes{ra, rb})
+}
+
+// DamerauString returns the Damerau-Levenshtein distance of the runes of two strings.
+func DamerauString(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	return Damerau(len(ra), len(rb), &runes{ra, rb})
+}
+
+func levenshtein(n, m int, data Data, transpose bool) int {
+	aoffset, boffset := 0, 0
+	for aoffset < n && boffset < m && data.Equal(aoffset, boffset) {
+		aoffset++
+		boffset++
+	}
+	for n > aoffset && m > boffset && data.Equal(n-1, m-1) {
+		n--
+		m--
+	}
+	n, m = n-aoffset, m-boffset
+	if n == 0 || m == 0 {
+		return n + m
+	}
+	// rows of distances of the prefixes of a to all prefixes of b
+	prev2, prev, cur := make([]int, m+1), make([]int, m+1), make([]int, m+1)
+	for y := range prev {
+		prev[y] = y
+	}
+	for x := 1; x <= n; x++ {
+		cur[0] = x
+		for y := 1; y <= m; y++ {
+			cost := 1
+			if data.Equal(aoffset+x-1, boffset+y-1) {
+				cost = 0
+			}
+			d := min(prev[y]+1, cur[y-1]+1, prev[y-1]+cost)
+			if transpose && x > 1 && y > 1 && cost == 1 &&
+				data.Equal(aoffset+x-1, boffset+y-2) && data.Equal(aoffset+x-2, boffset+y-1) {
+				d = min(d, prev2[y-2]+1)
+			}
+			cur[y] = d
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[m]
+}
@@ -0,0 +1,52 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestLevenshtein(t *testing.T) {
+	for _, test := range []struct {
+		a, b         string
+		lev, damerau int
+	}{
+		{"", "", 0, 0},
+		{"abc", "", 3, 3},
+		{"kitten", "sitting", 3, 3},
+		{"flaw", "lawn", 2, 2},
+		{"ca", "abc", 3, 3},
+		{"abcdef", "abdcef", 2, 1},
+		{"héllo", "hallo", 1, 1},
+	} {
+		if d := diff.LevenshteinString(test.a, test.b); d != test.lev {
+			t.Errorf("Levenshtein of %q and %q: expected %d, got %d", test.a, test.b, test.lev, d)
+		}
+		if d := diff.DamerauString(test.a, test.b); d != test.damerau {
+			t.Errorf("Damerau of %q and %q: expected %d, got %d", test.a, test.b, test.damerau, d)
+		}
+	}
+}
+
+func TestLevenshteinBounds(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a := randomInts(r, r.Intn(20), 4)
+		b := randomInts(r, r.Intn(20), 4)
+		data := &ints{a, b}
+		lev := diff.Levenshtein(len(a), len(b), data)
+		dist := diff.Distance(len(a), len(b), data)
+		// a substitution is at most a deletion and an insertion
+		if lev > dist || 2*lev < dist {
+			t.Fatal(a, b, "Levenshtein", lev, "out of bounds of distance", dist)
+		}
+		if d := diff.Damerau(len(a), len(b), data); d > lev {
+			t.Fatal(a, b, "Damerau", d, "greater than Levenshtein", lev)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "sort"
+
+// LineMap returns a function that maps the zero based position of an
+// element of a to its position in b, given the changes from a to b.
+// ok is false if the element was deleted. Though named for lines, it works
+// for changes of any elements. The changes must be ordered by ascending
+// positions and must not be modified while the function is used.
+func LineMap(changes []Change) func(oldLine int) (newLine int, ok bool) {
+	return func(x int) (int, bool) {
+		// the first change ending after x
+		i := sort.Search(len(changes), func(i int) bool { return changes[i].A+changes[i].Del > x })
+		if i < len(changes) && changes[i].A <= x {
+			return 0, false
+		}
+		if i == 0 {
+			return x, true
+		}
+		c := changes[i-1]
+		return x - (c.A + c.Del) + c.B + c.Ins, true
+	}
+}
+
+// InverseLineMap returns a function that maps positions of b to positions
+// of a, given the changes from a to b. ok is false for inserted elements.
+func InverseLineMap(changes []Change) func(newLine int) (oldLine int, ok bool) {
+	return LineMap(Invert(changes))
+}
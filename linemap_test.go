@@ -0,0 +1,62 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestLineMap(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		a, b := randomInts(r, r.Intn(30), 5), randomInts(r, r.Intn(30), 5)
+		changes := diff.Ints(a, b)
+		// the positions of the common elements
+		deleted := make([]bool, len(a))
+		inserted := make([]bool, len(b))
+		for _, c := range changes {
+			for k := 0; k < c.Del; k++ {
+				deleted[c.A+k] = true
+			}
+			for k := 0; k < c.Ins; k++ {
+				inserted[c.B+k] = true
+			}
+		}
+		var common [][2]int
+		y := 0
+		for x := range a {
+			if deleted[x] {
+				continue
+			}
+			for inserted[y] {
+				y++
+			}
+			common = append(common, [2]int{x, y})
+			y++
+		}
+		toB, toA := diff.LineMap(changes), diff.InverseLineMap(changes)
+		for x := range a {
+			if _, ok := toB(x); ok == deleted[x] {
+				t.Fatal(a, b, "unexpected ok for", x)
+			}
+		}
+		for y := range b {
+			if _, ok := toA(y); ok == inserted[y] {
+				t.Fatal(a, b, "unexpected ok for", y)
+			}
+		}
+		for _, p := range common {
+			if y, _ := toB(p[0]); y != p[1] {
+				t.Fatal(a, b, "expected", p[0], "to map to", p[1], "got", y)
+			}
+			if x, _ := toA(p[1]); x != p[0] {
+				t.Fatal(a, b, "expected", p[1], "to map to", p[0], "got", x)
+			}
+		}
+	}
+}
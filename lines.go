@@ -0,0 +1,117 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "strings"
+
+// Lines returns the differences of two string slices, typically lines.
+// Every distinct string is mapped to an integer before diffing, so that
+// the algorithm compares integers instead of whole strings.
+func Lines(a, b []string, opts ...Option) []Change {
+	return lineChanges(a, b, opts...)
+}
+
+// LinesFunc returns the differences of the lines of two strings, where two
+// lines are equal if eq reports so, for example strings.EqualFold.
+//
+// Unlike Lines, which returns indices of lines, LinesFunc returns changes
+// whose positions and lengths are byte offsets into a and b, like those of
+// Words.
+//
+// The lines are passed to eq without their line ending, which must be
+// equal too, so a missing final newline is a change. With
+// WithStripTrailingCR, "\r\n" and "\n" are equal line endings.
+// WithLineKey is ignored, eq compares the lines instead.
+func LinesFunc(a, b string, eq func(x, y string) bool, opts ...Option) []Change {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	la, lb := splitLines(a), splitLines(b)
+	changes := Diff(len(la), len(lb), &funcLines{la, lb, eq, o.stripCR}, opts...)
+	return byteOffsets(la, lb, changes)
+}
+
+type funcLines struct {
+	a, b    []string
+	eq      func(x, y string) bool
+	stripCR bool
+}
+
+func (d *funcLines) Equal(i, j int) bool {
+	x, xeol := d.line(d.a[i])
+	y, yeol := d.line(d.b[j])
+	return xeol == yeol && d.eq(x, y)
+}
+
+// line splits s into the text passed to eq and its line ending.
+func (d *funcLines) line(s string) (text, eol string) {
+	if d.stripCR {
+		s = trimCR(s)
+	}
+	if text, ok := strings.CutSuffix(s, "\n"); ok {
+		return text, "\n"
+	}
+	return s, ""
+}
+
+// lineChanges diffs the interned strings of a and b.
+func lineChanges(a, b []string, opts ...Option) []Change {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ia, ib := intern(a, b, &o)
+	return Diff(len(ia), len(ib), &ints{ia, ib}, opts...)
+}
+
+// intern maps the strings of a and b to integers that are equal
+// exactly if the strings are, ignoring a carriage return before
+// the final newline if stripCR is set and comparing the keys of
+// the strings if lineKey is set.
+func intern(a, b []string, o *options) (ia, ib []int) {
+	ids := make(map[string]int, len(a))
+	id := func(s string) int {
+		if o.stripCR {
+			s = trimCR(s)
+		}
+		if o.lineKey != nil {
+			s = o.lineKey(s)
+		}
+		i, ok := ids[s]
+		if !ok {
+			i = len(ids)
+			ids[s] = i
+		}
+		return i
+	}
+	ia, ib = make([]int, len(a)), make([]int, len(b))
+	for i, s := range a {
+		ia[i] = id(s)
+	}
+	for i, s := range b {
+		ib[i] = id(s)
+	}
+	return ia, ib
+}
+
+// trimCR removes a carriage return before the final newline of line.
+func trimCR(line string) string {
+	if strings.HasSuffix(line, "\r\n") {
+		return line[:len(line)-2] + "\n"
+	}
+	return line
+}
+
+// NormalizeLineEndings replaces the line endings "\r\n" and "\n" of s with eol.
+// It is used to unify text before applying or rendering changes of lines
+// that were diffed WithStripTrailingCR.
+func NormalizeLineEndings(s, eol string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	if eol == "\n" {
+		return s
+	}
+	return strings.ReplaceAll(s, "\n", eol)
+}
@@ -0,0 +1,125 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestLines(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		a := randomLines(r, 200, 20)
+		b := randomLines(r, 200, 20)
+		expect := diff.Diff(len(a), len(b), &stringSlices{a, b})
+		res := diff.Lines(a, b)
+		if !diffsEqual(res, expect) {
+			t.Fatal("expected", expect, "got", res)
+		}
+	}
+}
+
+func randomLines(r *rand.Rand, n, alphabet int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d\n", r.Intn(alphabet))
+	}
+	return lines
+}
+
+func BenchmarkLines(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	la, lb := randomLines(r, 5000, 1000), randomLines(r, 5000, 1000)
+	for i := 0; i < b.N; i++ {
+		diff.Lines(la, lb)
+	}
+}
+
+func TestLinesStripTrailingCR(t *testing.T) {
+	a := []string{"one\r\n", "two\r\n", "three\r\n"}
+	b := []string{"one\n", "2\n", "three\n"}
+	if res := diff.Lines(a, b); len(res) != 1 || res[0].Del != 3 {
+		t.Error("expected all lines to differ, got", res)
+	}
+	expect := []diff.Change{{A: 1, B: 1, Del: 1, Ins: 1}}
+	if res := diff.Lines(a, b, diff.WithStripTrailingCR()); !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	// a carriage return inside a line still counts
+	if res := diff.Lines([]string{"a\rb\n"}, []string{"ab\n"}, diff.WithStripTrailingCR()); len(res) != 1 {
+		t.Error("expected one change, got", res)
+	}
+}
+
+func TestWithLineKey(t *testing.T) {
+	a := []string{"One\r\n", "two\r\n", "three\r\n"}
+	b := []string{"one\n", "2\n", "THREE\n"}
+	expect := []diff.Change{{A: 1, B: 1, Del: 1, Ins: 1}}
+	if res := diff.Lines(a, b, diff.WithLineKey(strings.ToLower), diff.WithStripTrailingCR()); !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	if res := diff.Lines(a, b, diff.WithLineKey(strings.ToLower)); len(res) != 1 || res[0].Del != 3 {
+		t.Error("expected all lines to differ with carriage returns, got", res)
+	}
+}
+
+func TestLinesFunc(t *testing.T) {
+	a := "One\ntwo\n  three\nfour"
+	b := "one\nTWO\nthree\n4\n"
+	if res := diff.LinesFunc(a, b, func(x, y string) bool { return x == y }); len(res) != 1 || res[0].Del != len(a) {
+		t.Error("expected all lines to differ, got", res)
+	}
+	eq := func(x, y string) bool { return strings.EqualFold(strings.TrimSpace(x), strings.TrimSpace(y)) }
+	// the last lines differ
+	expect := []diff.Change{{A: 16, B: 14, Del: 4, Ins: 2}}
+	if res := diff.LinesFunc(a, b, eq); !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	// a missing newline is a change
+	expect = []diff.Change{{A: 16, B: 14, Del: 4, Ins: 5}}
+	if res := diff.LinesFunc(a, "one\ntwo\nthree\nFOUR\n", eq); !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	if res := diff.LinesFunc(a, "one\ntwo\nthree\nFOUR", eq); len(res) != 0 {
+		t.Error("expected no changes, got", res)
+	}
+	if res := diff.LinesFunc("one\ntwo\n", "ONE\r\ntwo\r\n", strings.EqualFold); len(res) != 1 {
+		t.Error("expected the carriage returns to differ, got", res)
+	}
+	if res := diff.LinesFunc("one\ntwo\n", "ONE\r\ntwo\r\n", strings.EqualFold, diff.WithStripTrailingCR()); len(res) != 0 {
+		t.Error("expected no changes without carriage returns, got", res)
+	}
+}
+
+func TestLinesFuncCheck(t *testing.T) {
+	for _, test := range []struct{ a, b string }{
+		{"x\n", "x"},
+		{"x", "x\n"},
+		{"grüße\nan\nalle", "GRÜSSE\nan\nalle\n"},
+		{"a\r\nb\n", "A\nb"},
+	} {
+		res := diff.LinesFunc(test.a, test.b, strings.EqualFold)
+		if err := diff.Check([]byte(test.a), []byte(test.b), res); err != nil {
+			t.Errorf("%q %q: %v", test.a, test.b, err)
+		}
+	}
+}
+
+func TestNormalizeLineEndings(t *testing.T) {
+	for _, test := range []struct{ s, eol, expect string }{
+		{"a\r\nb\nc", "\n", "a\nb\nc"},
+		{"a\r\nb\nc\n", "\r\n", "a\r\nb\r\nc\r\n"},
+		{"a\rb", "\n", "a\rb"},
+	} {
+		if res := diff.NormalizeLineEndings(test.s, test.eol); res != test.expect {
+			t.Errorf("NormalizeLineEndings(%q, %q) = %q, expected %q", test.s, test.eol, res, test.expect)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// The types implement both encoding.TextMarshaler and json.Marshaler,
+// because encoding/json would otherwise encode them as text.
+// The aliases have the same fields without the methods.
+
+type jsonChange Change
+
+// MarshalJSON implements json.Marshaler.
+func (c Change) MarshalJSON() ([]byte, error) { return json.Marshal(jsonChange(c)) }
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *Change) UnmarshalJSON(data []byte) error { return json.Unmarshal(data, (*jsonChange)(c)) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (c Change) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d,%d-%d+%d", c.A, c.B, c.Del, c.Ins)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (c *Change) UnmarshalText(text []byte) error {
+	var v Change
+	_, err := fmt.Sscanf(string(text), "%d,%d-%d+%d", &v.A, &v.B, &v.Del, &v.Ins)
+	// only accept the canonical form
+	if canonical, _ := v.MarshalText(); err != nil || string(canonical) != string(text) || v.A < 0 || v.B < 0 || v.Del < 0 || v.Ins < 0 {
+		return fmt.Errorf("diff: invalid change %q", text)
+	}
+	*c = v
+	return nil
+}
+
+type jsonTypedChange[T any] struct {
+	jsonChange
+	Deleted  []T `json:"deleted"`
+	Inserted []T `json:"inserted"`
+}
+
+// MarshalJSON implements json.Marshaler. It replaces the method of the
+// embedded Change, which would drop the elements.
+func (c TypedChange[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonTypedChange[T]{jsonChange(c.Change), c.Deleted, c.Inserted})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *TypedChange[T]) UnmarshalJSON(data []byte) error {
+	var v jsonTypedChange[T]
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*c = TypedChange[T]{Change(v.jsonChange), v.Deleted, v.Inserted}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler. A typed change has no
+// text form, so it returns an error instead of dropping the elements.
+func (c TypedChange[T]) MarshalText() ([]byte, error) {
+	return nil, fmt.Errorf("diff: typed change can not be encoded as text")
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It always returns
+// an error like MarshalText.
+func (c *TypedChange[T]) UnmarshalText(text []byte) error {
+	return fmt.Errorf("diff: typed change can not be decoded from text")
+}
+
+type jsonHunk Hunk
+
+// MarshalJSON implements json.Marshaler.
+func (h Hunk) MarshalJSON() ([]byte, error) { return json.Marshal(jsonHunk(h)) }
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (h *Hunk) UnmarshalJSON(data []byte) error { return json.Unmarshal(data, (*jsonHunk)(h)) }
+
+// MarshalText implements encoding.TextMarshaler.
+// Only hunks with Lines can be encoded as text.
+func (h Hunk) MarshalText() ([]byte, error) {
+	if h.Lines == nil && (h.LenA > 0 || h.LenB > 0) {
+		return nil, fmt.Errorf("diff: hunk without lines")
+	}
+	var sb strings.Builder
+	writeHunk(&sb, h)
+	return []byte(sb.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (h *Hunk) UnmarshalText(text []byte) error {
+	p := &patchParser{s: bufio.NewScanner(bytes.NewReader(text))}
+	p.s.Buffer(nil, len(text)+1)
+	if !p.next() || !strings.HasPrefix(p.line, "@@ ") {
+		return fmt.Errorf("diff: invalid hunk %q", text)
+	}
+	hunk, err := p.hunk()
+	if err != nil {
+		return fmt.Errorf("diff: %v", err)
+	}
+	*h = hunk
+	return nil
+}
+
+type jsonFilePatch FilePatch
+
+// MarshalJSON implements json.Marshaler.
+func (f FilePatch) MarshalJSON() ([]byte, error) { return json.Marshal(jsonFilePatch(f)) }
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *FilePatch) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*jsonFilePatch)(f))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (f FilePatch) MarshalText() ([]byte, error) { return Patch{f}.MarshalText() }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (f *FilePatch) UnmarshalText(text []byte) error {
+	p, err := ParsePatch(bytes.NewReader(text))
+	if err != nil {
+		return err
+	}
+	if len(p) != 1 {
+		return fmt.Errorf("diff: expected one file in patch, got %d", len(p))
+	}
+	*f = p[0]
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p Patch) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal([]FilePatch(p))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *Patch) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*[]FilePatch)(p))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (p Patch) MarshalText() ([]byte, error) {
+	var buf bytes.Buffer
+	err := WritePatch(&buf, p)
+	return buf.Bytes(), err
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *Patch) UnmarshalText(text []byte) error {
+	patch, err := ParsePatch(bytes.NewReader(text))
+	if err != nil {
+		return err
+	}
+	*p = patch
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestChangeJSON(t *testing.T) {
+	changes := []diff.Change{{A: 1, B: 2, Del: 3, Ins: 4}}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := `[{"a":1,"b":2,"del":3,"ins":4}]`; string(data) != expect {
+		t.Errorf("expected %s, got %s", expect, data)
+	}
+	var res []diff.Change
+	if err := json.Unmarshal(data, &res); err != nil {
+		t.Fatal(err)
+	}
+	if !diffsEqual(res, changes) {
+		t.Error("expected", changes, "got", res)
+	}
+}
+
+func TestChangeText(t *testing.T) {
+	c := diff.Change{A: 1, B: 2, Del: 3, Ins: 4}
+	text, err := c.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(text) != "1,2-3+4" {
+		t.Errorf("expected 1,2-3+4, got %s", text)
+	}
+	var res diff.Change
+	if err := res.UnmarshalText(text); err != nil || res != c {
+		t.Error("expected", c, "got", res, err)
+	}
+	for _, bad := range []string{"", "1,2-3", "1,2-3+4x", "1,2--3+4", "a,2-3+4", "1, 2-3+4"} {
+		if err := res.UnmarshalText([]byte(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+	// text keys in maps
+	data, err := json.Marshal(map[diff.Change]bool{c: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := `{"1,2-3+4":true}`; string(data) != expect {
+		t.Errorf("expected %s, got %s", expect, data)
+	}
+}
+
+func TestTypedChangeJSON(t *testing.T) {
+	a, b := []string{"x", "y"}, []string{"z"}
+	changes := diff.Materialize(a, b, diff.Lines(a, b))
+	data, err := json.Marshal(changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := `[{"a":0,"b":0,"del":2,"ins":1,"deleted":["x","y"],"inserted":["z"]}]`; string(data) != expect {
+		t.Errorf("expected %s, got %s", expect, data)
+	}
+	var res []diff.TypedChange[string]
+	if err := json.Unmarshal(data, &res); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, changes) {
+		t.Error("expected", changes, "got", res)
+	}
+	if _, err := changes[0].MarshalText(); err == nil {
+		t.Error("expected an error for the text form")
+	}
+}
+
+func TestPatchJSON(t *testing.T) {
+	p, err := diff.ParsePatch(strings.NewReader(gitPatch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), `[{"oldPath":"f.txt","newPath":"f.txt","oldMode":33188,"newMode":33261,"hunks":[{"a":0,"b":0,"lenA":3,"lenB":3,"changes":[{"a":1,"b":1,"del":1,"ins":1}],"lines":[" a\n","-b\n","+B\n"," c\n"]}]}`) {
+		t.Errorf("unexpected JSON %s", data)
+	}
+	var res diff.Patch
+	if err := json.Unmarshal(data, &res); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, p) {
+		t.Errorf("expected\n%+v\ngot\n%+v", p, res)
+	}
+}
+
+func TestPatchText(t *testing.T) {
+	p, err := diff.ParsePatch(strings.NewReader(gitPatch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := p.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res diff.Patch
+	if err := res.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, p) {
+		t.Errorf("expected\n%+v\ngot\n%+v", p, res)
+	}
+
+	text, err = p[0].MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f diff.FilePatch
+	if err := f.UnmarshalText(text); err != nil || !reflect.DeepEqual(f, p[0]) {
+		t.Errorf("expected\n%+v\ngot\n%+v %v", p[0], f, err)
+	}
+
+	text, err = p[1].Hunks[0].MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "@@ -2,3 +2,4 @@ one\n two\n three\n four\n+five\n"; string(text) != expect {
+		t.Errorf("expected %q, got %q", expect, text)
+	}
+	var h diff.Hunk
+	if err := h.UnmarshalText(text); err != nil || !reflect.DeepEqual(h, p[1].Hunks[0]) {
+		t.Errorf("expected\n%+v\ngot\n%+v %v", p[1].Hunks[0], h, err)
+	}
+	if _, err := diff.Hunks(3, 3, []diff.Change{{A: 1, B: 1, Del: 1, Ins: 1}}, 1)[0].MarshalText(); err == nil {
+		t.Error("expected an error for a hunk without lines")
+	}
+}
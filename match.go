@@ -0,0 +1,168 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "strings"
+
+// MatchOptions configure Match.
+type MatchOptions struct {
+	// Threshold is the worst score of an accepted match,
+	// from 0 for a perfect match to 1 for anything.
+	Threshold float64
+	// Distance is how far from the expected location a match may be,
+	// at the cost of one error per Distance bytes. With 0 only matches
+	// at the expected location are accepted.
+	Distance int
+}
+
+// DefaultMatchOptions are used by Match if no options are given.
+var DefaultMatchOptions = MatchOptions{Threshold: 0.5, Distance: 1000}
+
+// MaxMatchPattern is the longest pattern matched approximately by Match.
+const MaxMatchPattern = 64
+
+// Match returns the byte offset of the best approximate match of pattern
+// in text near loc, or -1 if there is none. Matches are scored by their
+// number of errors relative to the pattern length plus their distance from
+// loc, using the bitap algorithm like diff-match-patch. Patterns longer than
+// MaxMatchPattern are only found as exact matches at loc.
+func Match(text, pattern string, loc int, opts *MatchOptions) int {
+	if opts == nil {
+		opts = &DefaultMatchOptions
+	}
+	if loc < 0 {
+		loc = 0
+	} else if loc > len(text) {
+		loc = len(text)
+	}
+	switch {
+	case text == pattern:
+		return 0
+	case text == "":
+		return -1
+	case loc+len(pattern) <= len(text) && text[loc:loc+len(pattern)] == pattern:
+		return loc
+	case len(pattern) > MaxMatchPattern:
+		return -1
+	}
+	return bitap(text, pattern, loc, opts)
+}
+
+// bitap returns the location of the best match of pattern in text near loc.
+func bitap(text, pattern string, loc int, opts *MatchOptions) int {
+	score := func(errors, x int) float64 {
+		accuracy := float64(errors) / float64(len(pattern))
+		proximity := loc - x
+		if proximity < 0 {
+			proximity = -proximity
+		}
+		if opts.Distance == 0 {
+			if proximity == 0 {
+				return accuracy
+			}
+			return 1
+		}
+		return accuracy + float64(proximity)/float64(opts.Distance)
+	}
+	// bit masks of the positions of every byte in pattern
+	var alphabet [256]uint64
+	for i := 0; i < len(pattern); i++ {
+		alphabet[pattern[i]] |= 1 << uint(len(pattern)-i-1)
+	}
+
+	// exact matches bound the threshold
+	threshold := opts.Threshold
+	if i := indexFrom(text, pattern, loc); i >= 0 {
+		if s := score(0, i); s < threshold {
+			threshold = s
+		}
+		if i := lastIndexBefore(text, pattern, loc+len(pattern)); i >= 0 {
+			if s := score(0, i); s < threshold {
+				threshold = s
+			}
+		}
+	}
+
+	matchmask := uint64(1) << uint(len(pattern)-1)
+	best := -1
+	binMax := len(pattern) + len(text)
+	var last []uint64
+	for d := 0; d < len(pattern); d++ {
+		// find how far from loc a match with d errors may be
+		binMin, binMid := 0, binMax
+		for binMin < binMid {
+			if score(d, loc+binMid) <= threshold {
+				binMin = binMid
+			} else {
+				binMax = binMid
+			}
+			binMid = (binMax-binMin)/2 + binMin
+		}
+		binMax = binMid
+		start := loc - binMid + 1
+		if start < 1 {
+			start = 1
+		}
+		finish := loc + binMid
+		if finish > len(text) {
+			finish = len(text)
+		}
+		finish += len(pattern)
+
+		rd := make([]uint64, finish+2)
+		rd[finish+1] = 1<<uint(d) - 1
+		for j := finish; j >= start; j-- {
+			var charMatch uint64
+			if j-1 < len(text) {
+				charMatch = alphabet[text[j-1]]
+			}
+			if d == 0 {
+				rd[j] = (rd[j+1]<<1 | 1) & charMatch
+			} else {
+				rd[j] = (rd[j+1]<<1|1)&charMatch | ((last[j+1]|last[j])<<1 | 1) | last[j+1]
+			}
+			if rd[j]&matchmask != 0 {
+				if s := score(d, j-1); s <= threshold {
+					threshold = s
+					best = j - 1
+					if best <= loc {
+						break
+					}
+					// already passed loc, only search as far to the left
+					start = 2*loc - best
+					if start < 1 {
+						start = 1
+					}
+				}
+			}
+		}
+		if score(d+1, loc) > threshold {
+			break
+		}
+		last = rd
+	}
+	return best
+}
+
+// indexFrom returns the index of the first pattern in text at or after i.
+func indexFrom(text, pattern string, i int) int {
+	if i > len(text) {
+		return -1
+	}
+	if j := strings.Index(text[i:], pattern); j >= 0 {
+		return i + j
+	}
+	return -1
+}
+
+// lastIndexBefore returns the index of the last pattern in text
+// that starts at or before i.
+func lastIndexBefore(text, pattern string, i int) int {
+	end := i + len(pattern)
+	if end > len(text) {
+		end = len(text)
+	}
+	return strings.LastIndex(text[:end], pattern)
+}
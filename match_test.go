@@ -0,0 +1,49 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestMatch(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		text, pattern string
+		loc           int
+		opts          *diff.MatchOptions
+		expect        int
+	}{
+		{"equality", "abcdef", "abcdef", 1000, nil, 0},
+		{"empty text", "", "abcdef", 1, nil, -1},
+		{"empty pattern", "abcdef", "", 3, nil, 3},
+		{"exact", "abcdef", "de", 3, nil, 3},
+		{"beyond end", "abcdef", "defy", 4, nil, 3},
+		{"oversized pattern", "abcdef", "abcdefy", 0, nil, 0},
+		{"exact elsewhere", "abcdefghijk", "fgh", 5, nil, 5},
+		{"exact shifted", "abcdefghijk", "fgh", 0, nil, 5},
+		{"fuzzy", "abcdefghijk", "efxhi", 0, nil, 4},
+		{"fuzzy shifted", "abcdefghijk", "cdefxyhijk", 5, nil, 2},
+		{"no match", "abcdefghijk", "bxy", 1, nil, -1},
+		{"overflow", "123456789xx0", "3456789x0", 2, nil, 2},
+		{"before start", "abcdef", "xxabc", 4, nil, 0},
+		{"after end", "abcdef", "defyy", 4, nil, 3},
+		{"threshold", "abcdefghijk", "efxyhi", 1, &diff.MatchOptions{Threshold: 0.4, Distance: 1000}, 4},
+		{"strict threshold", "abcdefghijk", "efxyhi", 1, &diff.MatchOptions{Threshold: 0.3, Distance: 1000}, -1},
+		{"perfect threshold", "abcdefghijk", "bcdef", 1, &diff.MatchOptions{Threshold: 0, Distance: 1000}, 1},
+		{"multiple", "abcdexyzabcde", "abccde", 3, nil, 0},
+		{"multiple later", "abcdexyzabcde", "abccde", 5, nil, 8},
+		{"multiple before", "abcdefghijklmnopqrstuvwxyz", "abcdefg", 24, &diff.MatchOptions{Threshold: 0.5, Distance: 10}, -1},
+		{"loose distance", "abcdefghijklmnopqrstuvwxyz", "abcdxxefg", 1, &diff.MatchOptions{Threshold: 0.5, Distance: 10}, 0},
+		{"long pattern", strings.Repeat("x", 100) + "abc", strings.Repeat("y", 70), 0, nil, -1},
+	} {
+		if res := diff.Match(test.text, test.pattern, test.loc, test.opts); res != test.expect {
+			t.Errorf("%s: Match(%q, %q, %d) = %d, expected %d", test.name, test.text, test.pattern, test.loc, res, test.expect)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// A TypedChange is a Change together with the elements it deletes and inserts.
+type TypedChange[T any] struct {
+	Change
+	Deleted  []T // a[A:A+Del]
+	Inserted []T // b[B:B+Ins]
+}
+
+// Materialize attaches the deleted elements of a and the inserted elements
+// of b to every change. The element slices share memory with a and b.
+func Materialize[T any](a, b []T, changes []Change) []TypedChange[T] {
+	res := make([]TypedChange[T], len(changes))
+	for i, c := range changes {
+		res[i] = TypedChange[T]{
+			Change:   c,
+			Deleted:  a[c.A : c.A+c.Del : c.A+c.Del],
+			Inserted: b[c.B : c.B+c.Ins : c.B+c.Ins],
+		}
+	}
+	return res
+}
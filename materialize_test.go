@@ -0,0 +1,34 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestMaterialize(t *testing.T) {
+	a := []string{"a", "b", "c", "d"}
+	b := []string{"x", "a", "B", "c"}
+	res := diff.Materialize(a, b, diff.Lines(a, b))
+	expect := []diff.TypedChange[string]{
+		{Change: diff.Change{A: 0, B: 0, Del: 0, Ins: 1}, Deleted: []string{}, Inserted: []string{"x"}},
+		{Change: diff.Change{A: 1, B: 2, Del: 1, Ins: 1}, Deleted: []string{"b"}, Inserted: []string{"B"}},
+		{Change: diff.Change{A: 3, B: 4, Del: 1, Ins: 0}, Deleted: []string{"d"}, Inserted: []string{}},
+	}
+	if !reflect.DeepEqual(res, expect) {
+		t.Errorf("expected %v, got %v", expect, res)
+	}
+	// appending to the elements does not overwrite the input
+	_ = append(res[1].Deleted, "z")
+	if a[2] != "c" {
+		t.Error("input was modified", a)
+	}
+	if res := diff.Materialize[int](nil, nil, nil); len(res) != 0 {
+		t.Error("expected no changes, got", res)
+	}
+}
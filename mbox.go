@@ -0,0 +1,80 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// An Email describes a commit to be sent as a patch email.
+type Email struct {
+	// Hash is the commit id written to the mbox From line.
+	Hash string
+	// Author is the name and address like "Jane Doe <jane@example.com>".
+	Author  string
+	Date    time.Time
+	Subject string
+	// Message is the commit message without the subject.
+	Message string
+	// Number and Total number the emails of a series, if Total > 1.
+	Number, Total int
+	// Signature is written after the patch, usually the version of the tool.
+	Signature string
+	Patch     Patch
+}
+
+// WriteFormatPatch writes the email in the mbox format of git format-patch,
+// with the commit message, a diffstat and the patch, so that git am can
+// apply it.
+func WriteFormatPatch(w io.Writer, e Email) error {
+	hash := e.Hash
+	if hash == "" {
+		hash = strings.Repeat("0", 40)
+	}
+	prefix := "[PATCH]"
+	if e.Total > 1 {
+		prefix = fmt.Sprintf("[PATCH %d/%d]", e.Number, e.Total)
+	}
+	var sb strings.Builder
+	// git uses this fixed date to mark format-patch output
+	sb.WriteString("From " + hash + " Mon Sep 17 00:00:00 2001\n")
+	sb.WriteString("From: " + e.Author + "\n")
+	sb.WriteString("Date: " + e.Date.Format("Mon, 2 Jan 2006 15:04:05 -0700") + "\n")
+	sb.WriteString("Subject: " + prefix + " " + e.Subject + "\n\n")
+	if msg := strings.TrimSpace(e.Message); msg != "" {
+		sb.WriteString(msg + "\n\n")
+	}
+	sb.WriteString("---\n")
+
+	stats := make([]Labeled, len(e.Patch))
+	for i, f := range e.Patch {
+		label := f.NewPath
+		switch {
+		case f.NewPath == "":
+			label = f.OldPath
+		case f.OldPath != "" && f.OldPath != f.NewPath:
+			label = f.OldPath + " => " + f.NewPath
+		}
+		stats[i].Label = label
+		for _, h := range f.Hunks {
+			stats[i].Changes = append(stats[i].Changes, h.Changes...)
+		}
+	}
+	if err := WriteDiffstat(&sb, stats, 72); err != nil {
+		return err
+	}
+	sb.WriteByte('\n')
+	if err := WritePatch(&sb, e.Patch); err != nil {
+		return err
+	}
+	if e.Signature != "" {
+		sb.WriteString("-- \n" + e.Signature + "\n\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,75 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/echlebek/diff"
+)
+
+func TestWriteFormatPatch(t *testing.T) {
+	a := splitLines("a\nb\nc\n")
+	b := splitLines("a\nB\nc\n")
+	e := diff.Email{
+		Hash:      "8d5b6a1b3c0e6a0e0f1cd1a3f5b59a3a5e4f3b21",
+		Author:    "Jane Doe <jane@example.com>",
+		Date:      time.Date(2012, 3, 4, 5, 6, 7, 0, time.FixedZone("", 3600)),
+		Subject:   "Capitalize b",
+		Message:   "B is more important.\n",
+		Signature: "2.34.1",
+		Patch:     diff.Patch{diff.NewFilePatch("f.txt", "f.txt", a, b, diff.Lines(a, b), 3)},
+	}
+	var sb strings.Builder
+	if err := diff.WriteFormatPatch(&sb, e); err != nil {
+		t.Fatal(err)
+	}
+	expect := "" +
+		"From 8d5b6a1b3c0e6a0e0f1cd1a3f5b59a3a5e4f3b21 Mon Sep 17 00:00:00 2001\n" +
+		"From: Jane Doe <jane@example.com>\n" +
+		"Date: Sun, 4 Mar 2012 05:06:07 +0100\n" +
+		"Subject: [PATCH] Capitalize b\n" +
+		"\n" +
+		"B is more important.\n" +
+		"\n" +
+		"---\n" +
+		" f.txt | 2 +-\n" +
+		" 1 file changed, 1 insertion(+), 1 deletion(-)\n" +
+		"\n" +
+		"diff --git a/f.txt b/f.txt\n" +
+		"--- a/f.txt\n" +
+		"+++ b/f.txt\n" +
+		"@@ -1,3 +1,3 @@\n" +
+		" a\n" +
+		"-b\n" +
+		"+B\n" +
+		" c\n" +
+		"-- \n" +
+		"2.34.1\n" +
+		"\n"
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+
+	// the patch can be read back
+	p, err := diff.ParsePatch(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p) != 1 || len(p[0].Hunks) != 1 || !diffsEqual(p[0].Hunks[0].Changes, diff.Lines(a, b)) {
+		t.Error("unexpected patch", p)
+	}
+
+	sb.Reset()
+	e.Number, e.Total = 2, 3
+	if err := diff.WriteFormatPatch(&sb, e); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "Subject: [PATCH 2/3] Capitalize b\n") {
+		t.Error("expected a numbered subject in", sb.String())
+	}
+}
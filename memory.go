@@ -0,0 +1,58 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"fmt"
+	"math"
+)
+
+// A MemoryError is returned by Differ.Err if the search for a minimal
+// result stopped because it needed more memory than WithMaxMemory allows.
+type MemoryError struct {
+	Limit int // the limit in bytes
+	Need  int // the estimated bytes needed when the search stopped
+}
+
+func (e *MemoryError) Error() string {
+	return fmt.Sprintf("diff: needs %d bytes of memory, more than the limit of %d", e.Need, e.Limit)
+}
+
+// WithMaxMemory limits the memory used by the buffers of the algorithms to
+// about bytes. If a diff needs more, the remaining differences are reported
+// as replacing everything in between like with WithContext, and Differ.Err
+// returns a *MemoryError. The memory of the inputs and results is not counted.
+func WithMaxMemory(bytes int) Option {
+	return func(o *options) { o.maxMemory = bytes }
+}
+
+// exceeds reports whether need bytes are more than the memory limit
+// and records a MemoryError.
+func (c *context) exceeds(need int) bool {
+	if c.maxMemory <= 0 || need <= c.maxMemory {
+		return false
+	}
+	c.err = &MemoryError{Limit: c.maxMemory, Need: need}
+	return true
+}
+
+// baseMemory estimates the bytes used by every algorithm
+// for data with lengths n and m.
+func (c *context) baseMemory(n, m int) int {
+	need := len(c.flags)
+	if c.data == &c.hashed {
+		need += 8 * (n + m)
+	}
+	return need
+}
+
+// myersMemory estimates the bytes used by the d-path arrays of Myers.
+func myersMemory(n, m int) int {
+	size := 2 * (n + m + 1)
+	if size <= math.MaxInt32 {
+		return 2 * 4 * size
+	}
+	return 2 * 8 * size
+}
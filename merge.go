@@ -0,0 +1,222 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"io"
+	"strings"
+)
+
+// A MergeState tells how a region of a three-way merge was changed.
+type MergeState int
+
+const (
+	// Unchanged regions are equal in all three versions.
+	Unchanged MergeState = iota
+	// ChangedOurs regions were changed by ours or by both sides the same way.
+	ChangedOurs
+	// ChangedTheirs regions were changed by theirs only.
+	ChangedTheirs
+	// Conflict regions were changed differently by both sides.
+	Conflict
+)
+
+func (s MergeState) String() string {
+	switch s {
+	case Unchanged:
+		return "unchanged"
+	case ChangedOurs:
+		return "ours"
+	case ChangedTheirs:
+		return "theirs"
+	case Conflict:
+		return "conflict"
+	}
+	return "unknown"
+}
+
+// A MergeChunk is a region of a three-way merge with its ranges
+// base[Base0:Base1], ours[Ours0:Ours1] and theirs[Theirs0:Theirs1].
+type MergeChunk struct {
+	State            MergeState
+	Base0, Base1     int
+	Ours0, Ours1     int
+	Theirs0, Theirs1 int
+}
+
+// Merge3 merges the changes from base to ours and from base to theirs like
+// diff3 and returns the regions of the merge in order. Changes of both sides
+// that overlap or touch are a conflict unless they are equal.
+func Merge3[T comparable](base, ours, theirs []T, opts ...Option) []MergeChunk {
+	co := Diff(len(base), len(ours), &comparables[T]{base, ours}, opts...)
+	ct := Diff(len(base), len(theirs), &comparables[T]{base, theirs}, opts...)
+	var res []MergeChunk
+	// x is the position in base, the other sides are offset by do and dt
+	x, do, dt := 0, 0, 0
+	unchanged := func(end int) {
+		if x < end {
+			res = append(res, MergeChunk{Unchanged, x, end, x + do, end + do, x + dt, end + dt})
+		}
+	}
+	for len(co) > 0 || len(ct) > 0 {
+		lo := 0
+		switch {
+		case len(ct) == 0 || len(co) > 0 && co[0].A < ct[0].A:
+			lo = co[0].A
+		default:
+			lo = ct[0].A
+		}
+		unchanged(lo)
+		// collect the changes of both sides that overlap the region
+		hi, no, nt := lo, 0, 0
+		for {
+			if no < len(co) && co[no].A <= hi {
+				if end := co[no].A + co[no].Del; end > hi {
+					hi = end
+				}
+				no++
+			} else if nt < len(ct) && ct[nt].A <= hi {
+				if end := ct[nt].A + ct[nt].Del; end > hi {
+					hi = end
+				}
+				nt++
+			} else {
+				break
+			}
+		}
+		c := MergeChunk{Base0: lo, Base1: hi, Ours0: lo + do, Theirs0: lo + dt}
+		for _, ch := range co[:no] {
+			do += ch.Ins - ch.Del
+		}
+		for _, ch := range ct[:nt] {
+			dt += ch.Ins - ch.Del
+		}
+		c.Ours1, c.Theirs1 = hi+do, hi+dt
+		switch {
+		case nt == 0:
+			c.State = ChangedOurs
+		case no == 0:
+			c.State = ChangedTheirs
+		case equal(ours[c.Ours0:c.Ours1], theirs[c.Theirs0:c.Theirs1]):
+			c.State = ChangedOurs
+		default:
+			c.State = Conflict
+		}
+		res = append(res, c)
+		co, ct, x = co[no:], ct[nt:], hi
+	}
+	unchanged(len(base))
+	return res
+}
+
+func equal[T comparable](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// MergeOptions configures the output of WriteMerge.
+type MergeOptions struct {
+	// Ours, Base and Theirs are written after the conflict markers.
+	Ours, Base, Theirs string
+	// Diff3 includes the base of conflicts like diff3 -m and git's
+	// merge.conflictStyle diff3.
+	Diff3 bool
+	// Resolve is called for every conflict. Resolved conflicts are
+	// replaced by the returned lines, the others are written with markers.
+	Resolve Resolver
+}
+
+// A Resolver automatically resolves a conflict of a three-way merge given
+// the conflicting lines of each version. It returns the lines to use instead,
+// or false to leave the conflict to the user.
+type Resolver func(base, ours, theirs []string) (resolved []string, ok bool)
+
+// ResolveOurs resolves conflicts in favor of ours.
+func ResolveOurs(base, ours, theirs []string) ([]string, bool) {
+	return ours, true
+}
+
+// ResolveTheirs resolves conflicts in favor of theirs.
+func ResolveTheirs(base, ours, theirs []string) ([]string, bool) {
+	return theirs, true
+}
+
+// ResolveUnion resolves conflicts by keeping the lines of both sides,
+// ours first, like git's union merge.
+func ResolveUnion(base, ours, theirs []string) ([]string, bool) {
+	return append(ours[:len(ours):len(ours)], theirs...), true
+}
+
+// WriteMerge writes the merged lines of a three-way merge with the chunks
+// returned by Merge3. Conflicts are written between the standard markers:
+//
+//	<<<<<<< ours
+//	our lines
+//	||||||| base
+//	base lines, only with Diff3 set
+//	=======
+//	their lines
+//	>>>>>>> theirs
+//
+// A missing newline at the end of a conflicting side is added to keep the
+// markers on lines of their own. A nil opts is the same as a zero value.
+func WriteMerge(w io.Writer, base, ours, theirs []string, chunks []MergeChunk, opts *MergeOptions) error {
+	var o MergeOptions
+	if opts != nil {
+		o = *opts
+	}
+	var sb strings.Builder
+	for _, c := range chunks {
+		switch c.State {
+		case Unchanged, ChangedOurs:
+			writeMergeLines(&sb, ours[c.Ours0:c.Ours1], false)
+		case ChangedTheirs:
+			writeMergeLines(&sb, theirs[c.Theirs0:c.Theirs1], false)
+		case Conflict:
+			if o.Resolve != nil {
+				lines, ok := o.Resolve(base[c.Base0:c.Base1:c.Base1], ours[c.Ours0:c.Ours1:c.Ours1], theirs[c.Theirs0:c.Theirs1:c.Theirs1])
+				if ok {
+					writeMergeLines(&sb, lines, false)
+					break
+				}
+			}
+			writeMarker(&sb, "<<<<<<<", o.Ours)
+			writeMergeLines(&sb, ours[c.Ours0:c.Ours1], true)
+			if o.Diff3 {
+				writeMarker(&sb, "|||||||", o.Base)
+				writeMergeLines(&sb, base[c.Base0:c.Base1], true)
+			}
+			sb.WriteString("=======\n")
+			writeMergeLines(&sb, theirs[c.Theirs0:c.Theirs1], true)
+			writeMarker(&sb, ">>>>>>>", o.Theirs)
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeMarker(sb *strings.Builder, marker, label string) {
+	sb.WriteString(marker)
+	if label != "" {
+		sb.WriteString(" " + label)
+	}
+	sb.WriteByte('\n')
+}
+
+func writeMergeLines(sb *strings.Builder, lines []string, terminate bool) {
+	for _, line := range lines {
+		sb.WriteString(line)
+	}
+	if terminate && len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		sb.WriteByte('\n')
+	}
+}
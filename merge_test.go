@@ -0,0 +1,146 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestMerge3(t *testing.T) {
+	base := splitLines("a\nb\nc\nd\ne\n")
+	ours := splitLines("a\nB\nc\nd\ne\nf\n")
+	theirs := splitLines("a\nb\nc\ne\n")
+	chunks := diff.Merge3(base, ours, theirs)
+	expect := []diff.MergeChunk{
+		{State: diff.Unchanged, Base0: 0, Base1: 1, Ours0: 0, Ours1: 1, Theirs0: 0, Theirs1: 1},
+		{State: diff.ChangedOurs, Base0: 1, Base1: 2, Ours0: 1, Ours1: 2, Theirs0: 1, Theirs1: 2},
+		{State: diff.Unchanged, Base0: 2, Base1: 3, Ours0: 2, Ours1: 3, Theirs0: 2, Theirs1: 3},
+		{State: diff.ChangedTheirs, Base0: 3, Base1: 4, Ours0: 3, Ours1: 4, Theirs0: 3, Theirs1: 3},
+		{State: diff.Unchanged, Base0: 4, Base1: 5, Ours0: 4, Ours1: 5, Theirs0: 3, Theirs1: 4},
+		{State: diff.ChangedOurs, Base0: 5, Base1: 5, Ours0: 5, Ours1: 6, Theirs0: 4, Theirs1: 4},
+	}
+	if !reflect.DeepEqual(chunks, expect) {
+		t.Error("expected", expect, "got", chunks)
+	}
+	var sb strings.Builder
+	if err := diff.WriteMerge(&sb, base, ours, theirs, chunks, nil); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "a\nB\nc\ne\nf\n"; sb.String() != expect {
+		t.Errorf("expected %q, got %q", expect, sb.String())
+	}
+}
+
+func TestMerge3Conflict(t *testing.T) {
+	base := splitLines("a\nb\nc\n")
+	ours := splitLines("a\nx\nc\n")
+	theirs := append(splitLines("a\ny\nc\n"), "d")
+	chunks := diff.Merge3(base, ours, theirs)
+	if len(chunks) != 4 || chunks[1].State != diff.Conflict {
+		t.Fatal("expected a conflict, got", chunks)
+	}
+	var sb strings.Builder
+	err := diff.WriteMerge(&sb, base, ours, theirs, chunks, &diff.MergeOptions{Ours: "HEAD", Theirs: "feature"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "a\n<<<<<<< HEAD\nx\n=======\ny\n>>>>>>> feature\nc\nd"
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+
+	sb.Reset()
+	err = diff.WriteMerge(&sb, base, ours, theirs, chunks, &diff.MergeOptions{Ours: "ours", Base: "base", Theirs: "theirs", Diff3: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect = "a\n<<<<<<< ours\nx\n||||||| base\nb\n=======\ny\n>>>>>>> theirs\nc\nd"
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+
+	// the same change on both sides is no conflict
+	chunks = diff.Merge3(base, ours, ours)
+	for _, c := range chunks {
+		if c.State == diff.Conflict {
+			t.Error("unexpected conflict in", chunks)
+		}
+	}
+}
+
+func TestMerge3Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		base := randomInts(r, r.Intn(12), 4)
+		ours := randomInts(r, r.Intn(12), 4)
+		theirs := randomInts(r, r.Intn(12), 4)
+		if r.Intn(4) == 0 {
+			theirs = base
+		}
+		chunks := diff.Merge3(base, ours, theirs)
+		var o, b, th int
+		var merged []int
+		for _, c := range chunks {
+			if c.Base0 != b || c.Ours0 != o || c.Theirs0 != th {
+				t.Fatal(base, ours, theirs, "chunks are not contiguous", chunks)
+			}
+			b, o, th = c.Base1, c.Ours1, c.Theirs1
+			switch c.State {
+			case diff.Unchanged:
+				if !reflect.DeepEqual(base[c.Base0:c.Base1], ours[c.Ours0:c.Ours1]) || !reflect.DeepEqual(base[c.Base0:c.Base1], theirs[c.Theirs0:c.Theirs1]) {
+					t.Fatal(base, ours, theirs, "changed region", c)
+				}
+				merged = append(merged, ours[c.Ours0:c.Ours1]...)
+			case diff.ChangedOurs:
+				merged = append(merged, ours[c.Ours0:c.Ours1]...)
+			case diff.ChangedTheirs:
+				merged = append(merged, theirs[c.Theirs0:c.Theirs1]...)
+			}
+		}
+		if b != len(base) || o != len(ours) || th != len(theirs) {
+			t.Fatal(base, ours, theirs, "chunks do not cover the inputs", chunks)
+		}
+		// merging with an unchanged side results in the other side
+		if reflect.DeepEqual(base, theirs) && len(ours) > 0 && !reflect.DeepEqual(merged, ours) {
+			t.Fatal(base, ours, "merged to", merged)
+		}
+	}
+}
+
+func TestMergeResolve(t *testing.T) {
+	base := splitLines("a\nb\nc\nd\ne\n")
+	ours := splitLines("a\nx\nc\nd\nv\n")
+	theirs := splitLines("a\ny\nc\nd\nw\n")
+	chunks := diff.Merge3(base, ours, theirs)
+	for _, test := range []struct {
+		name    string
+		resolve diff.Resolver
+		expect  string
+	}{
+		{"ours", diff.ResolveOurs, "a\nx\nc\nd\nv\n"},
+		{"theirs", diff.ResolveTheirs, "a\ny\nc\nd\nw\n"},
+		{"union", diff.ResolveUnion, "a\nx\ny\nc\nd\nv\nw\n"},
+		{"callback", func(base, ours, theirs []string) ([]string, bool) {
+			// only resolve the conflict of the second line
+			if base[0] != "b\n" {
+				return nil, false
+			}
+			return []string{"z\n"}, true
+		}, "a\nz\nc\nd\n<<<<<<<\nv\n=======\nw\n>>>>>>>\n"},
+	} {
+		var sb strings.Builder
+		if err := diff.WriteMerge(&sb, base, ours, theirs, chunks, &diff.MergeOptions{Resolve: test.resolve}); err != nil {
+			t.Fatal(err)
+		}
+		if sb.String() != test.expect {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expect, sb.String())
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// A Column is one position of a multiple alignment. Column[k] is the index of
+// the element of the k-th sequence at this position or -1 if it has none.
+type Column []int
+
+// MultiAlign aligns any number of sequences against seqs[base] and returns
+// the columns of the alignment in order. Every element of every sequence
+// appears in exactly one column and all elements in a column are equal.
+//
+// Each sequence is diffed against the base in turn. Elements missing from
+// the base are aligned progressively with the elements that the previous
+// sequences inserted at the same place, so that an element added by several
+// versions shares one column:
+//
+//	base: a b c
+//	x:    a X c
+//	y:    a X b
+//
+//	columns: [0 0 0] [-1 1 1] [1 -1 2] [2 2 -1]
+func MultiAlign[T comparable](base int, seqs ...[]T) []Column {
+	a := seqs[base]
+	newColumn := func() Column {
+		col := make(Column, len(seqs))
+		for k := range col {
+			col[k] = -1
+		}
+		return col
+	}
+	common := make([]Column, len(a))
+	for x := range common {
+		common[x] = newColumn()
+		common[x][base] = x
+	}
+	// the columns without base elements in front of a[x] and at the end
+	gaps := make([][]gapColumn[T], len(a)+1)
+	for k, b := range seqs {
+		if k == base {
+			continue
+		}
+		x, y := 0, 0
+		for _, c := range Diff(len(a), len(b), &comparables[T]{a, b}) {
+			for ; x < c.A; x, y = x+1, y+1 {
+				common[x][k] = y
+			}
+			if c.Ins > 0 {
+				gaps[c.A] = mergeGap(gaps[c.A], k, c.B, b[c.B:c.B+c.Ins], newColumn)
+			}
+			x, y = c.A+c.Del, c.B+c.Ins
+		}
+		for ; x < len(a); x, y = x+1, y+1 {
+			common[x][k] = y
+		}
+	}
+	var res []Column
+	for x, gap := range gaps {
+		for _, g := range gap {
+			res = append(res, g.col)
+		}
+		if x < len(a) {
+			res = append(res, common[x])
+		}
+	}
+	return res
+}
+
+type comparables[T comparable] struct{ a, b []T }
+
+func (d *comparables[T]) Equal(i, j int) bool { return d.a[i] == d.b[j] }
+
+// gapColumn is a column without a base element and the value of its elements.
+type gapColumn[T comparable] struct {
+	elem T
+	col  Column
+}
+
+type gapColumns[T comparable] struct {
+	gap   []gapColumn[T]
+	elems []T
+}
+
+func (d *gapColumns[T]) Equal(i, j int) bool { return d.gap[i].elem == d.elems[j] }
+
+// mergeGap aligns the elements of sequence k starting at index y with the
+// columns of gap and returns the merged columns.
+func mergeGap[T comparable](gap []gapColumn[T], k, y int, elems []T, newColumn func() Column) []gapColumn[T] {
+	res := make([]gapColumn[T], 0, len(gap)+len(elems))
+	i, j := 0, 0
+	for _, c := range Diff(len(gap), len(elems), &gapColumns[T]{gap, elems}) {
+		for ; i < c.A; i, j = i+1, j+1 {
+			gap[i].col[k] = y + j
+			res = append(res, gap[i])
+		}
+		res = append(res, gap[c.A:c.A+c.Del]...)
+		for ; j < c.B+c.Ins; j++ {
+			col := newColumn()
+			col[k] = y + j
+			res = append(res, gapColumn[T]{elems[j], col})
+		}
+		i = c.A + c.Del
+	}
+	for ; i < len(gap); i, j = i+1, j+1 {
+		gap[i].col[k] = y + j
+		res = append(res, gap[i])
+	}
+	return res
+}
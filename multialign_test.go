@@ -0,0 +1,70 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestMultiAlign(t *testing.T) {
+	base := []string{"a", "b", "c"}
+	x := []string{"a", "X", "c"}
+	y := []string{"a", "X", "b"}
+	res := diff.MultiAlign(0, base, x, y)
+	expect := []diff.Column{{0, 0, 0}, {-1, 1, 1}, {1, -1, 2}, {2, 2, -1}}
+	if !reflect.DeepEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	// any sequence can be the base
+	res = diff.MultiAlign(1, base, x, y)
+	expect = []diff.Column{{0, 0, 0}, {1, -1, -1}, {-1, 1, 1}, {-1, -1, 2}, {2, 2, -1}}
+	if !reflect.DeepEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+}
+
+func TestMultiAlignRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 300; i++ {
+		seqs := make([][]int, 1+r.Intn(5))
+		for k := range seqs {
+			seqs[k] = randomInts(r, r.Intn(15), 4)
+		}
+		base := r.Intn(len(seqs))
+		res := diff.MultiAlign(base, seqs...)
+		next := make([]int, len(seqs))
+		for _, col := range res {
+			if len(col) != len(seqs) {
+				t.Fatal(seqs, "invalid column", col)
+			}
+			elem := -1
+			for k, y := range col {
+				if y == -1 {
+					continue
+				}
+				if y != next[k] {
+					t.Fatal(seqs, "expected", next[k], "in column", col)
+				}
+				next[k]++
+				if elem != -1 && seqs[k][y] != elem {
+					t.Fatal(seqs, "different elements in column", col)
+				}
+				elem = seqs[k][y]
+			}
+			if elem == -1 {
+				t.Fatal(seqs, "empty column")
+			}
+		}
+		for k, s := range seqs {
+			if next[k] != len(s) {
+				t.Fatal(seqs, "missing elements of", k, "in", res)
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteNormal writes the changes of lines a to b in the normal format of
+// POSIX diff, with commands like "3c3" and "5a6,7" followed by the deleted
+// lines prefixed with "< " and the inserted lines prefixed with "> ".
+// The lines should end in newlines; a last line without one is marked with
+// "\ No newline at end of file".
+func WriteNormal(w io.Writer, a, b []string, changes []Change) error {
+	var sb strings.Builder
+	for _, c := range changes {
+		switch {
+		case c.Del == 0 && c.Ins == 0:
+			continue
+		case c.Del == 0:
+			sb.WriteString(strconv.Itoa(c.A) + "a" + lineRange(c.B, c.Ins))
+		case c.Ins == 0:
+			sb.WriteString(lineRange(c.A, c.Del) + "d" + strconv.Itoa(c.B))
+		default:
+			sb.WriteString(lineRange(c.A, c.Del) + "c" + lineRange(c.B, c.Ins))
+		}
+		sb.WriteByte('\n')
+		writeLines(&sb, "< ", a[c.A:c.A+c.Del])
+		if c.Del > 0 && c.Ins > 0 {
+			sb.WriteString("---\n")
+		}
+		writeLines(&sb, "> ", b[c.B:c.B+c.Ins])
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteEd writes the changes of lines a to b as an ed script like diff -e,
+// which turns a into b when run by ed. The commands are in descending order
+// so that the line numbers of a stay valid. Unlike the normal format, ed
+// scripts can not represent a missing newline at the end of the last line.
+func WriteEd(w io.Writer, a, b []string, changes []Change) error {
+	var sb strings.Builder
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		switch {
+		case c.Del == 0 && c.Ins == 0:
+			continue
+		case c.Del == 0:
+			sb.WriteString(strconv.Itoa(c.A) + "a\n")
+		case c.Ins == 0:
+			sb.WriteString(lineRange(c.A, c.Del) + "d\n")
+			continue
+		default:
+			sb.WriteString(lineRange(c.A, c.Del) + "c\n")
+		}
+		lines := b[c.B : c.B+c.Ins]
+		for j, line := range lines {
+			line = strings.TrimSuffix(line, "\n")
+			if line != "." {
+				sb.WriteString(line + "\n")
+				continue
+			}
+			// a lone dot ends the input mode; write two and remove one
+			sb.WriteString("..\n.\ns/.//\n")
+			if j == len(lines)-1 {
+				break
+			}
+			sb.WriteString("a\n")
+		}
+		if last := lines[len(lines)-1]; strings.TrimSuffix(last, "\n") != "." {
+			sb.WriteString(".\n")
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// lineRange returns the one based line numbers of the n lines at start,
+// as a single number or a comma separated range. Without lines it returns
+// the number of the line before.
+func lineRange(start, n int) string {
+	if n <= 1 {
+		return strconv.Itoa(start + n)
+	}
+	return strconv.Itoa(start+1) + "," + strconv.Itoa(start+n)
+}
+
+// writeLines writes lines with prefix, marking a missing final newline.
+func writeLines(sb *strings.Builder, prefix string, lines []string) {
+	for _, line := range lines {
+		sb.WriteString(prefix)
+		sb.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
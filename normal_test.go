@@ -0,0 +1,70 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestWriteNormal(t *testing.T) {
+	a := splitLines("a\nb\nc\nd\ne\nf\n")
+	b := append(splitLines("x\na\nB\nc\ne\nf\n"), "g")
+	var sb strings.Builder
+	if err := diff.WriteNormal(&sb, a, b, diff.Lines(a, b)); err != nil {
+		t.Fatal(err)
+	}
+	expect := "" +
+		"0a1\n" +
+		"> x\n" +
+		"2c3\n" +
+		"< b\n" +
+		"---\n" +
+		"> B\n" +
+		"4d4\n" +
+		"< d\n" +
+		"6a7\n" +
+		"> g\n" +
+		"\\ No newline at end of file\n"
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+}
+
+func TestWriteNormalRanges(t *testing.T) {
+	a := splitLines("a\nb\nc\nd\n")
+	b := splitLines("a\nx\ny\nz\n")
+	var sb strings.Builder
+	if err := diff.WriteNormal(&sb, a, b, diff.Lines(a, b)); err != nil {
+		t.Fatal(err)
+	}
+	expect := "2,4c2,4\n< b\n< c\n< d\n---\n> x\n> y\n> z\n"
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+}
+
+func TestWriteEd(t *testing.T) {
+	a := splitLines("a\nb\nc\nd\ne\nf\n")
+	b := splitLines("x\na\nB\n.\nc\ne\nf\n")
+	var sb strings.Builder
+	if err := diff.WriteEd(&sb, a, b, diff.Lines(a, b)); err != nil {
+		t.Fatal(err)
+	}
+	// the same as GNU diff -e
+	expect := "" +
+		"4d\n" +
+		"2c\n" +
+		"B\n" +
+		"..\n.\ns/.//\n" +
+		"0a\n" +
+		"x\n" +
+		".\n"
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+}
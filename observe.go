@@ -0,0 +1,81 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "time"
+
+// A Phase is a step of computing a diff.
+type Phase int
+
+const (
+	// Prepare resets the buffers and hashes the elements of a Hasher.
+	Prepare Phase = iota + 1
+	// Search finds the differences with the selected algorithm.
+	Search
+	// Collect collects the changes and runs the post-processing passes.
+	Collect
+)
+
+func (p Phase) String() string {
+	switch p {
+	case Prepare:
+		return "prepare"
+	case Search:
+		return "search"
+	case Collect:
+		return "collect"
+	}
+	return "unknown"
+}
+
+// An Observer receives events of every diff, for example to export metrics
+// or to find inputs that are expensive to diff. Its methods are called by
+// the goroutine computing the diff.
+type Observer interface {
+	// Phase is called at the end of every phase with the time it took.
+	Phase(p Phase, elapsed time.Duration)
+	// Searched is called after the search with the number of calls of
+	// Data.Equal and the largest number of edits d a search reached.
+	// For Myers d is the largest of the searches for a middle snake,
+	// each of which covers up to 2d edits of its part of the inputs.
+	Searched(comparisons, d int)
+}
+
+// WithObserver reports the events of every diff to obs.
+// Diffs served from a cache only report the Collect phase.
+func WithObserver(obs Observer) Option {
+	return func(o *options) { o.observer = obs }
+}
+
+// counted wraps data and counts the calls of Equal.
+type counted struct {
+	data Data
+	n    int
+}
+
+func (c *counted) Equal(i, j int) bool {
+	c.n++
+	return c.data.Equal(i, j)
+}
+
+// phase reports the end of phase p that started at *start to the observer
+// and starts the next one.
+func (d *Differ) phase(p Phase, start *time.Time) {
+	if d.opts.observer == nil {
+		return
+	}
+	now := time.Now()
+	d.opts.observer.Phase(p, now.Sub(*start))
+	*start = now
+}
+
+// searched reports the end of the search to the observer.
+func (d *Differ) searched(start *time.Time) {
+	if d.opts.observer == nil {
+		return
+	}
+	d.phase(Search, start)
+	d.opts.observer.Searched(d.c.counted.n, d.c.maxD)
+}
@@ -0,0 +1,67 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/echlebek/diff"
+)
+
+// recorder records the events of an observer.
+type recorder struct {
+	phases      []diff.Phase
+	comparisons int
+	d           int
+}
+
+func (r *recorder) Phase(p diff.Phase, elapsed time.Duration) {
+	if elapsed < 0 {
+		panic("negative duration")
+	}
+	r.phases = append(r.phases, p)
+}
+
+func (r *recorder) Searched(comparisons, d int) {
+	r.comparisons, r.d = comparisons, d
+}
+
+func TestWithObserver(t *testing.T) {
+	a := []int{1, 2, 3, 1, 2, 2, 1}
+	b := []int{3, 2, 1, 2, 1, 3}
+	for _, test := range []struct {
+		opts []diff.Option
+		d    int
+	}{
+		{nil, 3},
+		{[]diff.Option{diff.WithAlgorithm(diff.Wu)}, 5},
+		{[]diff.Option{diff.WithTieBreak(diff.DeletionsFirst)}, 5},
+	} {
+		r := &recorder{}
+		data := &countingInts{ints: ints{a, b}}
+		res := diff.Diff(len(a), len(b), data, append(test.opts, diff.WithObserver(r))...)
+		if diff.Check(a, b, res) != nil {
+			t.Fatal("invalid result", res)
+		}
+		if len(r.phases) != 3 || r.phases[0] != diff.Prepare || r.phases[1] != diff.Search || r.phases[2] != diff.Collect {
+			t.Error("expected all phases in order, got", r.phases)
+		}
+		if r.comparisons != data.calls {
+			t.Error("expected", data.calls, "comparisons, got", r.comparisons)
+		}
+		if r.d != test.d {
+			t.Error(test.opts, "expected d", test.d, "got", r.d)
+		}
+	}
+}
+
+func TestPhaseString(t *testing.T) {
+	for p, s := range map[diff.Phase]string{diff.Prepare: "prepare", diff.Search: "search", diff.Collect: "collect", 0: "unknown"} {
+		if p.String() != s {
+			t.Error("expected", s, "got", p.String())
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import gocontext "context"
+
+// An Option configures how Diff computes differences.
+// Without options Diff returns a minimal result.
+type Option func(*options)
+
+type options struct {
+	heuristic   bool
+	stripCR     bool
+	algorithm   Algorithm
+	maxDistance int
+	ctx         gocontext.Context
+	cleanup     []func([]Change) []Change
+	tieBreak    TieBreak
+	slide       Slide
+	cache       Cache
+	maxChanges  int
+	maxMemory   int
+	observer    Observer
+	trace       *Trace
+	decompress  bool
+	lineKey     func(string) string
+}
+
+// WithHeuristic trades minimality for speed on large and very different inputs,
+// like GNU diff without --minimal. Once the search for a split point grows too
+// expensive, the furthest reaching path is used instead of the optimal one.
+func WithHeuristic() Option {
+	return func(o *options) { o.heuristic = true }
+}
+
+// WithStripTrailingCR makes line diffs like Lines and Files treat lines
+// ending in "\r\n" as equal to lines ending in "\n", like GNU diff's
+// --strip-trailing-cr. Other data is not affected.
+func WithStripTrailingCR() Option {
+	return func(o *options) { o.stripCR = true }
+}
+
+// WithLineKey makes line diffs like Lines compare the keys that key returns
+// for the lines instead of the lines themselves, for example
+// strings.ToLower to ignore case. key is called once per line, after a
+// trailing carriage return is stripped. Other data is not affected.
+func WithLineKey(key func(line string) string) Option {
+	return func(o *options) { o.lineKey = key }
+}
+
+// An Algorithm computes the differences of two sequences.
+// All algorithms return minimal results unless WithHeuristic is used.
+type Algorithm int
+
+const (
+	// Myers is the default O(ND) algorithm by Eugene Myers.
+	Myers Algorithm = iota
+	// Wu is the O(NP) algorithm by Sun Wu, Udi Manber and Gene Myers.
+	// It is much faster than Myers if the lengths of the sequences differ a lot.
+	// It ignores WithHeuristic.
+	Wu
+)
+
+// WithAlgorithm selects the algorithm used to compute the differences.
+func WithAlgorithm(a Algorithm) Option {
+	return func(o *options) { o.algorithm = a }
+}
+
+// WithMaxDistance limits the cost of the search for every split point to d
+// edits. Beyond that the furthest reaching path is used like WithHeuristic,
+// so that the result is no longer minimal but the time spent on very
+// different inputs is bounded. The Wu algorithm ignores it.
+func WithMaxDistance(d int) Option {
+	return func(o *options) { o.maxDistance = d }
+}
+
+// WithContext stops the search for a minimal result once ctx is done.
+// The remaining differences are then reported as replacing everything
+// in between, and Differ.Err returns the error of ctx. Use a Differ to
+// tell such results from minimal ones.
+func WithContext(ctx gocontext.Context) Option {
+	return func(o *options) { o.ctx = ctx }
+}
+
+// WithMaxChanges returns at most the first n changes, so that callers can
+// show that a diff is too large instead of rendering all of it.
+// Differ.Truncated reports whether changes were dropped.
+func WithMaxChanges(n int) Option {
+	return func(o *options) { o.maxChanges = n }
+}
+
+// WithCleanup runs the cleanup passes on the changes in order before they
+// are returned, for example
+//
+//	diff.WithCleanup(func(c []diff.Change) []diff.Change { return diff.Efficient(4, c) })
+func WithCleanup(passes ...func([]Change) []Change) Option {
+	return func(o *options) { o.cleanup = append(o.cleanup, passes...) }
+}
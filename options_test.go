@@ -0,0 +1,176 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func countEdits(changes []diff.Change) int {
+	d := 0
+	for _, c := range changes {
+		d += c.Del + c.Ins
+	}
+	return d
+}
+
+func TestWithHeuristic(t *testing.T) {
+	// small inputs are never too expensive
+	for _, test := range tests {
+		expect := diff.Ints(test.a, test.b)
+		res := diff.Diff(len(test.a), len(test.b), &ints{test.a, test.b}, diff.WithHeuristic())
+		if !diffsEqual(res, expect) {
+			t.Error(test.name, "expected", expect, "got", res)
+		}
+	}
+
+	r := rand.New(rand.NewSource(1))
+	a := randomInts(r, 5000, 1000)
+	b := randomInts(r, 5000, 1000)
+	for i := 0; i < len(a); i += 10 {
+		b[i] = a[i]
+	}
+	data := &ints{a, b}
+	minimal := diff.Diff(len(a), len(b), data)
+	fast := diff.Diff(len(a), len(b), data, diff.WithHeuristic())
+	if diff.Check(a, b, fast) != nil {
+		t.Fatal("heuristic result does not transform a into b")
+	}
+	if countEdits(fast) < countEdits(minimal) {
+		t.Error("heuristic result", countEdits(fast), "is smaller than minimal", countEdits(minimal))
+	}
+}
+
+func BenchmarkWithHeuristic(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	d := &ints{randomInts(r, 6000, 1000), randomInts(r, 6000, 1000)}
+	for i := 0; i < b.N; i++ {
+		diff.Diff(len(d.a), len(d.b), d, diff.WithHeuristic())
+	}
+}
+
+func TestWithMaxDistance(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a := randomInts(r, 2000, 100)
+	b := randomInts(r, 2000, 100)
+	data := &ints{a, b}
+	minimal := diff.Diff(len(a), len(b), data)
+	bounded := diff.Diff(len(a), len(b), data, diff.WithMaxDistance(10))
+	if diff.Check(a, b, bounded) != nil {
+		t.Fatal("bounded result does not transform a into b")
+	}
+	if countEdits(bounded) <= countEdits(minimal) {
+		t.Error("expected a bounded result larger than", countEdits(minimal), "got", countEdits(bounded))
+	}
+	// a distance that is never reached keeps the minimal result
+	if res := diff.Diff(len(a), len(b), data, diff.WithMaxDistance(len(a)+len(b))); !diffsEqual(res, minimal) {
+		t.Error("expected the minimal result")
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a := randomInts(r, 500, 10)
+	b := randomInts(r, 500, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	for _, alg := range []diff.Algorithm{diff.Myers, diff.Wu} {
+		d := diff.New(diff.WithContext(ctx), diff.WithAlgorithm(alg))
+		res := d.Diff(len(a), len(b), &ints{a, b})
+		if d.Err() != nil || countEdits(res) != countEdits(diff.Ints(a, b)) {
+			t.Error(alg, "expected a minimal result, got", d.Err())
+		}
+		cancel()
+		res = d.Diff(len(a), len(b), &ints{a, b})
+		if d.Err() != context.Canceled {
+			t.Error(alg, "expected a canceled error, got", d.Err())
+		}
+		if diff.Check(a, b, res) != nil {
+			t.Error(alg, "canceled result does not transform a into b")
+		}
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	cancel()
+}
+
+func TestWithCleanup(t *testing.T) {
+	a := []int{1, 2, 3, 4, 5}
+	b := []int{0, 2, 0, 4, 0}
+	merge := func(c []diff.Change) []diff.Change { return diff.Granular(1, c) }
+	d := diff.New(diff.WithCleanup(merge))
+	expect := []diff.Change{{A: 0, B: 0, Del: 5, Ins: 5}}
+	if res := d.Diff(len(a), len(b), &ints{a, b}); !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	var res []diff.Change
+	d.Each(len(a), len(b), &ints{a, b}, func(c diff.Change) bool {
+		res = append(res, c)
+		return true
+	})
+	if !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+}
+
+func TestWithMaxChanges(t *testing.T) {
+	a := []int{1, 2, 3, 4, 5, 6}
+	b := []int{0, 2, 7, 4, 8, 6}
+	all := diff.Ints(a, b)
+	for _, max := range []int{0, 1, 2, 3, 4} {
+		d := diff.New(diff.WithMaxChanges(max))
+		res := d.Diff(len(a), len(b), &ints{a, b})
+		expect, truncated := all, false
+		if max > 0 && max < len(all) {
+			expect, truncated = all[:max], true
+		}
+		if !diffsEqual(res, expect) || d.Truncated() != truncated {
+			t.Error(max, "expected", expect, truncated, "got", res, d.Truncated())
+		}
+		var each []diff.Change
+		d.Each(len(a), len(b), &ints{a, b}, func(c diff.Change) bool {
+			each = append(each, c)
+			return true
+		})
+		if !diffsEqual(each, expect) || d.Truncated() != truncated {
+			t.Error(max, "expected", expect, truncated, "from Each, got", each, d.Truncated())
+		}
+	}
+}
+
+func TestWithMaxMemory(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a := randomInts(r, 2000, 100)
+	b := randomInts(r, 2000, 100)
+	copy(b[:100], a[:100])
+	data := &ints{a, b}
+	for _, opts := range [][]diff.Option{
+		nil,
+		{diff.WithAlgorithm(diff.Wu)},
+		{diff.WithTieBreak(diff.DeletionsFirst)},
+	} {
+		d := diff.New(append(opts, diff.WithMaxMemory(1000))...)
+		res := d.Diff(len(a), len(b), data)
+		var merr *diff.MemoryError
+		if !errors.As(d.Err(), &merr) || merr.Limit != 1000 || merr.Need <= 1000 {
+			t.Fatal("expected a memory error, got", d.Err())
+		}
+		if diff.Check(a, b, res) != nil {
+			t.Fatal("invalid result", res)
+		}
+
+		d = diff.New(append(opts, diff.WithMaxMemory(1<<30))...)
+		res = d.Diff(len(a), len(b), data)
+		if d.Err() != nil {
+			t.Fatal("expected no error, got", d.Err())
+		}
+		if expect := diff.Diff(len(a), len(b), data, opts...); !diffsEqual(res, expect) {
+			t.Error("expected the unlimited result")
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// An Op classifies a change.
+type Op int
+
+const (
+	// Insert only inserts elements of b.
+	Insert Op = iota + 1
+	// Delete only deletes elements of a.
+	Delete
+	// Replace replaces elements of a with elements of b.
+	Replace
+)
+
+func (op Op) String() string {
+	switch op {
+	case Insert:
+		return "insert"
+	case Delete:
+		return "delete"
+	case Replace:
+		return "replace"
+	}
+	return "unknown"
+}
+
+// A Pair is a classified change with the ranges a[A0:A1] and b[B0:B1].
+type Pair struct {
+	Op     Op
+	A0, A1 int
+	B0, B1 int
+}
+
+// Pairs classifies changes as inserts, deletes or replacements.
+// Changes that neither delete nor insert are dropped.
+func Pairs(changes []Change) []Pair {
+	pairs := make([]Pair, 0, len(changes))
+	for _, c := range changes {
+		var op Op
+		switch {
+		case c.Del > 0 && c.Ins > 0:
+			op = Replace
+		case c.Del > 0:
+			op = Delete
+		case c.Ins > 0:
+			op = Insert
+		default:
+			continue
+		}
+		pairs = append(pairs, Pair{op, c.A, c.A + c.Del, c.B, c.B + c.Ins})
+	}
+	return pairs
+}
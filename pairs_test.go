@@ -0,0 +1,35 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestPairs(t *testing.T) {
+	changes := []diff.Change{
+		{A: 0, B: 0, Del: 0, Ins: 2},
+		{A: 3, B: 5, Del: 1, Ins: 0},
+		{A: 6, B: 7, Del: 2, Ins: 3},
+		{A: 9, B: 11, Del: 0, Ins: 0},
+	}
+	expect := []diff.Pair{
+		{Op: diff.Insert, A0: 0, A1: 0, B0: 0, B1: 2},
+		{Op: diff.Delete, A0: 3, A1: 4, B0: 5, B1: 5},
+		{Op: diff.Replace, A0: 6, A1: 8, B0: 7, B1: 10},
+	}
+	if res := diff.Pairs(changes); !reflect.DeepEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	if res := diff.Pairs(nil); len(res) != 0 {
+		t.Error("expected no pairs, got", res)
+	}
+	if s := diff.Replace.String(); s != "replace" {
+		t.Error("expected replace, got", s)
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A FilePatch holds the changes of one file of a Patch.
+// It is encoded in JSON with the field names oldPath, newPath, oldMode,
+// newMode, binary and hunks, and as text in the format of git diff.
+type FilePatch struct {
+	// OldPath and NewPath are the paths of the file before and after the
+	// change, without the a/ and b/ prefixes. OldPath is empty for added
+	// files and NewPath is empty for deleted files.
+	OldPath string `json:"oldPath,omitempty"`
+	NewPath string `json:"newPath,omitempty"`
+	// OldMode and NewMode are the git file modes like 0100644, 0 if unknown.
+	OldMode uint32 `json:"oldMode,omitempty"`
+	NewMode uint32 `json:"newMode,omitempty"`
+	// Binary is set for binary files, whose changes are not shown.
+	Binary bool   `json:"binary,omitempty"`
+	Hunks  []Hunk `json:"hunks"`
+}
+
+// A Patch holds the changes of many files like the output of git diff.
+// It is encoded in JSON as an array of file patches
+// and as text in the format of git diff.
+type Patch []FilePatch
+
+// NewFilePatch returns the patch of the changes of lines a to b with
+// context common lines around the changes.
+func NewFilePatch(oldPath, newPath string, a, b []string, changes []Change, context int) FilePatch {
+	return FilePatch{
+		OldPath: oldPath,
+		NewPath: newPath,
+		Hunks:   lineHunks(a, b, changes, context),
+	}
+}
+
+// WritePatch writes the patch in the format of git diff,
+// with a diff --git header for every file.
+func WritePatch(w io.Writer, p Patch) error {
+	var sb strings.Builder
+	for _, f := range p {
+		oldPath, newPath := f.OldPath, f.NewPath
+		if oldPath == "" {
+			oldPath = newPath
+		}
+		if newPath == "" {
+			newPath = oldPath
+		}
+		sb.WriteString("diff --git a/" + oldPath + " b/" + newPath + "\n")
+		switch {
+		case f.OldPath == "":
+			writeMode(&sb, "new file mode ", f.NewMode)
+		case f.NewPath == "":
+			writeMode(&sb, "deleted file mode ", f.OldMode)
+		case f.OldMode != f.NewMode && f.OldMode != 0 && f.NewMode != 0:
+			writeMode(&sb, "old mode ", f.OldMode)
+			writeMode(&sb, "new mode ", f.NewMode)
+		}
+		if f.OldPath != "" && f.NewPath != "" && f.OldPath != f.NewPath {
+			sb.WriteString("rename from " + f.OldPath + "\n")
+			sb.WriteString("rename to " + f.NewPath + "\n")
+		}
+		oldName, newName := "a/"+f.OldPath, "b/"+f.NewPath
+		if f.OldPath == "" {
+			oldName = "/dev/null"
+		}
+		if f.NewPath == "" {
+			newName = "/dev/null"
+		}
+		if f.Binary {
+			sb.WriteString("Binary files " + oldName + " and " + newName + " differ\n")
+			continue
+		}
+		if len(f.Hunks) == 0 {
+			continue
+		}
+		sb.WriteString("--- " + oldName + "\n")
+		sb.WriteString("+++ " + newName + "\n")
+		for _, h := range f.Hunks {
+			writeHunk(&sb, h)
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeMode(sb *strings.Builder, prefix string, mode uint32) {
+	if mode != 0 {
+		sb.WriteString(prefix + fmt.Sprintf("%06o", mode) + "\n")
+	}
+}
+
+// ParsePatch reads a patch in the format of git diff or of diff -u,
+// for which every pair of ---/+++ lines starts a file. Text before the
+// first file, like a commit message, is skipped. The hunks are returned
+// with their Changes and Lines set.
+func ParsePatch(r io.Reader) (Patch, error) {
+	p := &patchParser{s: bufio.NewScanner(r)}
+	p.s.Buffer(nil, 1<<30)
+	p.next()
+	for p.ok {
+		if err := p.file(); err != nil {
+			return nil, fmt.Errorf("diff: line %d: %v", p.n, err)
+		}
+	}
+	if err := p.s.Err(); err != nil {
+		return nil, err
+	}
+	return p.patch, nil
+}
+
+type patchParser struct {
+	s     *bufio.Scanner
+	line  string // current line
+	ok    bool   // current line is valid
+	n     int    // current line number
+	patch Patch
+}
+
+// next advances to the next line with its newline.
+func (p *patchParser) next() bool {
+	p.ok = p.s.Scan()
+	if p.ok {
+		p.n++
+		p.line = p.s.Text() + "\n"
+	}
+	return p.ok
+}
+
+// file parses a file starting at the current line if there is one
+// and advances past it.
+func (p *patchParser) file() error {
+	var f FilePatch
+	switch {
+	case strings.HasPrefix(p.line, "diff --git "):
+		f.OldPath, f.NewPath = gitPaths(strings.TrimSuffix(p.line[len("diff --git "):], "\n"))
+		if !p.next() {
+			break
+		}
+		if err := p.extendedHeader(&f); err != nil {
+			return err
+		}
+	case strings.HasPrefix(p.line, "--- "):
+	default:
+		p.next()
+		return nil
+	}
+	if p.ok && strings.HasPrefix(p.line, "--- ") {
+		f.OldPath = patchPath(p.line[4:], "a/")
+		if !p.next() || !strings.HasPrefix(p.line, "+++ ") {
+			return fmt.Errorf("expected +++ line")
+		}
+		f.NewPath = patchPath(p.line[4:], "b/")
+		p.next()
+		for p.ok && strings.HasPrefix(p.line, "@@ ") {
+			h, err := p.hunk()
+			if err != nil {
+				return err
+			}
+			f.Hunks = append(f.Hunks, h)
+		}
+	}
+	p.patch = append(p.patch, f)
+	return nil
+}
+
+// extendedHeader parses the lines after diff --git up to the first
+// line that is not part of the header.
+func (p *patchParser) extendedHeader(f *FilePatch) error {
+	for ; p.ok; p.next() {
+		line := strings.TrimSuffix(p.line, "\n")
+		var err error
+		switch {
+		case strings.HasPrefix(line, "new file mode "):
+			f.OldPath = ""
+			f.NewMode, err = parseMode(line[len("new file mode "):])
+		case strings.HasPrefix(line, "deleted file mode "):
+			f.NewPath = ""
+			f.OldMode, err = parseMode(line[len("deleted file mode "):])
+		case strings.HasPrefix(line, "old mode "):
+			f.OldMode, err = parseMode(line[len("old mode "):])
+		case strings.HasPrefix(line, "new mode "):
+			f.NewMode, err = parseMode(line[len("new mode "):])
+		case strings.HasPrefix(line, "rename from "):
+			f.OldPath = line[len("rename from "):]
+		case strings.HasPrefix(line, "rename to "):
+			f.NewPath = line[len("rename to "):]
+		case strings.HasPrefix(line, "Binary files "):
+			f.Binary = true
+		case strings.HasPrefix(line, "index "),
+			strings.HasPrefix(line, "similarity index "),
+			strings.HasPrefix(line, "dissimilarity index "),
+			strings.HasPrefix(line, "copy from "),
+			strings.HasPrefix(line, "copy to "):
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hunk parses the hunk starting at the current @@ line.
+func (p *patchParser) hunk() (Hunk, error) {
+	var h Hunk
+	header := strings.TrimSuffix(p.line, "\n")
+	end := strings.Index(header[3:], " @@")
+	if end < 0 {
+		return h, fmt.Errorf("invalid hunk header %q", header)
+	}
+	h.Section = strings.TrimPrefix(header[3+end+3:], " ")
+	ranges := strings.Fields(header[3 : 3+end])
+	if len(ranges) != 2 || ranges[0][0] != '-' || ranges[1][0] != '+' {
+		return h, fmt.Errorf("invalid hunk header %q", header)
+	}
+	var err error
+	if h.A, h.LenA, err = parseRange(ranges[0][1:]); err != nil {
+		return h, err
+	}
+	if h.B, h.LenB, err = parseRange(ranges[1][1:]); err != nil {
+		return h, err
+	}
+	x, y := h.A, h.B
+	for del, ins := h.LenA, h.LenB; (del > 0 || ins > 0) && p.next(); {
+		line := p.line
+		switch line[0] {
+		case ' ', '\n':
+			if line[0] == '\n' {
+				// some tools strip the space of empty common lines
+				line = " \n"
+			}
+			x, y, del, ins = x+1, y+1, del-1, ins-1
+		case '-':
+			h.Changes = appendChange(h.Changes, Change{x, y, 1, 0})
+			x, del = x+1, del-1
+		case '+':
+			h.Changes = appendChange(h.Changes, Change{x, y, 0, 1})
+			y, ins = y+1, ins-1
+		case '\\':
+			p.noNewline(&h)
+			continue
+		default:
+			return h, fmt.Errorf("invalid hunk line %q", strings.TrimSuffix(line, "\n"))
+		}
+		if del < 0 || ins < 0 {
+			return h, fmt.Errorf("hunk %q is longer than its header", header)
+		}
+		h.Lines = append(h.Lines, line)
+	}
+	if x-h.A != h.LenA || y-h.B != h.LenB {
+		return h, fmt.Errorf("hunk %q is shorter than its header", header)
+	}
+	p.next()
+	if p.ok && strings.HasPrefix(p.line, "\\") {
+		p.noNewline(&h)
+		p.next()
+	}
+	return h, nil
+}
+
+// noNewline removes the newline of the last line of h.
+func (p *patchParser) noNewline(h *Hunk) {
+	if n := len(h.Lines); n > 0 {
+		h.Lines[n-1] = strings.TrimSuffix(h.Lines[n-1], "\n")
+	}
+}
+
+// parseRange parses a unified range like "3,4" or "3" into a position and length.
+func parseRange(s string) (start, n int, err error) {
+	n = 1
+	if i := strings.IndexByte(s, ','); i >= 0 {
+		if n, err = strconv.Atoi(s[i+1:]); err != nil {
+			return 0, 0, fmt.Errorf("invalid range %q", s)
+		}
+		s = s[:i]
+	}
+	if start, err = strconv.Atoi(s); err != nil || start < 0 || n < 0 {
+		return 0, 0, fmt.Errorf("invalid range %q", s)
+	}
+	if n > 0 {
+		start--
+	}
+	return start, n, nil
+}
+
+func parseMode(s string) (uint32, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid mode %q", s)
+	}
+	return uint32(mode), nil
+}
+
+// gitPaths returns the paths of a diff --git line like "a/x b/x".
+func gitPaths(s string) (oldPath, newPath string) {
+	// both paths are equal unless the file is renamed
+	if n := len(s); n%2 == 1 && strings.HasPrefix(s, "a/") && s[n/2:n/2+3] == " b/" && s[2:n/2] == s[n/2+3:] {
+		return s[2 : n/2], s[n/2+3:]
+	}
+	if i := strings.Index(s, " b/"); i >= 0 {
+		return strings.TrimPrefix(s[:i], "a/"), s[i+3:]
+	}
+	return s, s
+}
+
+// patchPath returns the path of a ---/+++ line without prefix and time stamp.
+func patchPath(s, prefix string) string {
+	s = strings.TrimSuffix(s, "\n")
+	if i := strings.IndexByte(s, '\t'); i >= 0 {
+		s = s[:i]
+	}
+	if s == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(s, prefix)
+}
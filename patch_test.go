@@ -0,0 +1,161 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+// gitPatch is the output of git diff -M.
+const gitPatch = `diff --git a/f.txt b/f.txt
+old mode 100644
+new mode 100755
+index de98044..7be73ce
+--- a/f.txt
++++ b/f.txt
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+diff --git a/mv.txt b/moved.txt
+similarity index 79%
+rename from mv.txt
+rename to moved.txt
+index f384549..b2f931a 100644
+--- a/mv.txt
++++ b/moved.txt
+@@ -2,3 +2,4 @@ one
+ two
+ three
+ four
++five
+diff --git a/new.txt b/new.txt
+new file mode 100644
+index 0000000..3e75765
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1 @@
++new
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+index 587be6b..0000000
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-x
+diff --git a/img.png b/img.png
+index 1111111..2222222 100644
+Binary files a/img.png and b/img.png differ
+`
+
+func TestParsePatch(t *testing.T) {
+	p, err := diff.ParsePatch(strings.NewReader(gitPatch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := diff.Patch{
+		{OldPath: "f.txt", NewPath: "f.txt", OldMode: 0100644, NewMode: 0100755, Hunks: []diff.Hunk{
+			{A: 0, B: 0, LenA: 3, LenB: 3,
+				Changes: []diff.Change{{A: 1, B: 1, Del: 1, Ins: 1}},
+				Lines:   []string{" a\n", "-b\n", "+B\n", " c\n"}},
+		}},
+		{OldPath: "mv.txt", NewPath: "moved.txt", Hunks: []diff.Hunk{
+			{A: 1, B: 1, LenA: 3, LenB: 4,
+				Changes: []diff.Change{{A: 4, B: 4, Del: 0, Ins: 1}},
+				Lines:   []string{" two\n", " three\n", " four\n", "+five\n"},
+				Section: "one"},
+		}},
+		{OldPath: "", NewPath: "new.txt", NewMode: 0100644, Hunks: []diff.Hunk{
+			{A: 0, B: 0, LenA: 0, LenB: 1,
+				Changes: []diff.Change{{A: 0, B: 0, Del: 0, Ins: 1}},
+				Lines:   []string{"+new\n"}},
+		}},
+		{OldPath: "old.txt", NewPath: "", OldMode: 0100644, Hunks: []diff.Hunk{
+			{A: 0, B: 0, LenA: 1, LenB: 0,
+				Changes: []diff.Change{{A: 0, B: 0, Del: 1, Ins: 0}},
+				Lines:   []string{"-x\n"}},
+		}},
+		{OldPath: "img.png", NewPath: "img.png", Binary: true},
+	}
+	if !reflect.DeepEqual(p, expect) {
+		t.Errorf("expected\n%+v\ngot\n%+v", expect, p)
+	}
+}
+
+func TestWritePatch(t *testing.T) {
+	p, err := diff.ParsePatch(strings.NewReader(gitPatch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	if err := diff.WritePatch(&sb, p); err != nil {
+		t.Fatal(err)
+	}
+	// without index and similarity lines
+	var expect []string
+	for _, line := range strings.SplitAfter(gitPatch, "\n") {
+		if strings.HasPrefix(line, "index ") || strings.HasPrefix(line, "similarity ") {
+			continue
+		}
+		expect = append(expect, line)
+	}
+	if sb.String() != strings.Join(expect, "") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expect, ""), sb.String())
+	}
+	// parsing again gives the same patch
+	q, err := diff.ParsePatch(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p, q) {
+		t.Errorf("expected\n%+v\ngot\n%+v", p, q)
+	}
+}
+
+func TestNewFilePatch(t *testing.T) {
+	a := splitLines("a\nb\nc\n")
+	b := append(splitLines("a\nB\nc\n"), "d")
+	p := diff.Patch{diff.NewFilePatch("f", "f", a, b, diff.Lines(a, b), 3)}
+	var sb strings.Builder
+	if err := diff.WritePatch(&sb, p); err != nil {
+		t.Fatal(err)
+	}
+	expect := "diff --git a/f b/f\n--- a/f\n+++ b/f\n@@ -1,3 +1,4 @@\n a\n-b\n+B\n c\n+d\n\\ No newline at end of file\n"
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+	q, err := diff.ParsePatch(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p, q) {
+		t.Errorf("expected\n%+v\ngot\n%+v", p, q)
+	}
+}
+
+func TestParsePatchUnified(t *testing.T) {
+	p, err := diff.ParsePatch(strings.NewReader("some text\n--- a.txt\t2012-01-01\n+++ b.txt\t2012-01-02\n@@ -1 +1 @@\n-a\n+b\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p) != 1 || p[0].OldPath != "a.txt" || p[0].NewPath != "b.txt" || len(p[0].Hunks) != 1 {
+		t.Fatal("unexpected patch", p)
+	}
+	for _, bad := range []string{
+		"--- a\n",
+		"--- a\n+++ b\n@@ -1,2 +1 @@\n-a\n",
+		"--- a\n+++ b\n@@ -1 +1 @@\n*a\n",
+		"--- a\n+++ b\n@@ -x +1 @@\n",
+	} {
+		if _, err := diff.ParsePatch(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// A Preset bundles the tokenization, options and cleanup suited
+// to a common kind of text.
+type Preset int
+
+const (
+	// PresetCode diffs lines and slides changes to natural boundaries
+	// with IndentHeuristic.
+	PresetCode Preset = iota
+	// PresetProse diffs words and merges changes separated by a single
+	// character, so that replaced phrases read as one change.
+	PresetProse
+	// PresetLogs diffs lines and gives up on minimal results for large,
+	// very different inputs with WithHeuristic.
+	PresetLogs
+	// PresetData diffs lines and returns a minimal result.
+	PresetData
+)
+
+// Diff returns the differences of two strings using the preset.
+// The positions and lengths of the changes are byte offsets into a and b.
+func (p Preset) Diff(a, b string) []Change {
+	switch p {
+	case PresetCode:
+		la, lb := splitLines(a), splitLines(b)
+		changes := lineChanges(la, lb)
+		return byteOffsets(la, lb, IndentHeuristic(la, lb, changes))
+	case PresetProse:
+		return Granular(1, Words(a, b))
+	case PresetLogs:
+		return tokenChanges(splitLines(a), splitLines(b), WithHeuristic())
+	default:
+		return tokenChanges(splitLines(a), splitLines(b))
+	}
+}
+
+// splitLines splits s after every newline.
+// The last line is only missing its newline if s does not end in one.
+func splitLines(s string) []string {
+	var lines []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\n' {
+			lines = append(lines, s[start:i+1])
+			start = i + 1
+		}
+	}
+	if start < len(s) {
+		lines = append(lines, s[start:])
+	}
+	return lines
+}
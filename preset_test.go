@@ -0,0 +1,34 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestPresets(t *testing.T) {
+	code := [2]string{"foo() {\n}\n\nbar() {\n}\n", "foo() {\n}\n\nbaz() {\n}\n\nbar() {\n}\n"}
+	prose := [2]string{"the cat sat on the mat", "the dog lay on the mat"}
+	logs := [2]string{"start\nok\nstop", "start\nfail\nstop"}
+	tests := []struct {
+		name   string
+		preset diff.Preset
+		a, b   string
+		res    []diff.Change
+	}{
+		{"code", diff.PresetCode, code[0], code[1], []diff.Change{{A: 11, B: 11, Del: 0, Ins: 11}}},
+		{"prose", diff.PresetProse, prose[0], prose[1], []diff.Change{{A: 4, B: 4, Del: 7, Ins: 7}}},
+		{"logs", diff.PresetLogs, logs[0], logs[1], []diff.Change{{A: 6, B: 6, Del: 3, Ins: 5}}},
+		{"data", diff.PresetData, logs[0], logs[1], []diff.Change{{A: 6, B: 6, Del: 3, Ins: 5}}},
+	}
+	for _, test := range tests {
+		res := test.preset.Diff(test.a, test.b)
+		if !diffsEqual(res, test.res) {
+			t.Error(test.name, "expected", test.res, "got", res)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Sentences returns the differences of two strings in sentences as
+// split by SplitSentences. Like Words, the changes are byte offsets.
+func Sentences(a, b string) []Change {
+	return Segments(a, b, SplitSentences)
+}
+
+// Paragraphs returns the differences of two strings in paragraphs as
+// split by SplitParagraphs. Like Words, the changes are byte offsets.
+func Paragraphs(a, b string) []Change {
+	return Segments(a, b, SplitParagraphs)
+}
+
+// Segments returns the differences of two strings split into segments by
+// split, for example by a sentence segmenter for another language.
+// The segments returned by split must add up to the whole string. The
+// positions and lengths of the changes are byte offsets into a and b.
+func Segments(a, b string, split func(string) []string, opts ...Option) []Change {
+	return tokenChanges(split(a), split(b), opts...)
+}
+
+// SplitSentences splits s into sentences, each followed by the white space
+// after it. A sentence ends with '.', '!' or '?' and any closing quotes and
+// brackets, if white space follows that is not followed by a lower case
+// letter, so that abbreviations like "e.g. this" are not split.
+func SplitSentences(s string) []string {
+	var res []string
+	start := 0
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		if r != '.' && r != '!' && r != '?' {
+			continue
+		}
+		end := i
+		for end < len(s) {
+			r, size := utf8.DecodeRuneInString(s[end:])
+			if !strings.ContainsRune(`.!?"')]»”’`, r) {
+				break
+			}
+			end += size
+		}
+		space := end
+		for space < len(s) {
+			r, size := utf8.DecodeRuneInString(s[space:])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			space += size
+		}
+		if space == end && space < len(s) {
+			i = end
+			continue // no space, like in 3.14
+		}
+		if next, _ := utf8.DecodeRuneInString(s[space:]); space < len(s) && unicode.IsLower(next) {
+			i = space
+			continue
+		}
+		res = append(res, s[start:space])
+		start, i = space, space
+	}
+	if start < len(s) {
+		res = append(res, s[start:])
+	}
+	return res
+}
+
+// SplitParagraphs splits s into paragraphs, each followed by the empty
+// lines after it. Lines that only hold white space are empty.
+func SplitParagraphs(s string) []string {
+	var res []string
+	start, pos := 0, 0
+	text, blank := false, false // whether the paragraph has text and empty lines after it
+	for _, line := range splitLines(s) {
+		if strings.TrimSpace(line) == "" {
+			blank = text
+		} else {
+			if blank {
+				res = append(res, s[start:pos])
+				start, blank = pos, false
+			}
+			text = true
+		}
+		pos += len(line)
+	}
+	if start < len(s) {
+		res = append(res, s[start:])
+	}
+	return res
+}
@@ -0,0 +1,61 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestSplitSentences(t *testing.T) {
+	for _, test := range []struct {
+		s      string
+		expect []string
+	}{
+		{"", nil},
+		{"One.", []string{"One."}},
+		{"One. Two!  Three? Four", []string{"One. ", "Two!  ", "Three? ", "Four"}},
+		{"Pi is 3.14. Use e.g. this one.", []string{"Pi is 3.14. ", "Use e.g. this one."}},
+		{"He said \"Stop.\" Then left...\nNew line.", []string{"He said \"Stop.\" ", "Then left...\n", "New line."}},
+		{"Wirklich?» Ja.", []string{"Wirklich?» ", "Ja."}},
+	} {
+		res := diff.SplitSentences(test.s)
+		if !reflect.DeepEqual(res, test.expect) {
+			t.Errorf("%q: expected %q, got %q", test.s, test.expect, res)
+		}
+		if strings.Join(res, "") != test.s {
+			t.Errorf("%q: sentences %q don't add up", test.s, res)
+		}
+	}
+}
+
+func TestSplitParagraphs(t *testing.T) {
+	s := "\nFirst line\nsecond line\n\n  \nNext\n\n\nLast"
+	expect := []string{"\nFirst line\nsecond line\n\n  \n", "Next\n\n\n", "Last"}
+	if res := diff.SplitParagraphs(s); !reflect.DeepEqual(res, expect) {
+		t.Errorf("expected %q, got %q", expect, res)
+	}
+}
+
+func TestSentences(t *testing.T) {
+	a := "The fox jumps. The dog sleeps. The end."
+	b := "The fox jumps. The dog barks. The end."
+	if expect := []diff.Change{{A: 15, B: 15, Del: 16, Ins: 15}}; !diffsEqual(diff.Sentences(a, b), expect) {
+		t.Error("expected", expect, "got", diff.Sentences(a, b))
+	}
+	a = "Intro.\n\nBody one.\n\nOutro.\n"
+	b = "Intro.\n\nBody two.\n\nOutro.\n"
+	if expect := []diff.Change{{A: 8, B: 8, Del: 11, Ins: 11}}; !diffsEqual(diff.Paragraphs(a, b), expect) {
+		t.Error("expected", expect, "got", diff.Paragraphs(a, b))
+	}
+	// a custom segmenter
+	lines := func(s string) []string { return strings.SplitAfter(s, "|") }
+	if expect := []diff.Change{{A: 2, B: 2, Del: 2, Ins: 3}}; !diffsEqual(diff.Segments("a|b|c", "a|bb|c", lines), expect) {
+		t.Error("expected", expect, "got", diff.Segments("a|b|c", "a|bb|c", lines))
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package protodiff compares two protocol buffer messages field by field.
+package protodiff
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/echlebek/diff"
+)
+
+// A Status describes how a field differs between two messages.
+type Status int
+
+const (
+	Added    Status = iota + 1 // only populated in the second message
+	Removed                    // only populated in the first message
+	Modified                   // populated in both with different values
+)
+
+func (s Status) String() string {
+	switch s {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// A Change describes a field, list element or map entry that differs
+// between two messages.
+type Change struct {
+	// Path locates the value like "spec.containers[0].image" or
+	// `labels["app"]`. List indices are those of the second message,
+	// except for removed elements. The root has the empty path.
+	Path   string
+	Status Status
+	// From and To are the values in the first and second message,
+	// From is invalid for added values and To for removed ones.
+	From, To protoreflect.Value
+}
+
+// String returns the status and path of the change, for example "M\tspec.replicas".
+func (c Change) String() string {
+	return fmt.Sprintf("%c\t%s", "?ADM"[c.Status], c.Path)
+}
+
+// Compare returns the differences of two messages in the order of the
+// field numbers. Fields are compared if they are populated in either
+// message, repeated fields by a diff of their elements that pairs replaced
+// messages to compare them field by field, and map fields by key.
+// Messages of different types are reported as modified at the root.
+// Unknown fields and extensions are ignored.
+func Compare(a, b proto.Message) []Change {
+	var c comparer
+	c.message("", a.ProtoReflect(), b.ProtoReflect())
+	return c.changes
+}
+
+type comparer struct {
+	changes []Change
+}
+
+func (c *comparer) add(path string, s Status, from, to protoreflect.Value) {
+	c.changes = append(c.changes, Change{Path: path, Status: s, From: from, To: to})
+}
+
+func (c *comparer) message(path string, a, b protoreflect.Message) {
+	if a.Descriptor().FullName() != b.Descriptor().FullName() {
+		c.add(path, Modified, protoreflect.ValueOfMessage(a), protoreflect.ValueOfMessage(b))
+		return
+	}
+	fields := a.Descriptor().Fields()
+	numbers := make([]int, fields.Len())
+	for i := range numbers {
+		numbers[i] = i
+	}
+	sort.Slice(numbers, func(i, j int) bool { return fields.Get(numbers[i]).Number() < fields.Get(numbers[j]).Number() })
+	for _, i := range numbers {
+		fd := fields.Get(i)
+		p := join(path, string(fd.Name()))
+		hasA, hasB := a.Has(fd), b.Has(fd)
+		switch {
+		case !hasA && !hasB:
+		case !hasB:
+			c.add(p, Removed, a.Get(fd), protoreflect.Value{})
+		case !hasA:
+			c.add(p, Added, protoreflect.Value{}, b.Get(fd))
+		case fd.IsList():
+			c.list(p, fd, a.Get(fd).List(), b.Get(fd).List())
+		case fd.IsMap():
+			c.mapField(p, fd, a.Get(fd).Map(), b.Get(fd).Map())
+		default:
+			c.value(p, fd, a.Get(fd), b.Get(fd))
+		}
+	}
+}
+
+// value compares single values of the kind of fd.
+func (c *comparer) value(path string, fd protoreflect.FieldDescriptor, a, b protoreflect.Value) {
+	if isMessage(fd) {
+		c.message(path, a.Message(), b.Message())
+	} else if !a.Equal(b) {
+		c.add(path, Modified, a, b)
+	}
+}
+
+func (c *comparer) list(path string, fd protoreflect.FieldDescriptor, a, b protoreflect.List) {
+	for _, ch := range diff.Diff(a.Len(), b.Len(), &listData{fd, a, b}) {
+		paired := 0
+		if isMessage(fd) {
+			paired = min(ch.Del, ch.Ins)
+		}
+		for i := 0; i < paired; i++ {
+			c.message(index(path, ch.B+i), a.Get(ch.A+i).Message(), b.Get(ch.B+i).Message())
+		}
+		for i := paired; i < ch.Del; i++ {
+			c.add(index(path, ch.A+i), Removed, a.Get(ch.A+i), protoreflect.Value{})
+		}
+		for i := paired; i < ch.Ins; i++ {
+			c.add(index(path, ch.B+i), Added, protoreflect.Value{}, b.Get(ch.B+i))
+		}
+	}
+}
+
+func (c *comparer) mapField(path string, fd protoreflect.FieldDescriptor, a, b protoreflect.Map) {
+	var keys []protoreflect.MapKey
+	a.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+		keys = append(keys, k)
+		return true
+	})
+	b.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+		if !a.Has(k) {
+			keys = append(keys, k)
+		}
+		return true
+	})
+	sort.Slice(keys, func(i, j int) bool { return lessKey(keys[i], keys[j]) })
+	for _, k := range keys {
+		p := path + "[" + formatKey(k) + "]"
+		switch {
+		case !b.Has(k):
+			c.add(p, Removed, a.Get(k), protoreflect.Value{})
+		case !a.Has(k):
+			c.add(p, Added, protoreflect.Value{}, b.Get(k))
+		default:
+			c.value(p, fd.MapValue(), a.Get(k), b.Get(k))
+		}
+	}
+}
+
+func isMessage(fd protoreflect.FieldDescriptor) bool {
+	return fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func index(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+func formatKey(k protoreflect.MapKey) string {
+	if s, ok := k.Interface().(string); ok {
+		return strconv.Quote(s)
+	}
+	return k.String()
+}
+
+func lessKey(a, b protoreflect.MapKey) bool {
+	switch x := a.Interface().(type) {
+	case string:
+		return x < b.String()
+	case bool:
+		return !x && b.Bool()
+	case int32, int64:
+		return a.Int() < b.Int()
+	}
+	return a.Uint() < b.Uint()
+}
+
+type listData struct {
+	fd   protoreflect.FieldDescriptor
+	a, b protoreflect.List
+}
+
+func (d *listData) Equal(i, j int) bool {
+	x, y := d.a.Get(i), d.b.Get(j)
+	if isMessage(d.fd) {
+		return proto.Equal(x.Message().Interface(), y.Message().Interface())
+	}
+	return x.Equal(y)
+}
@@ -0,0 +1,86 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protodiff_test
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/echlebek/diff/protodiff"
+)
+
+func TestCompare(t *testing.T) {
+	a := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("a.proto"),
+		Package:    proto.String("pkg"),
+		Dependency: []string{"x.proto", "y.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("A")},
+			{Name: proto.String("B"), Field: []*descriptorpb.FieldDescriptorProto{{Name: proto.String("f"), Number: proto.Int32(1)}}},
+		},
+	}
+	b := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("a.proto"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"x.proto", "z.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("A")},
+			{Name: proto.String("B"), Field: []*descriptorpb.FieldDescriptorProto{{Name: proto.String("f"), Number: proto.Int32(2)}}},
+			{Name: proto.String("C")},
+		},
+	}
+	expect := []string{
+		"D\tpackage",
+		"D\tdependency[1]",
+		"A\tdependency[1]",
+		"M\tmessage_type[1].field[0].number",
+		"A\tmessage_type[2]",
+		"A\tsyntax",
+	}
+	check(t, protodiff.Compare(a, b), expect)
+	if changes := protodiff.Compare(a, a); len(changes) != 0 {
+		t.Error("expected no changes, got", changes)
+	}
+}
+
+func TestCompareMap(t *testing.T) {
+	a, err := structpb.NewStruct(map[string]any{"app": "web", "replicas": 2, "tags": []any{"a", "b"}, "old": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := structpb.NewStruct(map[string]any{"app": "web", "replicas": 3, "tags": []any{"a", "c", "b"}, "new": nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{
+		"A\tfields[\"new\"]",
+		"D\tfields[\"old\"]",
+		"M\tfields[\"replicas\"].number_value",
+		"A\tfields[\"tags\"].list_value.values[1]",
+	}
+	changes := protodiff.Compare(a, b)
+	check(t, changes, expect)
+	if c := changes[2]; c.From.Float() != 2 || c.To.Float() != 3 {
+		t.Error("unexpected values", c.From, c.To)
+	}
+	// messages of different types
+	check(t, protodiff.Compare(a, wrapperspb.String("x")), []string{"M\t"})
+}
+
+func check(t *testing.T, changes []protodiff.Change, expect []string) {
+	t.Helper()
+	if len(changes) != len(expect) {
+		t.Fatal("expected", expect, "got", changes)
+	}
+	for i, c := range changes {
+		if c.String() != expect[i] {
+			t.Errorf("expected %q, got %q", expect[i], c.String())
+		}
+	}
+}
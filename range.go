@@ -0,0 +1,49 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "strconv"
+
+// A Range is the half-open range of positions from Start up to but not
+// including End. A change deletes the range [A, A+Del) of a and inserts
+// the range [B, B+Ins) of b.
+type Range struct {
+	Start, End int
+}
+
+// Len returns the number of positions in r.
+func (r Range) Len() int { return r.End - r.Start }
+
+// Empty reports whether r holds no positions, like the deleted range of
+// an insertion.
+func (r Range) Empty() bool { return r.End <= r.Start }
+
+// Contains reports whether position i is in r.
+func (r Range) Contains(i int) bool { return r.Start <= i && i < r.End }
+
+// Overlaps reports whether r and s share a position. Empty ranges overlap
+// nothing, not even ranges around them.
+func (r Range) Overlaps(s Range) bool {
+	return !r.Empty() && !s.Empty() && r.Start < s.End && s.Start < r.End
+}
+
+// Shift returns r moved by d positions.
+func (r Range) Shift(d int) Range { return Range{r.Start + d, r.End + d} }
+
+func (r Range) String() string {
+	return "[" + strconv.Itoa(r.Start) + "," + strconv.Itoa(r.End) + ")"
+}
+
+// RangeA returns the range of a deleted by c.
+func (c Change) RangeA() Range { return Range{c.A, c.A + c.Del} }
+
+// RangeB returns the range of b inserted by c.
+func (c Change) RangeB() Range { return Range{c.B, c.B + c.Ins} }
+
+// ChangeOf returns the change that replaces the range a of a
+// with the range b of b.
+func ChangeOf(a, b Range) Change {
+	return Change{A: a.Start, B: b.Start, Del: a.Len(), Ins: b.Len()}
+}
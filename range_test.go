@@ -0,0 +1,53 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestRange(t *testing.T) {
+	r := diff.Range{Start: 2, End: 5}
+	if r.Len() != 3 || r.Empty() {
+		t.Error("unexpected length of", r)
+	}
+	if !r.Contains(2) || !r.Contains(4) || r.Contains(5) || r.Contains(1) {
+		t.Error("unexpected positions in", r)
+	}
+	for _, test := range []struct {
+		s      diff.Range
+		expect bool
+	}{
+		{diff.Range{Start: 0, End: 2}, false},
+		{diff.Range{Start: 0, End: 3}, true},
+		{diff.Range{Start: 3, End: 4}, true},
+		{diff.Range{Start: 4, End: 9}, true},
+		{diff.Range{Start: 5, End: 9}, false},
+		{diff.Range{Start: 3, End: 3}, false},
+	} {
+		if r.Overlaps(test.s) != test.expect || test.s.Overlaps(r) != test.expect {
+			t.Error(r, "overlapping", test.s, "expected", test.expect)
+		}
+	}
+	if s := r.Shift(-2); s != (diff.Range{Start: 0, End: 3}) {
+		t.Error("unexpected shifted range", s)
+	}
+	if s := r.String(); s != "[2,5)" {
+		t.Error("unexpected string", s)
+	}
+}
+
+func TestChangeRanges(t *testing.T) {
+	c := diff.Change{A: 3, B: 4, Del: 2, Ins: 0}
+	a, b := c.RangeA(), c.RangeB()
+	if a != (diff.Range{Start: 3, End: 5}) || b != (diff.Range{Start: 4, End: 4}) || !b.Empty() {
+		t.Error("unexpected ranges", a, b)
+	}
+	if res := diff.ChangeOf(a, b); res != c {
+		t.Error("expected", c, "got", res)
+	}
+}
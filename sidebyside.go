@@ -0,0 +1,92 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// SideBySideOptions configure WriteSideBySide.
+type SideBySideOptions struct {
+	// Width is the width of the output lines, 130 if zero.
+	Width int
+	// SuppressCommon leaves out lines that did not change.
+	SuppressCommon bool
+}
+
+// WriteSideBySide writes the changes of lines a to b in two columns like
+// diff -y. The gutter between the columns marks changed lines with "|",
+// deleted lines with "<" and inserted lines with ">". Tabs are expanded
+// and lines too long for their column are cut. Trailing white space is
+// removed from the output lines.
+func WriteSideBySide(w io.Writer, a, b []string, changes []Change, opts *SideBySideOptions) error {
+	width := 130
+	if opts != nil && opts.Width > 0 {
+		width = opts.Width
+	}
+	suppress := opts != nil && opts.SuppressCommon
+	col := (width - 3) / 2
+	if col < 1 {
+		col = 1
+	}
+
+	var sb strings.Builder
+	row := func(left, right string, mark byte) {
+		left, right = column(left, col), column(right, col)
+		line := left + strings.Repeat(" ", col-utf8.RuneCountInString(left)) + " " + string(mark) + " " + right
+		sb.WriteString(strings.TrimRight(line, " "))
+		sb.WriteByte('\n')
+	}
+	x, y := 0, 0
+	common := func(end int) {
+		for ; x < end; x, y = x+1, y+1 {
+			if !suppress {
+				row(a[x], b[y], ' ')
+			}
+		}
+	}
+	for _, c := range changes {
+		common(c.A)
+		i := 0
+		for ; i < c.Del && i < c.Ins; i++ {
+			row(a[c.A+i], b[c.B+i], '|')
+		}
+		for ; i < c.Del; i++ {
+			row(a[c.A+i], "", '<')
+		}
+		for ; i < c.Ins; i++ {
+			row("", b[c.B+i], '>')
+		}
+		x, y = c.A+c.Del, c.B+c.Ins
+	}
+	common(len(a))
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// column returns line without its line ending, with expanded tabs
+// and cut to width runes.
+func column(line string, width int) string {
+	line = strings.TrimRight(line, "\r\n")
+	var sb strings.Builder
+	n := 0
+	for _, r := range line {
+		if r == '\t' {
+			for t := 8 - n%8; t > 0 && n < width; t-- {
+				sb.WriteByte(' ')
+				n++
+			}
+			continue
+		}
+		if n == width {
+			break
+		}
+		sb.WriteRune(r)
+		n++
+	}
+	return sb.String()
+}
@@ -0,0 +1,46 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestWriteSideBySide(t *testing.T) {
+	a := []string{"one\n", "two\n", "three\n", "four\n", "five\n"}
+	b := []string{"one\n", "2\n", "three\n", "five\n", "six\n", "seven and a long line\n"}
+	changes := diff.Lines(a, b)
+	var sb strings.Builder
+	if err := diff.WriteSideBySide(&sb, a, b, changes, &diff.SideBySideOptions{Width: 23}); err != nil {
+		t.Fatal(err)
+	}
+	expect := "" +
+		"one          one\n" +
+		"two        | 2\n" +
+		"three        three\n" +
+		"four       <\n" +
+		"five         five\n" +
+		"           > six\n" +
+		"           > seven and\n"
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+
+	sb.Reset()
+	if err := diff.WriteSideBySide(&sb, a, b, changes, &diff.SideBySideOptions{Width: 23, SuppressCommon: true}); err != nil {
+		t.Fatal(err)
+	}
+	expect = "" +
+		"two        | 2\n" +
+		"four       <\n" +
+		"           > six\n" +
+		"           > seven and\n"
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+}
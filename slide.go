@@ -0,0 +1,41 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// A Slide tells where to place insertions and deletions that can be shifted
+// along runs of equal elements, like a repeated block of lines.
+type Slide int
+
+const (
+	// SlideUp places changes as early as possible, so that the common
+	// elements of a run attach to the bottom of the change.
+	SlideUp Slide = iota + 1
+	// SlideDown places changes as late as possible, so that the common
+	// elements of a run attach to the top of the change.
+	SlideDown
+)
+
+// WithSlide shifts every change that only inserts or only deletes as far
+// up or down as it can go without changing the result or touching another
+// change. Inserting "b\n" into "a\nb\n" for example is at the first line
+// with SlideUp and at the second with SlideDown. Without it the position
+// depends on the algorithm. See IndentHeuristic for line diffs of code.
+func WithSlide(s Slide) Option {
+	return func(o *options) { o.slide = s }
+}
+
+// slide shifts the changes of data of length n in place.
+func slide(n int, data Data, changes []Change, s Slide) []Change {
+	for i := range changes {
+		up, down := slideRange(n, data, changes, i)
+		d := down
+		if s == SlideUp {
+			d = -up
+		}
+		changes[i].A += d
+		changes[i].B += d
+	}
+	return changes
+}
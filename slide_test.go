@@ -0,0 +1,52 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestWithSlide(t *testing.T) {
+	a := splitLines("- name: a\n  on: true\n")
+	b := splitLines("- name: a\n  on: true\n- name: a\n  on: true\n")
+	for _, test := range []struct {
+		slide  diff.Slide
+		expect []diff.Change
+	}{
+		{diff.SlideUp, []diff.Change{{A: 0, B: 0, Del: 0, Ins: 2}}},
+		{diff.SlideDown, []diff.Change{{A: 2, B: 2, Del: 0, Ins: 2}}},
+	} {
+		res := diff.Lines(a, b, diff.WithSlide(test.slide))
+		if !diffsEqual(res, test.expect) {
+			t.Error(test.slide, "expected", test.expect, "got", res)
+		}
+		res = diff.Lines(b, a, diff.WithSlide(test.slide))
+		if expect := diff.Invert(test.expect); !diffsEqual(res, expect) {
+			t.Error(test.slide, "expected", expect, "got", res)
+		}
+	}
+}
+
+func TestWithSlideRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a := randomInts(r, r.Intn(20), 3)
+		b := randomInts(r, r.Intn(20), 3)
+		data := &ints{a, b}
+		expect := countEdits(diff.Ints(a, b))
+		for _, s := range []diff.Slide{diff.SlideUp, diff.SlideDown} {
+			res := diff.Diff(len(a), len(b), data, diff.WithSlide(s))
+			if diff.Check(a, b, res) != nil {
+				t.Fatal(a, b, s, "invalid result", res)
+			}
+			if countEdits(res) != expect {
+				t.Fatal(a, b, s, "expected", expect, "edits, got", res)
+			}
+		}
+	}
+}
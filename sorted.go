@@ -0,0 +1,29 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "cmp"
+
+// Sorted returns the differences of two sorted slices in a single pass
+// in O(n+m) time. Because both are sorted, every element is either in both
+// slices or only in one of them, so the changes only delete and insert and
+// are minimal. The result is undefined if a or b is not sorted.
+func Sorted[T cmp.Ordered](a, b []T) []Change {
+	var changes []Change
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch cmp.Compare(a[i], b[j]) {
+		case -1:
+			changes = appendChange(changes, Change{A: i, B: j, Del: 1})
+			i++
+		case 1:
+			changes = appendChange(changes, Change{A: i, B: j, Ins: 1})
+			j++
+		default:
+			i, j = i+1, j+1
+		}
+	}
+	return appendChange(changes, Change{A: i, B: j, Del: len(a) - i, Ins: len(b) - j})
+}
@@ -0,0 +1,53 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestSorted(t *testing.T) {
+	a := []string{"a", "b", "d", "e", "e"}
+	b := []string{"b", "c", "e", "f", "g"}
+	res := diff.Sorted(a, b)
+	expect := []diff.Change{{A: 0, B: 0, Del: 1, Ins: 0}, {A: 2, B: 1, Del: 1, Ins: 1}, {A: 4, B: 3, Del: 1, Ins: 2}}
+	if !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+}
+
+func TestSortedRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a := randomInts(r, r.Intn(20), 8)
+		b := randomInts(r, r.Intn(20), 8)
+		sort.Ints(a)
+		sort.Ints(b)
+		res := diff.Sorted(a, b)
+		if diff.Check(a, b, res) != nil {
+			t.Fatal(a, b, "invalid changes", res)
+		}
+		ins, del := diff.Stats(res)
+		eins, edel := diff.Stats(diff.Ints(a, b))
+		if ins != eins || del != edel {
+			t.Fatal(a, b, "changes are not minimal", res)
+		}
+	}
+}
+
+func BenchmarkSorted(b *testing.B) {
+	x, y := make([]int, 10000), make([]int, 10000)
+	for i := range x {
+		x[i], y[i] = 2*i, 3*i
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		diff.Sorted(x, y)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Stats returns the number of inserted and deleted elements of changes.
+func Stats(changes []Change) (ins, del int) {
+	for _, c := range changes {
+		ins += c.Ins
+		del += c.Del
+	}
+	return ins, del
+}
+
+// Similarity returns the share of the longer of two sequences with lengths
+// n and m that is kept by changes, from 0 for nothing to 1 for all of it.
+// Two empty sequences are equal.
+func Similarity(n, m int, changes []Change) float64 {
+	longer := n
+	if m > longer {
+		longer = m
+	}
+	if longer == 0 {
+		return 1
+	}
+	_, del := Stats(changes)
+	return float64(n-del) / float64(longer)
+}
+
+// A Labeled holds the changes of one diff and a label for it, like a file name.
+type Labeled struct {
+	Label   string
+	Changes []Change
+}
+
+// WriteDiffstat writes a summary of the diffs like git's diffstat,
+// one line per diff followed by the totals:
+//
+//	 a.go | 10 ++++++----
+//	 1 file changed, 6 insertions(+), 4 deletions(-)
+//
+// The graphs are scaled down to fit the lines into width columns.
+func WriteDiffstat(w io.Writer, diffs []Labeled, width int) error {
+	nameWidth, maxChange := 0, 0
+	totalIns, totalDel := 0, 0
+	for _, d := range diffs {
+		if len(d.Label) > nameWidth {
+			nameWidth = len(d.Label)
+		}
+		ins, del := Stats(d.Changes)
+		if ins+del > maxChange {
+			maxChange = ins + del
+		}
+		totalIns += ins
+		totalDel += del
+	}
+	numWidth := len(strconv.Itoa(maxChange))
+	graphWidth := width - nameWidth - numWidth - 5
+	if graphWidth < 2 {
+		graphWidth = 2
+	}
+
+	var sb strings.Builder
+	for _, d := range diffs {
+		ins, del := Stats(d.Changes)
+		fmt.Fprintf(&sb, " %-*s | %*d", nameWidth, d.Label, numWidth, ins+del)
+		if maxChange > graphWidth {
+			total := scale(ins+del, graphWidth, maxChange)
+			if total < 2 && ins > 0 && del > 0 {
+				total = 2
+			}
+			// round the smaller side, so that it stays visible
+			if ins < del {
+				ins = scale(ins, graphWidth, maxChange)
+				del = total - ins
+			} else {
+				del = scale(del, graphWidth, maxChange)
+				ins = total - del
+			}
+		}
+		if ins+del > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(strings.Repeat("+", ins))
+		sb.WriteString(strings.Repeat("-", del))
+		sb.WriteByte('\n')
+	}
+	fmt.Fprintf(&sb, " %d %s changed", len(diffs), plural(len(diffs), "file", "files"))
+	if totalIns > 0 || totalDel == 0 {
+		fmt.Fprintf(&sb, ", %d %s(+)", totalIns, plural(totalIns, "insertion", "insertions"))
+	}
+	if totalDel > 0 || totalIns == 0 {
+		fmt.Fprintf(&sb, ", %d %s(-)", totalDel, plural(totalDel, "deletion", "deletions"))
+	}
+	sb.WriteByte('\n')
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// scale scales n from 0..max to 0..width keeping non-zero values visible.
+func scale(n, width, max int) int {
+	if n == 0 {
+		return 0
+	}
+	return 1 + n*(width-1)/max
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
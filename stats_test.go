@@ -0,0 +1,72 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestStats(t *testing.T) {
+	ins, del := diff.Stats(diff.ByteStrings("brown fox jumps over the lazy dog", "brwn faax junps ovver the lay dago"))
+	if ins != 6 || del != 5 {
+		t.Error("expected 6 insertions and 5 deletions, got", ins, del)
+	}
+	if ins, del := diff.Stats(nil); ins != 0 || del != 0 {
+		t.Error("expected no insertions and deletions, got", ins, del)
+	}
+}
+
+func TestWriteDiffstat(t *testing.T) {
+	diffs := []diff.Labeled{
+		{Label: "diff.go", Changes: []diff.Change{{A: 0, B: 0, Del: 4, Ins: 6}}},
+		{Label: "README.md", Changes: []diff.Change{{A: 3, B: 3, Del: 0, Ins: 1}}},
+		{Label: "big.txt", Changes: []diff.Change{{A: 0, B: 0, Del: 120, Ins: 0}}},
+	}
+	var sb strings.Builder
+	if err := diff.WriteDiffstat(&sb, diffs, 40); err != nil {
+		t.Fatal(err)
+	}
+	expect := ` diff.go   |  10 +-
+ README.md |   1 +
+ big.txt   | 120 -----------------------
+ 3 files changed, 7 insertions(+), 124 deletions(-)
+`
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+
+	sb.Reset()
+	if err := diff.WriteDiffstat(&sb, diffs[1:2], 80); err != nil {
+		t.Fatal(err)
+	}
+	expect = ` README.md | 1 +
+ 1 file changed, 1 insertion(+)
+`
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	for _, test := range []struct {
+		a, b   string
+		expect float64
+	}{
+		{"", "", 1},
+		{"abcd", "abcd", 1},
+		{"abcd", "", 0},
+		{"abcd", "abed", 0.75},
+		{"ab", "abcd", 0.5},
+		{"abcd", "wxyz", 0},
+	} {
+		changes := diff.ByteStrings(test.a, test.b)
+		if s := diff.Similarity(len(test.a), len(test.b), changes); s != test.expect {
+			t.Errorf("Similarity(%q, %q) = %v, expected %v", test.a, test.b, s, test.expect)
+		}
+	}
+}
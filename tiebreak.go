@@ -0,0 +1,114 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// A TieBreak chooses between minimal results that differ only in the order
+// of deletions and insertions, like deleting "1" before or after keeping "2"
+// when diffing "12" and "21".
+type TieBreak int
+
+const (
+	// DeletionsFirst deletes elements of a as early as possible.
+	DeletionsFirst TieBreak = iota + 1
+	// InsertionsFirst inserts elements of b as early as possible.
+	InsertionsFirst
+)
+
+// WithTieBreak makes the choice between minimal results well-defined: the
+// edit path is walked from the start, common elements are kept as early as
+// possible, and where both a deletion and an insertion lead to a minimal
+// result the preferred one is taken. Without it the choice depends on how
+// the algorithm splits the problem and may change between versions of this
+// package, so use it for results that are stored, like snapshot tests.
+//
+// It needs memory quadratic in the number of differences. It overrides
+// WithAlgorithm and ignores WithHeuristic and WithMaxDistance.
+func WithTieBreak(t TieBreak) Option {
+	return func(o *options) { o.tieBreak = t }
+}
+
+// ordered marks the differences of data on the minimal edit path preferred
+// by c.tieBreak. It records the furthest reaching reverse paths for every
+// number of edits e, which tell whether a point can still reach the end with
+// e edits, and then walks forward taking the preferred edit whenever it can.
+func (c *context) ordered(n, m int) {
+	delta := n - m
+	// rv[e][k-delta+e] is the smallest x reached on diagonal k=x-y
+	// by a path with e edits from the end
+	var rv [][]int
+	invalid := n + 1
+	need := c.baseMemory(n, m)
+	for e := 0; ; e++ {
+		need += 8*(2*e+1) + 24
+		c.maxD = e
+		if c.canceled() || c.exceeds(need) {
+			for i := range c.flags {
+				c.flags[i] = 3
+			}
+			return
+		}
+		v := make([]int, 2*e+1)
+		for i := range v {
+			k := delta - e + i
+			x := invalid
+			if e == 0 {
+				x = n
+			} else {
+				prev := rv[e-1]
+				// from diagonal k+1 undoing a deletion
+				if j := k + 1 - delta + e - 1; j >= 0 && j < len(prev) && prev[j] != invalid && prev[j]-1 >= 0 && prev[j]-1 < x {
+					x = prev[j] - 1
+				}
+				// from diagonal k-1 undoing an insertion
+				if j := k - 1 - delta + e - 1; j >= 0 && j < len(prev) && prev[j] != invalid && prev[j]-k >= 0 && prev[j] < x {
+					x = prev[j]
+				}
+			}
+			if x != invalid && (x-k < 0 || x-k > m || x > n) {
+				x = invalid
+			}
+			if x != invalid {
+				for y := x - k; x > 0 && y > 0 && c.data.Equal(x-1, y-1); y-- {
+					x--
+				}
+			}
+			v[i] = x
+		}
+		rv = append(rv, v)
+		if j := -delta + e; j >= 0 && j < len(v) && v[j] == 0 {
+			break
+		}
+	}
+	// reaches reports whether the point x, y can reach the end with e edits.
+	reaches := func(x, y, e int) bool {
+		if e < 0 || x > n || y > m {
+			return false
+		}
+		for x < n && y < m && c.data.Equal(x, y) {
+			x++
+			y++
+		}
+		j := x - y - delta + e
+		return j >= 0 && j < len(rv[e]) && x >= rv[e][j]
+	}
+	x, y := 0, 0
+	for e := len(rv) - 1; e > 0; e-- {
+		for x < n && y < m && c.data.Equal(x, y) {
+			x++
+			y++
+		}
+		del := reaches(x+1, y, e-1)
+		if c.tieBreak == InsertionsFirst && reaches(x, y+1, e-1) {
+			del = false
+		}
+		if del {
+			c.flags[x] |= 1
+			x++
+		} else {
+			c.flags[y] |= 2
+			y++
+		}
+	}
+}
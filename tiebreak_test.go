@@ -0,0 +1,85 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestWithTieBreak(t *testing.T) {
+	a, b := []int{1, 2}, []int{2, 1}
+	res := diff.Diff(len(a), len(b), &ints{a, b}, diff.WithTieBreak(diff.DeletionsFirst))
+	if expect := []diff.Change{{A: 0, B: 0, Del: 1, Ins: 0}, {A: 2, B: 1, Del: 0, Ins: 1}}; !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	res = diff.Diff(len(a), len(b), &ints{a, b}, diff.WithTieBreak(diff.InsertionsFirst))
+	if expect := []diff.Change{{A: 0, B: 0, Del: 0, Ins: 1}, {A: 1, B: 2, Del: 1, Ins: 0}}; !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+}
+
+// preferred computes the result of a tie break with a table of the
+// distances of all suffixes.
+func preferred(a, b []int, t diff.TieBreak) []diff.Change {
+	n, m := len(a), len(b)
+	dist := make([][]int, n+1)
+	for x := range dist {
+		dist[x] = make([]int, m+1)
+	}
+	for x := n; x >= 0; x-- {
+		for y := m; y >= 0; y-- {
+			switch {
+			case x == n:
+				dist[x][y] = m - y
+			case y == m:
+				dist[x][y] = n - x
+			case a[x] == b[y]:
+				dist[x][y] = dist[x+1][y+1]
+			default:
+				dist[x][y] = 1 + min(dist[x+1][y], dist[x][y+1])
+			}
+		}
+	}
+	var res []diff.Change
+	x, y := 0, 0
+	for x < n || y < m {
+		if x < n && y < m && a[x] == b[y] {
+			x, y = x+1, y+1
+			continue
+		}
+		del := x < n && dist[x+1][y] < dist[x][y]
+		if ins := y < m && dist[x][y+1] < dist[x][y]; ins && (t == diff.InsertionsFirst || !del) {
+			del = false
+		}
+		c := diff.Change{A: x, B: y, Ins: 1}
+		if del {
+			c = diff.Change{A: x, B: y, Del: 1}
+		}
+		if k := len(res) - 1; k >= 0 && res[k].A+res[k].Del == c.A && res[k].B+res[k].Ins == c.B {
+			res[k].Del += c.Del
+			res[k].Ins += c.Ins
+		} else {
+			res = append(res, c)
+		}
+		x, y = x+c.Del, y+c.Ins
+	}
+	return res
+}
+
+func TestWithTieBreakRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a, b := randomInts(r, r.Intn(15), 3), randomInts(r, r.Intn(15), 3)
+		for _, tb := range []diff.TieBreak{diff.DeletionsFirst, diff.InsertionsFirst} {
+			res := diff.Diff(len(a), len(b), &ints{a, b}, diff.WithTieBreak(tb))
+			if expect := preferred(a, b, tb); !diffsEqual(res, expect) {
+				t.Fatal(a, b, tb, "expected", expect, "got", res)
+			}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A Trace records how the Myers algorithm explored the edit graph, to find
+// out why it chose a path. Positions x in a and y in b are points of the
+// graph, and a diagonal k holds the points with x-y=k.
+type Trace struct {
+	Steps []TraceStep
+}
+
+// A TraceStep is a snapshot of the d-paths of one search for a middle snake
+// after d edits. The forward paths start at AOffset, BOffset and the reverse
+// paths at ALimit, BLimit.
+type TraceStep struct {
+	AOffset, BOffset, ALimit, BLimit int
+	D                                int
+	// Forward holds the x of the furthest reaching forward paths on the
+	// diagonals AOffset-BOffset-D, AOffset-BOffset-D+2 and so on.
+	Forward []int
+	// Reverse holds the x of the furthest reaching reverse paths on the
+	// diagonals ALimit-BLimit-D, ALimit-BLimit-D+2 and so on.
+	// It is shorter than D+1 if the search ended before.
+	Reverse []int
+}
+
+// WithTrace records the searches of the Myers algorithm in t.
+// Other algorithms and the fallback of WithHeuristic are not recorded.
+func WithTrace(t *Trace) Option {
+	return func(o *options) { o.trace = t }
+}
+
+func (t *Trace) forward(x int) {
+	s := &t.Steps[len(t.Steps)-1]
+	s.Forward = append(s.Forward, x)
+}
+
+func (t *Trace) reverse(x int) {
+	s := &t.Steps[len(t.Steps)-1]
+	s.Reverse = append(s.Reverse, x)
+}
+
+// sameBox reports whether s searches the same box as o.
+func (s *TraceStep) sameBox(o *TraceStep) bool {
+	return s.AOffset == o.AOffset && s.BOffset == o.BOffset && s.ALimit == o.ALimit && s.BLimit == o.BLimit
+}
+
+// WriteText writes the steps of t as text, one line per direction and d:
+//
+//	search a[0:7] b[0:6]
+//	d=0 forward 0,0
+//	d=0 reverse 7,6
+//	d=1 forward 0,1 1,0
+//	d=1 reverse 6,6 5,3
+//
+// Every point is the end of the furthest reaching path on its diagonal.
+func (t *Trace) WriteText(w io.Writer) error {
+	var sb strings.Builder
+	for i := range t.Steps {
+		s := &t.Steps[i]
+		if i == 0 || !s.sameBox(&t.Steps[i-1]) {
+			fmt.Fprintf(&sb, "search a[%d:%d] b[%d:%d]\n", s.AOffset, s.ALimit, s.BOffset, s.BLimit)
+		}
+		writePoints(&sb, s.D, "forward", s.AOffset-s.BOffset-s.D, s.Forward)
+		if len(s.Reverse) > 0 {
+			writePoints(&sb, s.D, "reverse", s.ALimit-s.BLimit-s.D, s.Reverse)
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writePoints(sb *strings.Builder, d int, dir string, k int, xs []int) {
+	fmt.Fprintf(sb, "d=%d %s", d, dir)
+	for i, x := range xs {
+		fmt.Fprintf(sb, " %d,%d", x, x-k-2*i)
+	}
+	sb.WriteByte('\n')
+}
+
+// WriteDOT writes the steps of t as a Graphviz graph with one cluster per
+// search. Edges of forward paths are blue, of reverse paths red, and
+// diagonal runs of equal elements are bold. The nodes have positions for
+// neato -n with a growing to the right and b growing downwards.
+func (t *Trace) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph trace {\n\tnode [shape=circle, fontsize=8, width=0.3, fixedsize=true];\n")
+	search := -1
+	nodes := map[[2]int]bool{}
+	node := func(x, y int) string {
+		id := fmt.Sprintf("s%d_%d_%d", search, x, y)
+		if !nodes[[2]int{x, y}] {
+			nodes[[2]int{x, y}] = true
+			fmt.Fprintf(&sb, "\t\t%s [label=\"%d,%d\", pos=\"%d,%d\"];\n", id, x, y, 50*x, -50*y)
+		}
+		return id
+	}
+	edge := func(x0, y0, x1, y1 int, color string) {
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		style := ""
+		if x1-x0 == y1-y0 {
+			style = ", style=bold"
+		}
+		from, to := node(x0, y0), node(x1, y1)
+		fmt.Fprintf(&sb, "\t\t%s -> %s [color=%s%s];\n", from, to, color, style)
+	}
+	for i := range t.Steps {
+		s := &t.Steps[i]
+		first := i == 0 || !s.sameBox(&t.Steps[i-1])
+		if first {
+			if search >= 0 {
+				sb.WriteString("\t}\n")
+			}
+			search++
+			nodes = map[[2]int]bool{}
+			fmt.Fprintf(&sb, "\tsubgraph cluster_%d {\n\t\tlabel=\"a[%d:%d] b[%d:%d]\";\n", search, s.AOffset, s.ALimit, s.BOffset, s.BLimit)
+		}
+		var prev *TraceStep
+		if !first {
+			prev = &t.Steps[i-1]
+		}
+		fmid, rmid := s.AOffset-s.BOffset, s.ALimit-s.BLimit
+		for j, x := range s.Forward {
+			k := fmid - s.D + 2*j
+			// the start of the path after its last edit
+			sx, px, py := s.AOffset, s.AOffset, s.BOffset
+			if prev != nil {
+				if j < len(prev.Forward) && (j == 0 || j != s.D && prev.Forward[j] > prev.Forward[j-1]) {
+					px = prev.Forward[j] // down from diagonal k+1
+					py, sx = px-k-1, px
+				} else {
+					px = prev.Forward[j-1] // right from diagonal k-1
+					py, sx = px-k+1, px+1
+				}
+				edge(px, py, sx, sx-k, "blue")
+			}
+			edge(sx, sx-k, x, x-k, "blue")
+		}
+		for j, x := range s.Reverse {
+			k := rmid - s.D + 2*j
+			sx, px, py := s.ALimit, s.ALimit, s.BLimit
+			if prev != nil {
+				if j == s.D || j != 0 && prev.Reverse[j-1] < prev.Reverse[j] {
+					px = prev.Reverse[j-1] // up from diagonal k-1
+					py, sx = px-k+1, px
+				} else {
+					px = prev.Reverse[j] // left from diagonal k+1
+					py, sx = px-k-1, px-1
+				}
+				edge(px, py, sx, sx-k, "red")
+			}
+			edge(sx, sx-k, x, x-k, "red")
+		}
+	}
+	if search >= 0 {
+		sb.WriteString("\t}\n")
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
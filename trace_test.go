@@ -0,0 +1,65 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestWithTrace(t *testing.T) {
+	test := tests[len(tests)-1]
+	data := &ints{test.a, test.b}
+	var trace diff.Trace
+	res := diff.Diff(len(test.a), len(test.b), data, diff.WithTrace(&trace))
+	if expect := diff.Ints(test.a, test.b); !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+	var sb strings.Builder
+	if err := trace.WriteText(&sb); err != nil {
+		t.Fatal(err)
+	}
+	expect := `search a[0:7] b[0:6]
+d=0 forward 0,0
+d=0 reverse 7,6
+d=1 forward 0,1 1,0
+d=1 reverse 6,6 5,3
+d=2 forward 2,4 2,2 3,1
+d=2 reverse 5,6 3,2 4,1
+d=3 forward 3,6 4,5 5,4
+search a[0:3] b[0:2]
+`
+	if !strings.HasPrefix(sb.String(), expect) {
+		t.Errorf("expected text to start with\n%s\ngot\n%s", expect, sb.String())
+	}
+
+	sb.Reset()
+	if err := trace.WriteDOT(&sb); err != nil {
+		t.Fatal(err)
+	}
+	dot := sb.String()
+	if n := strings.Count(dot, "subgraph cluster_"); n != 4 {
+		t.Error("expected 4 searches, got", n)
+	}
+	// the snake of the first forward 2-path and the edit before it
+	for _, s := range []string{
+		"s0_1_0 -> s0_1_1 [color=blue];",
+		"s0_1_1 -> s0_2_2 [color=blue, style=bold];",
+		"s0_7_5 -> s0_5_3 [color=red, style=bold];",
+	} {
+		if !strings.Contains(dot, s) {
+			t.Error("expected DOT to contain", s)
+		}
+	}
+
+	// other algorithms are not traced
+	trace = diff.Trace{}
+	diff.Diff(len(test.a), len(test.b), data, diff.WithTrace(&trace), diff.WithAlgorithm(diff.Wu))
+	if len(trace.Steps) != 0 {
+		t.Error("expected no steps, got", trace.Steps)
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package treediff computes the differences of ordered trees with the
+// tree edit distance algorithm by Kaizhong Zhang and Dennis Shasha.
+//
+// The algorithm is described in "Simple Fast Algorithms for the Editing
+// Distance between Trees and Related Problems", SIAM J. Comput. 18(6), 1989.
+package treediff
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A Node is a node of an ordered tree.
+type Node interface {
+	Label() string
+	Children() []Node
+}
+
+// An Op is the kind of an Operation.
+type Op int
+
+const (
+	// Insert inserts a node of b.
+	Insert Op = iota + 1
+	// Delete deletes a node of a, its children take its place.
+	Delete
+	// Relabel changes the label of a node.
+	Relabel
+	// Move deletes a whole subtree of a and inserts it unchanged in b.
+	Move
+)
+
+func (op Op) String() string {
+	switch op {
+	case Insert:
+		return "insert"
+	case Delete:
+		return "delete"
+	case Relabel:
+		return "relabel"
+	case Move:
+		return "move"
+	}
+	return "unknown"
+}
+
+// An Operation is an edit of a tree. From is the node in a and To the node
+// in b, From is nil for insertions and To for deletions. The paths hold the
+// child indices leading from the roots to the nodes.
+type Operation struct {
+	Op               Op
+	From, To         Node
+	FromPath, ToPath []int
+}
+
+// Distance returns the number of insertions, deletions and relabelings
+// needed to turn the tree a into b. A nil node is an empty tree.
+func Distance(a, b Node) int {
+	ta, tb := newTree(a), newTree(b)
+	return newTreeDist(ta, tb).td[ta.n()][tb.n()]
+}
+
+// Diff returns a minimal list of operations that turn the tree a into b.
+// Subtrees that were deleted and inserted unchanged are reported as one
+// move instead of operations for each of their nodes. The operations are
+// ordered by the position of From in a, insertions come last in the
+// order of b. A nil node is an empty tree.
+func Diff(a, b Node) []Operation {
+	ta, tb := newTree(a), newTree(b)
+	d := newTreeDist(ta, tb)
+	matched := make([]int, ta.n()+1) // the node of b matched with a node of a
+	inserted := make([]bool, tb.n()+1)
+	for i := range inserted {
+		inserted[i] = true
+	}
+	inserted[0] = false
+	if ta.n() > 0 && tb.n() > 0 {
+		d.mapping(ta.n(), tb.n(), matched, inserted)
+	}
+	moved := moves(ta, tb, matched, inserted)
+
+	var ops []Operation
+	for i := 1; i <= ta.n(); i++ {
+		j, ok := moved[i]
+		switch {
+		case ok:
+			ops = append(ops, Operation{Op: Move, From: ta.nodes[i], To: tb.nodes[j], FromPath: ta.paths[i], ToPath: tb.paths[j]})
+		case matched[i] == -1:
+			// part of a moved subtree
+		case matched[i] == 0:
+			ops = append(ops, Operation{Op: Delete, From: ta.nodes[i], FromPath: ta.paths[i]})
+		case ta.nodes[i].Label() != tb.nodes[matched[i]].Label():
+			j := matched[i]
+			ops = append(ops, Operation{Op: Relabel, From: ta.nodes[i], To: tb.nodes[j], FromPath: ta.paths[i], ToPath: tb.paths[j]})
+		}
+	}
+	for j := 1; j <= tb.n(); j++ {
+		if inserted[j] {
+			ops = append(ops, Operation{Op: Insert, To: tb.nodes[j], ToPath: tb.paths[j]})
+		}
+	}
+	sort.SliceStable(ops, func(i, j int) bool {
+		if ops[i].From == nil || ops[j].From == nil {
+			return ops[j].From == nil && ops[i].From != nil
+		}
+		return lessPath(ops[i].FromPath, ops[j].FromPath)
+	})
+	return ops
+}
+
+// lessPath reports whether the node at p comes before the node at q in
+// document order.
+func lessPath(p, q []int) bool {
+	for k := 0; k < len(p) && k < len(q); k++ {
+		if p[k] != q[k] {
+			return p[k] < q[k]
+		}
+	}
+	return len(p) < len(q)
+}
+
+// tree holds the nodes of a tree in postorder, numbered from 1.
+type tree struct {
+	nodes []Node
+	paths [][]int
+	l     []int // the leftmost leaf descendant of every node
+	// parent of every node, 0 for the root
+	parent   []int
+	keyroots []int
+}
+
+func newTree(root Node) *tree {
+	t := &tree{nodes: []Node{nil}, paths: [][]int{nil}, l: []int{0}, parent: []int{0}}
+	if root != nil {
+		t.add(root, nil)
+	}
+	for i := 1; i < len(t.nodes); i++ {
+		// the highest node with a leftmost leaf is a keyroot
+		if i == t.n() || t.l[t.parent[i]] != t.l[i] {
+			t.keyroots = append(t.keyroots, i)
+		}
+	}
+	return t
+}
+
+// add numbers n and its descendants and returns the number of n.
+func (t *tree) add(n Node, path []int) int {
+	first := 0
+	var children []int
+	for k, c := range n.Children() {
+		i := t.add(c, append(path[:len(path):len(path)], k))
+		if k == 0 {
+			first = t.l[i]
+		}
+		children = append(children, i)
+	}
+	i := len(t.nodes)
+	if first == 0 {
+		first = i
+	}
+	t.nodes = append(t.nodes, n)
+	t.paths = append(t.paths, path)
+	t.l = append(t.l, first)
+	t.parent = append(t.parent, 0)
+	for _, c := range children {
+		t.parent[c] = i
+	}
+	return i
+}
+
+func (t *tree) n() int { return len(t.nodes) - 1 }
+
+type treeDist struct {
+	a, b *tree
+	td   [][]int // the distance of the subtrees i of a and j of b
+}
+
+func newTreeDist(a, b *tree) *treeDist {
+	d := &treeDist{a: a, b: b, td: make([][]int, a.n()+1)}
+	for i := range d.td {
+		d.td[i] = make([]int, b.n()+1)
+	}
+	// distances to and from an empty tree
+	d.td[a.n()][0] = a.n()
+	d.td[0][b.n()] = b.n()
+	for _, i := range a.keyroots {
+		for _, j := range b.keyroots {
+			d.forestDist(i, j)
+		}
+	}
+	return d
+}
+
+// forestDist computes the distances of the forests of the subtrees i and j
+// and the tree distances of their subtrees with the same leftmost leaves.
+// fd[x][y] is the distance of the nodes l(i)..x-1+l(i) and l(j)..y-1+l(j).
+func (d *treeDist) forestDist(i, j int) [][]int {
+	a, b := d.a, d.b
+	li, lj := a.l[i], b.l[j]
+	fd := make([][]int, i-li+2)
+	for x := range fd {
+		fd[x] = make([]int, j-lj+2)
+		fd[x][0] = x
+	}
+	for y := range fd[0] {
+		fd[0][y] = y
+	}
+	for x := 1; x <= i-li+1; x++ {
+		for y := 1; y <= j-lj+1; y++ {
+			ni, nj := li+x-1, lj+y-1
+			cost := fd[x-1][y] + 1
+			if c := fd[x][y-1] + 1; c < cost {
+				cost = c
+			}
+			if a.l[ni] == li && b.l[nj] == lj {
+				c := fd[x-1][y-1]
+				if a.nodes[ni].Label() != b.nodes[nj].Label() {
+					c++
+				}
+				if c < cost {
+					cost = c
+				}
+				d.td[ni][nj] = cost
+			} else if c := fd[a.l[ni]-li][b.l[nj]-lj] + d.td[ni][nj]; c < cost {
+				cost = c
+			}
+			fd[x][y] = cost
+		}
+	}
+	return fd
+}
+
+// mapping backtracks the distance of the subtrees i and j and records the
+// matched nodes of a and the nodes of b that are matched.
+func (d *treeDist) mapping(i, j int, matched []int, inserted []bool) {
+	a, b := d.a, d.b
+	fd := d.forestDist(i, j)
+	li, lj := a.l[i], b.l[j]
+	x, y := i-li+1, j-lj+1
+	for x > 0 || y > 0 {
+		ni, nj := li+x-1, lj+y-1
+		switch {
+		case x > 0 && fd[x][y] == fd[x-1][y]+1:
+			x--
+		case y > 0 && fd[x][y] == fd[x][y-1]+1:
+			y--
+		case a.l[ni] == li && b.l[nj] == lj:
+			matched[ni] = nj
+			inserted[nj] = false
+			x, y = x-1, y-1
+		default:
+			d.mapping(ni, nj, matched, inserted)
+			x, y = a.l[ni]-li, b.l[nj]-lj
+		}
+	}
+}
+
+// moves pairs the largest deleted subtrees of a with equal inserted subtrees
+// of b and marks their nodes as neither deleted nor inserted. It returns the
+// roots of the moved subtrees of a with their counterparts in b.
+func moves(a, b *tree, matched []int, inserted []bool) map[int]int {
+	deleted := make([]bool, a.n()+1)
+	for i := 1; i <= a.n(); i++ {
+		deleted[i] = matched[i] == 0
+	}
+	rootsA := wholeSubtrees(a, deleted)
+	rootsB := wholeSubtrees(b, inserted)
+	if len(rootsA) == 0 || len(rootsB) == 0 {
+		return nil
+	}
+	available := make(map[string][]int)
+	for _, j := range rootsB {
+		s := signature(b.nodes[j])
+		available[s] = append(available[s], j)
+	}
+	moved := make(map[int]int)
+	for _, i := range rootsA {
+		s := signature(a.nodes[i])
+		js := available[s]
+		if len(js) == 0 {
+			continue
+		}
+		j := js[0]
+		available[s] = js[1:]
+		moved[i] = j
+		for k := a.l[i]; k < i; k++ {
+			matched[k] = -1
+		}
+		for k := b.l[j]; k <= j; k++ {
+			inserted[k] = false
+		}
+		// the root is reported as moved
+		matched[i] = -1
+	}
+	return moved
+}
+
+// wholeSubtrees returns the highest nodes in postorder whose subtrees are
+// completely marked.
+func wholeSubtrees(t *tree, marked []bool) []int {
+	whole := make([]bool, t.n()+1)
+	var roots []int
+	for i := 1; i <= t.n(); i++ {
+		whole[i] = marked[i]
+		for k := t.l[i]; k < i && whole[i]; k++ {
+			whole[i] = marked[k]
+		}
+	}
+	for i := 1; i <= t.n(); i++ {
+		if whole[i] && (i == t.n() || !whole[t.parent[i]]) {
+			roots = append(roots, i)
+		}
+	}
+	return roots
+}
+
+// signature encodes the labels and the shape of the subtree at n.
+func signature(n Node) string {
+	var sb strings.Builder
+	var write func(n Node)
+	write = func(n Node) {
+		sb.WriteString(strconv.Quote(n.Label()))
+		sb.WriteByte('(')
+		for _, c := range n.Children() {
+			write(c)
+		}
+		sb.WriteByte(')')
+	}
+	write(n)
+	return sb.String()
+}
@@ -0,0 +1,163 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package treediff_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff/treediff"
+)
+
+type node struct {
+	label    string
+	children []treediff.Node
+}
+
+func (n *node) Label() string             { return n.label }
+func (n *node) Children() []treediff.Node { return n.children }
+
+// parse parses trees written like "a(b c(d))".
+func parse(s string) treediff.Node {
+	n, rest := parseNode(s)
+	if rest != "" {
+		panic("trailing " + rest)
+	}
+	return n
+}
+
+func parseNode(s string) (*node, string) {
+	i := strings.IndexAny(s, "( )")
+	if i == -1 {
+		return &node{label: s}, ""
+	}
+	n := &node{label: s[:i]}
+	s = s[i:]
+	if s[0] != '(' {
+		return n, s
+	}
+	s = s[1:]
+	for s[0] != ')' {
+		var c *node
+		c, s = parseNode(strings.TrimPrefix(s, " "))
+		n.children = append(n.children, c)
+	}
+	return n, s[1:]
+}
+
+func format(ops []treediff.Operation) string {
+	var res []string
+	for _, op := range ops {
+		s := op.Op.String()
+		if op.From != nil {
+			s += fmt.Sprintf(" %s%v", op.From.Label(), op.FromPath)
+		}
+		if op.To != nil {
+			s += fmt.Sprintf(" %s%v", op.To.Label(), op.ToPath)
+		}
+		res = append(res, s)
+	}
+	return strings.Join(res, ", ")
+}
+
+func TestDiff(t *testing.T) {
+	for _, test := range []struct {
+		a, b     string
+		distance int
+		ops      string
+	}{
+		{"f(d(a c(b)) e)", "f(c(d(a b)) e)", 2, "delete c[0 1], insert c[0]"},
+		{"a(b c)", "a(b c)", 0, ""},
+		{"a(b c)", "x(b d)", 2, "relabel a[] x[], relabel c[1] d[1]"},
+		{"a(b(x y) c)", "a(c b(x y))", 2, "move c[1] c[0]"},
+		{"a(b c d)", "a(b(c d))", 2, "delete b[0], insert b[0]"},
+		{"a", "a(b)", 1, "insert b[0]"},
+	} {
+		a, b := parse(test.a), parse(test.b)
+		if d := treediff.Distance(a, b); d != test.distance {
+			t.Error(test.a, test.b, "expected distance", test.distance, "got", d)
+		}
+		if ops := format(treediff.Diff(a, b)); ops != test.ops {
+			t.Errorf("%s %s: expected %q, got %q", test.a, test.b, test.ops, ops)
+		}
+	}
+}
+
+func TestDiffEmpty(t *testing.T) {
+	a := parse("a(b c)")
+	if d := treediff.Distance(a, nil); d != 3 {
+		t.Error("expected distance 3, got", d)
+	}
+	if ops := format(treediff.Diff(nil, a)); ops != "insert b[0], insert c[1], insert a[]" {
+		t.Error("unexpected operations", ops)
+	}
+	if ops := treediff.Diff(nil, nil); len(ops) != 0 {
+		t.Error("expected no operations, got", ops)
+	}
+}
+
+func randomTree(r *rand.Rand, size int) *node {
+	n := &node{label: string(rune('a' + r.Intn(3)))}
+	for size--; size > 0; {
+		k := 1 + r.Intn(size)
+		n.children = append(n.children, randomTree(r, k))
+		size -= k
+	}
+	return n
+}
+
+// forestDist is the recursive definition of the edit distance of forests.
+func forestDist(f, g []treediff.Node) int {
+	if len(f) == 0 || len(g) == 0 {
+		return size(f) + size(g)
+	}
+	v, w := f[len(f)-1], g[len(g)-1]
+	fv := append(f[:len(f)-1:len(f)-1], v.Children()...)
+	gw := append(g[:len(g)-1:len(g)-1], w.Children()...)
+	d := forestDist(fv, g) + 1
+	if e := forestDist(f, gw) + 1; e < d {
+		d = e
+	}
+	e := forestDist(f[:len(f)-1], g[:len(g)-1]) + forestDist(v.Children(), w.Children())
+	if v.Label() != w.Label() {
+		e++
+	}
+	if e < d {
+		d = e
+	}
+	return d
+}
+
+func size(f []treediff.Node) int {
+	n := len(f)
+	for _, c := range f {
+		n += size(c.Children())
+	}
+	return n
+}
+
+func TestDiffRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		a, b := randomTree(r, 1+r.Intn(6)), randomTree(r, 1+r.Intn(6))
+		d := treediff.Distance(a, b)
+		if e := forestDist([]treediff.Node{a}, []treediff.Node{b}); d != e {
+			t.Fatal(a, b, "expected distance", e, "got", d)
+		}
+		cost := 0
+		for _, op := range treediff.Diff(a, b) {
+			if op.Op == treediff.Move {
+				cost += 2 * size([]treediff.Node{op.From})
+			} else {
+				cost++
+			}
+		}
+		if cost != d {
+			t.Fatal(a, b, "operations cost", cost, "instead of", d)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "bytes"
+
+// CommonPrefix returns the number of equal elements at the start of data.
+// Diff and the other entry points trim it before running the algorithm.
+func CommonPrefix(n, m int, data Data) int {
+	i := 0
+	for i < n && i < m && data.Equal(i, i) {
+		i++
+	}
+	return i
+}
+
+// CommonSuffix returns the number of equal elements at the end of data.
+func CommonSuffix(n, m int, data Data) int {
+	i := 0
+	for i < n && i < m && data.Equal(n-1-i, m-1-i) {
+		i++
+	}
+	return i
+}
+
+// trimBlock is the block size compared at once by the byte fast paths.
+const trimBlock = 64
+
+// CommonPrefixBytes returns the length of the common prefix of a and b.
+// Long prefixes are compared in blocks using the optimized bytes.Equal.
+func CommonPrefixBytes(a, b []byte) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	i := 0
+	for i+trimBlock <= n && bytes.Equal(a[i:i+trimBlock], b[i:i+trimBlock]) {
+		i += trimBlock
+	}
+	for i < n && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// CommonSuffixBytes returns the length of the common suffix of a and b.
+func CommonSuffixBytes(a, b []byte) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	la, lb := len(a), len(b)
+	i := 0
+	for i+trimBlock <= n && bytes.Equal(a[la-i-trimBlock:la-i], b[lb-i-trimBlock:lb-i]) {
+		i += trimBlock
+	}
+	for i < n && a[la-1-i] == b[lb-1-i] {
+		i++
+	}
+	return i
+}
+
+// commonPrefixString is CommonPrefixBytes for strings.
+func commonPrefixString(a, b string) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	i := 0
+	for i+trimBlock <= n && a[i:i+trimBlock] == b[i:i+trimBlock] {
+		i += trimBlock
+	}
+	for i < n && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// commonSuffixString is CommonSuffixBytes for strings.
+func commonSuffixString(a, b string) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	la, lb := len(a), len(b)
+	i := 0
+	for i+trimBlock <= n && a[la-i-trimBlock:la-i] == b[lb-i-trimBlock:lb-i] {
+		i += trimBlock
+	}
+	for i < n && a[la-1-i] == b[lb-1-i] {
+		i++
+	}
+	return i
+}
+
+// shiftChanges moves changes of a trimmed input by the length of the trimmed prefix.
+func shiftChanges(changes []Change, prefix int) []Change {
+	for i := range changes {
+		changes[i].A += prefix
+		changes[i].B += prefix
+	}
+	return changes
+}
@@ -0,0 +1,58 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestCommonPrefixSuffix(t *testing.T) {
+	for _, test := range []struct {
+		a, b           string
+		prefix, suffix int
+	}{
+		{"", "", 0, 0},
+		{"abc", "", 0, 0},
+		{"abc", "abc", 3, 3},
+		{"abcd", "abxd", 2, 1},
+		{"ab", "abab", 2, 2},
+		{"x" + string(bytes.Repeat([]byte("y"), 200)), "z" + string(bytes.Repeat([]byte("y"), 200)), 0, 200},
+		{string(bytes.Repeat([]byte("y"), 200)) + "x", string(bytes.Repeat([]byte("y"), 200)) + "z", 200, 0},
+	} {
+		a, b := []byte(test.a), []byte(test.b)
+		if p := diff.CommonPrefixBytes(a, b); p != test.prefix {
+			t.Errorf("CommonPrefixBytes(%q, %q) = %d, expected %d", test.a, test.b, p, test.prefix)
+		}
+		if s := diff.CommonSuffixBytes(a, b); s != test.suffix {
+			t.Errorf("CommonSuffixBytes(%q, %q) = %d, expected %d", test.a, test.b, s, test.suffix)
+		}
+		data := &bytesData{a, b}
+		if p := diff.CommonPrefix(len(a), len(b), data); p != test.prefix {
+			t.Errorf("CommonPrefix(%q, %q) = %d, expected %d", test.a, test.b, p, test.prefix)
+		}
+		if s := diff.CommonSuffix(len(a), len(b), data); s != test.suffix {
+			t.Errorf("CommonSuffix(%q, %q) = %d, expected %d", test.a, test.b, s, test.suffix)
+		}
+	}
+}
+
+func TestBytesTrimmed(t *testing.T) {
+	common := bytes.Repeat([]byte("0123456789"), 100)
+	a := append(append(append([]byte{}, common...), "abc"...), common...)
+	b := append(append(append([]byte{}, common...), "axc"...), common...)
+	expect := []diff.Change{{A: 1001, B: 1001, Del: 1, Ins: 1}}
+	if res := diff.Bytes(a, b); !diffsEqual(res, expect) {
+		t.Error("Bytes expected", expect, "got", res)
+	}
+	if res := diff.ByteStrings(string(a), string(b)); !diffsEqual(res, expect) {
+		t.Error("ByteStrings expected", expect, "got", res)
+	}
+	if res := diff.Bytes(a, a); len(res) != 0 {
+		t.Error("expected no changes, got", res)
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package typediff picks how to diff two files by their content type,
+// like a structural diff for JSON and a line diff for other text.
+package typediff
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	_ "image/gif"  // register the image formats
+	_ "image/jpeg" // that the image handler decodes
+	_ "image/png"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/echlebek/diff"
+	"github.com/echlebek/diff/fsdiff"
+	"github.com/echlebek/diff/godiff"
+	"github.com/echlebek/diff/imagediff"
+	"github.com/echlebek/diff/xmldiff"
+	"github.com/echlebek/diff/yamldiff"
+)
+
+// A Handler writes the differences of the contents a and b of two files
+// of a content type to w. It only writes something if they differ.
+type Handler func(w io.Writer, nameA, nameB string, a, b []byte) error
+
+// A Registry holds the handlers for content types.
+type Registry struct {
+	handlers map[string]Handler
+}
+
+// NewRegistry returns a registry with handlers for Go source, JSON,
+// YAML, XML and images. Other files are diffed by line, or reported as
+// differing binary files by Binary.
+func NewRegistry() *Registry {
+	r := &Registry{handlers: make(map[string]Handler)}
+	r.Register("text/*", Text)
+	r.Register("text/x-go", Go)
+	r.Register("application/json", YAML)
+	r.Register("application/yaml", YAML)
+	r.Register("application/xml", XML)
+	r.Register("text/xml", XML)
+	r.Register("image/*", Image)
+	return r
+}
+
+// Register sets the handler for a content type like "application/json",
+// or for all subtypes of a type with a pattern like "image/*".
+// Handlers of content types take precedence over those of patterns.
+func (r *Registry) Register(pattern string, h Handler) {
+	r.handlers[pattern] = h
+}
+
+// Handler returns the handler for the content type t. Parameters like
+// a charset are ignored. Without a registered handler, files that look
+// binary are handled by Binary and the others by Text.
+func (r *Registry) Handler(t string, data []byte) Handler {
+	if mt, _, err := mime.ParseMediaType(t); err == nil {
+		t = mt
+	}
+	if h, ok := r.handlers[t]; ok {
+		return h
+	}
+	if major, _, ok := strings.Cut(t, "/"); ok {
+		if h, ok := r.handlers[major+"/*"]; ok {
+			return h
+		}
+	}
+	if diff.IsBinary(data) {
+		return Binary
+	}
+	return Text
+}
+
+// Diff writes the differences of two files with the handler of the
+// content type of the second file, see ContentType.
+func (r *Registry) Diff(w io.Writer, nameA, nameB string, a, b []byte) error {
+	return r.Handler(ContentType(nameB, b), b)(w, nameA, nameB, a, b)
+}
+
+// Compare writes the differences of the files of two trees like diff -r.
+// Files only in one tree are listed and modified files are diffed with
+// Diff, ordered by path.
+func (r *Registry) Compare(w io.Writer, a, b fs.FS) error {
+	entries, err := fsdiff.Compare(a, b, nil)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		switch e.Status {
+		case fsdiff.Added:
+			_, err = fmt.Fprintf(w, "Only in b: %s\n", e.Path)
+		case fsdiff.Removed:
+			_, err = fmt.Fprintf(w, "Only in a: %s\n", e.Path)
+		default:
+			var da, db []byte
+			if da, err = fs.ReadFile(a, e.Path); err != nil {
+				return err
+			}
+			if db, err = fs.ReadFile(b, e.Path); err != nil {
+				return err
+			}
+			err = r.Diff(w, "a/"+e.Path, "b/"+e.Path, da, db)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extensions maps file extensions to content types that are missing
+// from the mime package on some systems.
+var extensions = map[string]string{
+	".go":   "text/x-go",
+	".json": "application/json",
+	".xml":  "application/xml",
+	".yaml": "application/yaml",
+	".yml":  "application/yaml",
+}
+
+// ContentType returns the content type of a file by the extension of its
+// name, or by sniffing data like http.DetectContentType.
+func ContentType(name string, data []byte) string {
+	ext := strings.ToLower(path.Ext(name))
+	if t, ok := extensions[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return http.DetectContentType(data)
+}
+
+// Text writes the unified diff of the lines of two files.
+func Text(w io.Writer, nameA, nameB string, a, b []byte) error {
+	d := diff.Contents(a, b)
+	if d.Binary {
+		return Binary(w, nameA, nameB, a, b)
+	}
+	return diff.WriteUnified(w, nameA, nameB, d.A, d.B, d.Changes, 3)
+}
+
+// Binary writes that two files differ with their sizes and hashes,
+// see diff.WriteBinary.
+func Binary(w io.Writer, nameA, nameB string, a, b []byte) error {
+	if bytes.Equal(a, b) {
+		return nil
+	}
+	return diff.WriteBinary(w, nameA, nameB, &diff.FileDiff{
+		Binary: true,
+		SizeA:  len(a), SizeB: len(b),
+		HashA: sha256.Sum256(a), HashB: sha256.Sum256(b),
+	})
+}
+
+// Go writes the changed declarations of two Go source files, see
+// godiff.CompareSource. Files that don't parse are diffed by Text.
+func Go(w io.Writer, nameA, nameB string, a, b []byte) error {
+	changes, err := godiff.CompareSource(nameA, a, nameB, b)
+	if err != nil {
+		return Text(w, nameA, nameB, a, b)
+	}
+	return writeChanges(w, nameA, nameB, changes)
+}
+
+// YAML writes the changed nodes of two YAML or JSON documents, see
+// yamldiff.Compare. Documents that don't parse are diffed by Text.
+func YAML(w io.Writer, nameA, nameB string, a, b []byte) error {
+	changes, err := yamldiff.Compare(a, b, nil)
+	if err != nil {
+		return Text(w, nameA, nameB, a, b)
+	}
+	return writeChanges(w, nameA, nameB, changes)
+}
+
+// XML writes the changed nodes of two XML documents, see xmldiff.Compare.
+// Documents that don't parse are diffed by Text.
+func XML(w io.Writer, nameA, nameB string, a, b []byte) error {
+	changes, err := xmldiff.Compare(a, b, nil)
+	if err != nil {
+		return Text(w, nameA, nameB, a, b)
+	}
+	return writeChanges(w, nameA, nameB, changes)
+}
+
+// Image writes the changed regions of two images, see imagediff.Compare.
+// Images that don't decode are handled by Binary.
+func Image(w io.Writer, nameA, nameB string, a, b []byte) error {
+	ma, _, err := image.Decode(bytes.NewReader(a))
+	if err != nil {
+		return Binary(w, nameA, nameB, a, b)
+	}
+	mb, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return Binary(w, nameA, nameB, a, b)
+	}
+	regions := imagediff.Compare(ma, mb, 0)
+	if len(regions) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("--- " + nameA + "\n")
+	sb.WriteString("+++ " + nameB + "\n")
+	for _, r := range regions {
+		sb.WriteString(r.A.String() + "\t" + r.B.String() + "\n")
+	}
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// writeChanges writes the header lines of a diff followed by one line
+// for every change.
+func writeChanges[T fmt.Stringer](w io.Writer, nameA, nameB string, changes []T) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("--- " + nameA + "\n")
+	sb.WriteString("+++ " + nameB + "\n")
+	for _, c := range changes {
+		sb.WriteString(c.String() + "\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
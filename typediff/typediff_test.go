@@ -0,0 +1,101 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typediff_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/echlebek/diff/typediff"
+)
+
+func TestContentType(t *testing.T) {
+	for _, test := range []struct {
+		name, data, expect string
+	}{
+		{"a.json", "{}", "application/json"},
+		{"a.YML", "a: 1", "application/yaml"},
+		{"main.go", "package main", "text/x-go"},
+		{"README", "hello\n", "text/plain; charset=utf-8"},
+		{"blob", "\x89PNG\r\n\x1a\n", "image/png"},
+	} {
+		if res := typediff.ContentType(test.name, []byte(test.data)); res != test.expect {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expect, res)
+		}
+	}
+}
+
+func pngImage(t *testing.T, c color.Color) []byte {
+	t.Helper()
+	m := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	m.Set(1, 1, c)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDiff(t *testing.T) {
+	r := typediff.NewRegistry()
+	for _, test := range []struct {
+		name   string
+		a, b   []byte
+		expect string
+	}{
+		{"x.json", []byte(`{"a": 1, "b": 2}`), []byte(`{"a": 1, "b": 3}`), "--- a/x.json\n+++ b/x.json\nM\tb\n"},
+		{"x.json", []byte(`{"a": 1}`), []byte(`{"a": 1}`), ""},
+		{"x.json", []byte("{\n"), []byte("[\n"), "--- a/x.json\n+++ b/x.json\n@@ -1 +1 @@\n-{\n+[\n"},
+		{"x.txt", []byte("a\nb\n"), []byte("a\nc\n"), "--- a/x.txt\n+++ b/x.txt\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n"},
+		{"x.bin", []byte("\x00"), []byte("\x01"), "Binary files a/x.bin and b/x.bin differ\n"},
+		{"x.png", pngImage(t, color.Black), pngImage(t, color.White), "--- a/x.png\n+++ b/x.png\n(1,1)-(2,2)\t(1,1)-(2,2)\n"},
+	} {
+		var sb strings.Builder
+		if err := r.Diff(&sb, "a/"+test.name, "b/"+test.name, test.a, test.b); err != nil {
+			t.Fatal(test.name, err)
+		}
+		if res := sb.String(); !strings.HasPrefix(res, test.expect) || test.expect == "" && res != "" {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expect, res)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	r := typediff.NewRegistry()
+	r.Register("application/*", func(w io.Writer, nameA, nameB string, a, b []byte) error {
+		_, err := io.WriteString(w, "application\n")
+		return err
+	})
+	r.Register("application/json", func(w io.Writer, nameA, nameB string, a, b []byte) error {
+		_, err := io.WriteString(w, "json\n")
+		return err
+	})
+	a := fstest.MapFS{
+		"same.txt": {Data: []byte("x\n")},
+		"x.json":   {Data: []byte("1")},
+		"x.pdf":    {Data: []byte("%PDF-1")},
+		"gone":     {Data: []byte("-")},
+	}
+	b := fstest.MapFS{
+		"same.txt": {Data: []byte("x\n")},
+		"x.json":   {Data: []byte("2")},
+		"x.pdf":    {Data: []byte("%PDF-2")},
+		"new":      {Data: []byte("+")},
+	}
+	var sb strings.Builder
+	if err := r.Compare(&sb, a, b); err != nil {
+		t.Fatal(err)
+	}
+	expect := "Only in a: gone\nOnly in b: new\njson\napplication\n"
+	if sb.String() != expect {
+		t.Errorf("expected %q, got %q", expect, sb.String())
+	}
+}
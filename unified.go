@@ -0,0 +1,81 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteUnified writes the changes of lines a to b in the unified format of
+// diff -u, with context common lines around the changes. nameA and nameB
+// are written to the header lines and may contain a tab separated time stamp.
+// Nothing is written if there are no changes.
+func WriteUnified(w io.Writer, nameA, nameB string, a, b []string, changes []Change, context int) error {
+	hunks := lineHunks(a, b, changes, context)
+	if len(hunks) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("--- " + nameA + "\n")
+	sb.WriteString("+++ " + nameB + "\n")
+	for _, h := range hunks {
+		writeHunk(&sb, h)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// lineHunks returns the hunks of changes of lines a to b with their Lines set.
+func lineHunks(a, b []string, changes []Change, context int) []Hunk {
+	hunks := Hunks(len(a), len(b), changes, context)
+	for i := range hunks {
+		h := &hunks[i]
+		x := h.A
+		for _, c := range h.Changes {
+			h.Lines = appendPrefixed(h.Lines, ' ', a[x:c.A])
+			h.Lines = appendPrefixed(h.Lines, '-', a[c.A:c.A+c.Del])
+			h.Lines = appendPrefixed(h.Lines, '+', b[c.B:c.B+c.Ins])
+			x = c.A + c.Del
+		}
+		h.Lines = appendPrefixed(h.Lines, ' ', a[x:h.A+h.LenA])
+	}
+	return hunks
+}
+
+func appendPrefixed(dst []string, prefix byte, lines []string) []string {
+	for _, line := range lines {
+		dst = append(dst, string(prefix)+line)
+	}
+	return dst
+}
+
+// writeHunk writes the header and lines of a hunk in unified format.
+func writeHunk(sb *strings.Builder, h Hunk) {
+	sb.WriteString("@@ -" + unifiedRange(h.A, h.LenA) + " +" + unifiedRange(h.B, h.LenB) + " @@")
+	if h.Section != "" {
+		sb.WriteString(" " + h.Section)
+	}
+	sb.WriteByte('\n')
+	for _, line := range h.Lines {
+		sb.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// unifiedRange returns the one based start and length of the n lines
+// at start. Without lines the start is the line before.
+func unifiedRange(start, n int) string {
+	switch n {
+	case 0:
+		return strconv.Itoa(start) + ",0"
+	case 1:
+		return strconv.Itoa(start + 1)
+	}
+	return strconv.Itoa(start+1) + "," + strconv.Itoa(n)
+}
@@ -0,0 +1,57 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestWriteUnified(t *testing.T) {
+	a := splitLines("a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n")
+	b := append(splitLines("x\na\nB\nc\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"), "n")
+	var sb strings.Builder
+	if err := diff.WriteUnified(&sb, "a.txt", "b.txt", a, b, diff.Lines(a, b), 3); err != nil {
+		t.Fatal(err)
+	}
+	// the same as GNU diff -u
+	expect := "" +
+		"--- a.txt\n" +
+		"+++ b.txt\n" +
+		"@@ -1,7 +1,7 @@\n" +
+		"+x\n" +
+		" a\n" +
+		"-b\n" +
+		"+B\n" +
+		" c\n" +
+		"-d\n" +
+		" e\n" +
+		" f\n" +
+		" g\n" +
+		"@@ -11,3 +11,4 @@\n" +
+		" k\n" +
+		" l\n" +
+		" m\n" +
+		"+n\n" +
+		"\\ No newline at end of file\n"
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+
+	sb.Reset()
+	if err := diff.WriteUnified(&sb, "a", "b", a, a, nil, 3); err != nil || sb.Len() != 0 {
+		t.Error("expected no output, got", sb.String(), err)
+	}
+	empty := []string{}
+	if err := diff.WriteUnified(&sb, "e", "a", empty, a[:1], diff.Lines(empty, a[:1]), 3); err != nil {
+		t.Fatal(err)
+	}
+	expect = "--- e\n+++ a\n@@ -0,0 +1 @@\n+a\n"
+	if sb.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, sb.String())
+	}
+}
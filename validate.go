@@ -0,0 +1,38 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import "fmt"
+
+// Validate checks that changes describe a transformation of a sequence
+// of length n into a sequence of length m: every change deletes or inserts
+// something within bounds, the changes are ordered by ascending positions
+// without overlapping, and the common elements between them have the same
+// length in both sequences.
+func Validate(n, m int, changes []Change) error {
+	if n < 0 || m < 0 {
+		return fmt.Errorf("diff: negative length %d, %d", n, m)
+	}
+	x, y := 0, 0
+	for i, c := range changes {
+		switch {
+		case c.Del < 0 || c.Ins < 0:
+			return fmt.Errorf("diff: change %d %v has a negative length", i, c)
+		case c.Del == 0 && c.Ins == 0:
+			return fmt.Errorf("diff: change %d %v is empty", i, c)
+		case c.A < x || c.B < y:
+			return fmt.Errorf("diff: change %d %v overlaps the previous change", i, c)
+		case c.A-x != c.B-y:
+			return fmt.Errorf("diff: change %d %v follows %d common elements of a but %d of b", i, c, c.A-x, c.B-y)
+		case c.A+c.Del > n || c.B+c.Ins > m:
+			return fmt.Errorf("diff: change %d %v is out of bounds %d, %d", i, c, n, m)
+		}
+		x, y = c.A+c.Del, c.B+c.Ins
+	}
+	if n-x != m-y {
+		return fmt.Errorf("diff: changes end with %d common elements of a but %d of b", n-x, m-y)
+	}
+	return nil
+}
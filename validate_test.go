@@ -0,0 +1,44 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestValidate(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		a, b := randomInts(r, r.Intn(30), 4), randomInts(r, r.Intn(30), 4)
+		if err := diff.Validate(len(a), len(b), diff.Ints(a, b)); err != nil {
+			t.Fatal(a, b, err)
+		}
+	}
+	for _, test := range []struct {
+		name    string
+		n, m    int
+		changes []diff.Change
+	}{
+		{"negative length", -1, 0, nil},
+		{"negative change", 3, 3, []diff.Change{{A: 0, B: 0, Del: -1, Ins: -1}}},
+		{"empty change", 3, 3, []diff.Change{{A: 1, B: 1}}},
+		{"unordered", 5, 5, []diff.Change{{A: 3, B: 3, Del: 1, Ins: 1}, {A: 1, B: 1, Del: 1, Ins: 1}}},
+		{"overlapping", 5, 5, []diff.Change{{A: 1, B: 1, Del: 2, Ins: 2}, {A: 2, B: 3, Del: 1, Ins: 1}}},
+		{"inconsistent gap", 5, 5, []diff.Change{{A: 1, B: 2, Del: 1, Ins: 0}}},
+		{"out of bounds", 3, 3, []diff.Change{{A: 2, B: 2, Del: 2, Ins: 2}}},
+		{"inconsistent end", 3, 4, []diff.Change{{A: 0, B: 0, Del: 1, Ins: 1}}},
+		{"unchanged lengths differ", 3, 4, nil},
+	} {
+		if err := diff.Validate(test.n, test.m, test.changes); err == nil {
+			t.Error(test.name, "expected an error")
+		}
+	}
+	if err := diff.Validate(0, 0, nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Words returns the differences of two strings in words.
+// The strings are split into words of letters and digits, runs of white space,
+// and single punctuation characters. The positions and lengths of the changes
+// are byte offsets into a and b that always fall on these boundaries.
+func Words(a, b string) []Change {
+	return tokenChanges(splitWords(a), splitWords(b))
+}
+
+// tokenChanges diffs two token sequences and converts the changes
+// to byte offsets into the concatenated tokens.
+func tokenChanges(a, b []string, opts ...Option) []Change {
+	return byteOffsets(a, b, lineChanges(a, b, opts...))
+}
+
+// byteOffsets converts changes of tokens to byte offsets in place.
+func byteOffsets(a, b []string, changes []Change) []Change {
+	oa, ob := offsets(a), offsets(b)
+	for i, c := range changes {
+		changes[i] = Change{
+			A: oa[c.A], B: ob[c.B],
+			Del: oa[c.A+c.Del] - oa[c.A],
+			Ins: ob[c.B+c.Ins] - ob[c.B],
+		}
+	}
+	return changes
+}
+
+// offsets returns the byte offset of every token and the total length.
+func offsets(tokens []string) []int {
+	off := make([]int, len(tokens)+1)
+	for i, t := range tokens {
+		off[i+1] = off[i] + len(t)
+	}
+	return off
+}
+
+const (
+	wordClass = iota
+	spaceClass
+	otherClass
+)
+
+func runeClass(r rune) int {
+	switch {
+	case unicode.IsLetter(r), unicode.IsDigit(r), unicode.IsMark(r), r == '_':
+		return wordClass
+	case unicode.IsSpace(r):
+		return spaceClass
+	}
+	return otherClass
+}
+
+// splitWords splits s into words, runs of white space and other single characters.
+func splitWords(s string) []string {
+	var tokens []string
+	for start := 0; start < len(s); {
+		r, size := utf8.DecodeRuneInString(s[start:])
+		class := runeClass(r)
+		end := start + size
+		for class != otherClass && end < len(s) {
+			r, size := utf8.DecodeRuneInString(s[end:])
+			if runeClass(r) != class {
+				break
+			}
+			end += size
+		}
+		tokens = append(tokens, s[start:end])
+		start = end
+	}
+	return tokens
+}
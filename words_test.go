@@ -0,0 +1,48 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestWords(t *testing.T) {
+	a := "the quick brown fox, jumps"
+	b := "the quack brown fox; jumps high"
+	res := diff.Words(a, b)
+	echange := []diff.Change{
+		{A: 4, B: 4, Del: 5, Ins: 5},
+		{A: 19, B: 19, Del: 1, Ins: 1},
+		{A: 26, B: 26, Del: 0, Ins: 5},
+	}
+	if !diffsEqual(res, echange) {
+		t.Error("expected", echange, "got", res)
+	}
+}
+
+func TestWordsUnicode(t *testing.T) {
+	a := "grüße an alle"
+	b := "grüße, an Alle"
+	res := diff.Words(a, b)
+	echange := []diff.Change{
+		{A: 7, B: 7, Del: 0, Ins: 1},
+		{A: 11, B: 12, Del: 4, Ins: 4},
+	}
+	if !diffsEqual(res, echange) {
+		t.Error("expected", echange, "got", res)
+	}
+}
+
+func TestWordsEmpty(t *testing.T) {
+	if res := diff.Words("", ""); len(res) != 0 {
+		t.Error("expected no changes, got", res)
+	}
+	res := diff.Words("", "two words")
+	if expect := []diff.Change{{A: 0, B: 0, Del: 0, Ins: 9}}; !diffsEqual(res, expect) {
+		t.Error("expected", expect, "got", res)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff
+
+// wu marks the differences of data with lengths n and m using the algorithm
+// described in "An O(NP) Sequence Comparison Algorithm", Sun Wu, Udi Manber,
+// Gene Myers, Information Processing Letters Vol. 35 No. 6, 1990, pp. 317-323.
+// P is the number of deletions from the shorter sequence, which makes it
+// much faster than Myers when the lengths differ a lot.
+func (c *context) wu(n, m int) {
+	// x indexes the shorter sequence of length mx, y the longer one of length ny
+	mx, ny := n, m
+	equal := c.data.Equal
+	del, ins := byte(1), byte(2)
+	if n > m {
+		mx, ny = m, n
+		equal = func(x, y int) bool { return c.data.Equal(y, x) }
+		del, ins = ins, del
+	}
+	// a snake from the point after an edit to the furthest equal element
+	type snake struct{ x, y, endx, endy, prev int }
+	var snakes []snake
+	delta := ny - mx
+	off := mx + 1
+	fp := make([]int, mx+ny+3)   // furthest y on every diagonal k=y-x
+	path := make([]int, mx+ny+3) // index of the last snake on every diagonal
+	for i := range fp {
+		fp[i], path[i] = -1, -1
+	}
+	extend := func(k int) {
+		// continue from the furthest of the neighboring diagonals
+		y, prev := -1, -1
+		if k == 0 && fp[off] == -1 {
+			y = 0 // origin
+		}
+		if f := fp[off+k-1]; f >= 0 && f+1 <= ny && f+1-k <= mx && f+1 > y {
+			y, prev = f+1, path[off+k-1]
+		}
+		if f := fp[off+k+1]; f >= 0 && f-k <= mx && f >= y {
+			y, prev = f, path[off+k+1]
+		}
+		if y < 0 {
+			return
+		}
+		x := y - k
+		s := snake{x: x, y: y, prev: prev}
+		for x < mx && y < ny && equal(x, y) {
+			x++
+			y++
+		}
+		s.endx, s.endy = x, y
+		snakes = append(snakes, s)
+		fp[off+k] = y
+		path[off+k] = len(snakes) - 1
+	}
+	// fp, path and the snakes of five ints each
+	base := c.baseMemory(n, m) + 2*8*len(fp)
+	for p := 0; fp[off+delta] != ny; p++ {
+		c.maxD = delta + 2*p
+		if c.canceled() || c.exceeds(base+5*8*cap(snakes)) {
+			for i := range c.flags {
+				c.flags[i] = 3
+			}
+			return
+		}
+		for k := -p; k < delta; k++ {
+			extend(k)
+		}
+		for k := delta + p; k > delta; k-- {
+			extend(k)
+		}
+		extend(delta)
+	}
+	// walk back from the end, every snake but the first follows one edit
+	for s := snakes[path[off+delta]]; s.prev >= 0; {
+		prev := snakes[s.prev]
+		if s.y == prev.endy+1 {
+			c.flags[prev.endy] |= ins
+		} else {
+			c.flags[prev.endx] |= del
+		}
+		s = prev
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diff_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/echlebek/diff"
+)
+
+func TestWu(t *testing.T) {
+	for _, test := range tests {
+		expect := diff.Ints(test.a, test.b)
+		res := diff.Diff(len(test.a), len(test.b), &ints{test.a, test.b}, diff.WithAlgorithm(diff.Wu))
+		if diff.Check(test.a, test.b, res) != nil {
+			t.Error(test.name, "result", res, "does not transform a into b")
+		}
+		if countEdits(res) != countEdits(expect) {
+			t.Error(test.name, "expected", expect, "got", res)
+		}
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		a := randomInts(r, r.Intn(60), 4)
+		b := randomInts(r, r.Intn(60), 4)
+		data := &ints{a, b}
+		minimal := diff.Diff(len(a), len(b), data)
+		res := diff.Diff(len(a), len(b), data, diff.WithAlgorithm(diff.Wu))
+		if diff.Check(a, b, res) != nil {
+			t.Fatal(a, b, "result", res, "does not transform a into b")
+		}
+		if countEdits(res) != countEdits(minimal) {
+			t.Fatal(a, b, "expected", minimal, "got", res)
+		}
+	}
+}
+
+func BenchmarkWu(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	d := &ints{randomInts(r, 200, 10), randomInts(r, 6000, 10)}
+	for i := 0; i < b.N; i++ {
+		diff.Diff(len(d.a), len(d.b), d, diff.WithAlgorithm(diff.Wu))
+	}
+}
+
+func BenchmarkMyersUnequal(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	d := &ints{randomInts(r, 200, 10), randomInts(r, 6000, 10)}
+	for i := 0; i < b.N; i++ {
+		diff.Diff(len(d.a), len(d.b), d)
+	}
+}
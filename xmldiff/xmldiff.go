@@ -0,0 +1,311 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package xmldiff compares the structure of two XML documents.
+package xmldiff
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/echlebek/diff"
+)
+
+// A Status describes how a node differs between two documents.
+type Status int
+
+const (
+	Added    Status = iota + 1 // only in the second document
+	Removed                    // only in the first document
+	Modified                   // changed attribute value or text
+	// Moved attributes changed their position among the other attributes.
+	Moved
+)
+
+func (s Status) String() string {
+	switch s {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	case Moved:
+		return "moved"
+	}
+	return "unknown"
+}
+
+// A Change describes a node that differs between two documents.
+type Change struct {
+	// Path locates the node like "/config/server[2]/@port" or
+	// "/config/name/text()". Positions are those of the second document,
+	// except for removed nodes.
+	Path   string
+	Status Status
+	// From and To are the values of modified attributes and text nodes.
+	From, To string
+}
+
+// String returns the status and path of the change, for example "M\t/a/@b".
+func (c Change) String() string {
+	return fmt.Sprintf("%c\t%s", "?ADMV"[c.Status], c.Path)
+}
+
+// Options configure Compare.
+type Options struct {
+	// IgnoreAttributeOrder compares attributes regardless of their order.
+	IgnoreAttributeOrder bool
+	// IgnoreWhitespace trims the white space around text and drops
+	// text nodes that only contain white space, like indentation.
+	IgnoreWhitespace bool
+}
+
+// Compare parses the XML documents a and b and returns their differences in
+// document order. Attributes are compared by name and the child elements and
+// text nodes by a diff that pairs replaced elements with the same name.
+// Comments and processing instructions are ignored. opts may be nil.
+func Compare(a, b []byte, opts *Options) ([]Change, error) {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	na, err := parse(a, o.IgnoreWhitespace)
+	if err != nil {
+		return nil, err
+	}
+	nb, err := parse(b, o.IgnoreWhitespace)
+	if err != nil {
+		return nil, err
+	}
+	c := &comparer{opts: o, ids: make(map[*node]int), keys: make(map[string]int)}
+	c.compareChildren("", na, nb)
+	return c.changes, nil
+}
+
+// A node is an element or a text node if name is empty.
+type node struct {
+	name     string
+	attrs    []xml.Attr
+	text     string
+	children []*node
+}
+
+// parse returns a node holding the root elements of the document.
+func parse(data []byte, trim bool) (*node, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	doc := &node{}
+	stack := []*node{doc}
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent := stack[len(stack)-1]
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: qname(tok.Name), attrs: tok.Attr}
+			parent.children = append(parent.children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			if len(stack) == 1 || parent.name != qname(tok.Name) {
+				return nil, fmt.Errorf("xmldiff: line %d: unexpected end element </%s>", lineOf(d, data), qname(tok.Name))
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) == 1 {
+				// text outside of the root element
+				continue
+			}
+			if k := len(parent.children) - 1; k >= 0 && parent.children[k].name == "" {
+				parent.children[k].text += string(tok)
+				continue
+			}
+			parent.children = append(parent.children, &node{text: string(tok)})
+		}
+	}
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("xmldiff: unclosed element <%s>", stack[len(stack)-1].name)
+	}
+	if trim {
+		trimSpace(doc)
+	}
+	return doc, nil
+}
+
+func lineOf(d *xml.Decoder, data []byte) int {
+	return 1 + bytes.Count(data[:d.InputOffset()], []byte("\n"))
+}
+
+func qname(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+// trimSpace trims the text nodes below n and drops empty ones.
+func trimSpace(n *node) {
+	kept := n.children[:0]
+	for _, c := range n.children {
+		if c.name == "" {
+			if c.text = strings.TrimSpace(c.text); c.text == "" {
+				continue
+			}
+		}
+		trimSpace(c)
+		kept = append(kept, c)
+	}
+	n.children = kept
+}
+
+type comparer struct {
+	opts    Options
+	changes []Change
+	// ids and keys memoize the fingerprints of nodes
+	ids  map[*node]int
+	keys map[string]int
+}
+
+func (c *comparer) add(path string, s Status, from, to string) {
+	c.changes = append(c.changes, Change{Path: path, Status: s, From: from, To: to})
+}
+
+func (c *comparer) compare(path string, a, b *node) {
+	if a.name == "" {
+		if a.text != b.text {
+			c.add(path, Modified, a.text, b.text)
+		}
+		return
+	}
+	c.compareAttrs(path, a.attrs, b.attrs)
+	c.compareChildren(path, a, b)
+}
+
+func (c *comparer) compareAttrs(path string, a, b []xml.Attr) {
+	for _, ch := range diff.Keyed(a, b, attrName, func(x, y xml.Attr) bool { return x.Value == y.Value }) {
+		switch {
+		case ch.Removed():
+			c.add(path+"/@"+attrName(a[ch.A]), Removed, a[ch.A].Value, "")
+		case ch.Added():
+			c.add(path+"/@"+attrName(b[ch.B]), Added, "", b[ch.B].Value)
+		default:
+			p := path + "/@" + attrName(b[ch.B])
+			if ch.Moved && !c.opts.IgnoreAttributeOrder {
+				c.add(p, Moved, a[ch.A].Value, b[ch.B].Value)
+			}
+			if ch.Modified {
+				c.add(p, Modified, a[ch.A].Value, b[ch.B].Value)
+			}
+		}
+	}
+}
+
+func attrName(a xml.Attr) string { return qname(a.Name) }
+
+func (c *comparer) compareChildren(path string, a, b *node) {
+	pa, pb := childPaths(path, a.children), childPaths(path, b.children)
+	x, y := 0, 0
+	for _, ch := range diff.Diff(len(a.children), len(b.children), &nodes{a.children, b.children, c.equal}) {
+		for ; x < ch.A; x, y = x+1, y+1 {
+			// equal elements may still differ in attribute order
+			c.compare(pb[y], a.children[x], b.children[y])
+		}
+		// pair replaced nodes of the same kind and name
+		i, j := ch.A, ch.B
+		for _, r := range diff.Diff(ch.Del, ch.Ins, &nodes{a.children[i : i+ch.Del], b.children[j : j+ch.Ins], sameName}) {
+			for ; x < i+r.A; x, y = x+1, y+1 {
+				c.compare(pb[y], a.children[x], b.children[y])
+			}
+			for ; x < i+r.A+r.Del; x++ {
+				c.add(pa[x], Removed, a.children[x].text, "")
+			}
+			for ; y < j+r.B+r.Ins; y++ {
+				c.add(pb[y], Added, "", b.children[y].text)
+			}
+		}
+		for ; x < ch.A+ch.Del; x, y = x+1, y+1 {
+			c.compare(pb[y], a.children[x], b.children[y])
+		}
+	}
+	for ; x < len(a.children); x, y = x+1, y+1 {
+		c.compare(pb[y], a.children[x], b.children[y])
+	}
+}
+
+// childPaths returns the XPath of every child like "/a/b[2]" or "/a/text()".
+// The position is only written for nodes with siblings of the same name.
+func childPaths(path string, children []*node) []string {
+	count := make(map[string]int)
+	for _, n := range children {
+		count[n.name]++
+	}
+	seen := make(map[string]int)
+	paths := make([]string, len(children))
+	for i, n := range children {
+		step := n.name
+		if step == "" {
+			step = "text()"
+		}
+		seen[n.name]++
+		if count[n.name] > 1 {
+			step += "[" + strconv.Itoa(seen[n.name]) + "]"
+		}
+		paths[i] = path + "/" + step
+	}
+	return paths
+}
+
+type nodes struct {
+	a, b  []*node
+	equal func(a, b *node) bool
+}
+
+func (d *nodes) Equal(i, j int) bool { return d.equal(d.a[i], d.b[j]) }
+
+func sameName(a, b *node) bool { return a.name == b.name }
+
+// equal reports whether the nodes are equal as configured.
+func (c *comparer) equal(a, b *node) bool {
+	return c.fingerprint(a) == c.fingerprint(b)
+}
+
+// fingerprint returns a number that is the same for nodes without
+// differences. It is computed once per node from the fingerprints
+// of its children, so comparing nested nodes stays linear.
+func (c *comparer) fingerprint(n *node) int {
+	if id, ok := c.ids[n]; ok {
+		return id
+	}
+	buf := strconv.AppendQuote(nil, n.name)
+	if n.name == "" {
+		buf = strconv.AppendQuote(buf, n.text)
+	}
+	attrs := make([]string, len(n.attrs))
+	for i, a := range n.attrs {
+		attrs[i] = strconv.Quote(attrName(a)) + "=" + strconv.Quote(a.Value)
+	}
+	if c.opts.IgnoreAttributeOrder {
+		sort.Strings(attrs)
+	}
+	buf = append(buf, strings.Join(attrs, " ")...)
+	for _, child := range n.children {
+		buf = strconv.AppendInt(append(buf, ','), int64(c.fingerprint(child)), 10)
+	}
+	id, ok := c.keys[string(buf)]
+	if !ok {
+		id = len(c.keys)
+		c.keys[string(buf)] = id
+	}
+	c.ids[n] = id
+	return id
+}
@@ -0,0 +1,111 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xmldiff_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/echlebek/diff/xmldiff"
+)
+
+const config = `<?xml version="1.0"?>
+<config version="1">
+  <name>web</name>
+  <server host="a" port="80"/>
+  <server host="b" port="80"/>
+  <!-- disabled -->
+  <log level="info"/>
+</config>
+`
+
+const changed = `<?xml version="1.0"?>
+<config version="2">
+  <name>api</name>
+  <server port="80" host="a"/>
+  <server host="b" port="8080" tls="on"/>
+  <server host="c" port="80"/>
+</config>
+`
+
+func changes(t *testing.T, a, b string, opts *xmldiff.Options) string {
+	t.Helper()
+	res, err := xmldiff.Compare([]byte(a), []byte(b), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := make([]string, len(res))
+	for i, c := range res {
+		lines[i] = c.String() + "\t" + c.From + "\t" + c.To
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestCompare(t *testing.T) {
+	expect := strings.Join([]string{
+		"M\t/config/@version\t1\t2",
+		"M\t/config/name/text()\tweb\tapi",
+		"V\t/config/server[1]/@port\t80\t80",
+		"M\t/config/server[2]/@port\t80\t8080",
+		"A\t/config/server[2]/@tls\t\ton",
+		"D\t/config/log\t\t",
+		"A\t/config/server[3]\t\t",
+	}, "\n")
+	if res := changes(t, config, changed, &xmldiff.Options{IgnoreWhitespace: true}); res != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, res)
+	}
+	expect = strings.Join([]string{
+		"M\t/config/@version\t1\t2",
+		"M\t/config/name/text()\tweb\tapi",
+		"M\t/config/server[2]/@port\t80\t8080",
+		"A\t/config/server[2]/@tls\t\ton",
+		"D\t/config/log\t\t",
+		"A\t/config/server[3]\t\t",
+	}, "\n")
+	if res := changes(t, config, changed, &xmldiff.Options{IgnoreWhitespace: true, IgnoreAttributeOrder: true}); res != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, res)
+	}
+}
+
+func TestCompareWhitespace(t *testing.T) {
+	a := "<a><b>x</b></a>"
+	b := "<a>\n  <b> x </b>\n</a>"
+	if res := changes(t, a, b, &xmldiff.Options{IgnoreWhitespace: true}); res != "" {
+		t.Error("expected no changes, got", res)
+	}
+	expect := strings.Join([]string{
+		"A\t/a/text()[1]\t\t\n  ",
+		"M\t/a/b/text()\tx\t x ",
+		"A\t/a/text()[2]\t\t\n",
+	}, "\n")
+	if res := changes(t, a, b, nil); res != expect {
+		t.Errorf("expected %q, got %q", expect, res)
+	}
+}
+
+func TestCompareDeep(t *testing.T) {
+	// nested elements used to be compared again at every level
+	a, b := "1", "2"
+	for i := 0; i < 30; i++ {
+		a, b = "<a><x/>"+a+"<y/></a>", "<a><x/>"+b+"<y/></a>"
+	}
+	start := time.Now()
+	res := changes(t, a, b, nil)
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("took %v", d)
+	}
+	if expect := "M\t" + strings.Repeat("/a", 30) + "/text()\t1\t2"; res != expect {
+		t.Errorf("expected %q, got %q", expect, res)
+	}
+}
+
+func TestCompareErrors(t *testing.T) {
+	for _, doc := range []string{"<a><b></a>", "<a>", "<a></b>"} {
+		if _, err := xmldiff.Compare([]byte(doc), []byte("<a/>"), nil); err == nil {
+			t.Error("expected an error for", doc)
+		}
+	}
+}
@@ -0,0 +1,422 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package yamldiff compares the structure of two YAML documents.
+package yamldiff
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/echlebek/diff"
+	"gopkg.in/yaml.v3"
+)
+
+// A Status describes how a node differs between two documents.
+type Status int
+
+const (
+	Added    Status = iota + 1 // only in the second document
+	Removed                    // only in the first document
+	Modified                   // changed value, kind or comments
+	// Moved mapping keys changed their position among the other keys.
+	Moved
+)
+
+func (s Status) String() string {
+	switch s {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	case Moved:
+		return "moved"
+	}
+	return "unknown"
+}
+
+// A Change describes a node that differs between two documents.
+type Change struct {
+	// Path locates the node like "spec.containers[0].image".
+	// Sequence indices are those of the second document,
+	// except for removed items. The root has the empty path.
+	Path   string
+	Status Status
+	// From and To are the nodes in the first and second document,
+	// From is nil for added nodes and To for removed ones.
+	From, To *yaml.Node
+}
+
+// String returns the status and path of the change, for example "M\tspec.replicas".
+func (c Change) String() string {
+	return fmt.Sprintf("%c\t%s", "?ADMV"[c.Status], c.Path)
+}
+
+// Options configure Compare.
+type Options struct {
+	// IgnoreOrder compares mappings regardless of the order of their keys.
+	IgnoreOrder bool
+	// IgnoreComments ignores changed comments.
+	IgnoreComments bool
+}
+
+// Compare parses the YAML documents of a and b and returns their
+// differences in document order, with the removed keys of a mapping first.
+// If a or b holds more than one document, the documents are compared
+// pairwise and the paths start with the index of the document, like
+// "1:spec.replicas"; extra documents are added or removed as a whole.
+// Mappings are compared by key, sequences item by item with a diff that
+// pairs replaced mappings sharing at least half of their scalar values.
+// Scalars are equal if their tags and values are,
+// regardless of their style. opts may be nil.
+func Compare(a, b []byte, opts *Options) ([]Change, error) {
+	da, err := documents(a)
+	if err != nil {
+		return nil, err
+	}
+	db, err := documents(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(da) == 1 && len(db) == 1 {
+		return CompareNodes(da[0], db[0], opts), nil
+	}
+	var changes []Change
+	for i := 0; i < len(da) || i < len(db); i++ {
+		prefix := strconv.Itoa(i) + ":"
+		switch {
+		case i >= len(da):
+			changes = append(changes, Change{Path: prefix, Status: Added, To: resolve(db[i])})
+		case i >= len(db):
+			changes = append(changes, Change{Path: prefix, Status: Removed, From: resolve(da[i])})
+		default:
+			for _, c := range CompareNodes(da[i], db[i], opts) {
+				c.Path = prefix + c.Path
+				changes = append(changes, c)
+			}
+		}
+	}
+	return changes, nil
+}
+
+// documents parses all documents of data. Empty data has one empty document.
+func documents(data []byte) ([]*yaml.Node, error) {
+	d := yaml.NewDecoder(bytes.NewReader(data))
+	var docs []*yaml.Node
+	for {
+		n := new(yaml.Node)
+		err := d.Decode(n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, n)
+	}
+	if len(docs) == 0 {
+		docs = append(docs, new(yaml.Node))
+	}
+	return docs, nil
+}
+
+// CompareNodes returns the differences of two parsed YAML nodes like Compare.
+func CompareNodes(a, b *yaml.Node, opts *Options) []Change {
+	c := &comparer{ids: make(map[*yaml.Node]int), keys: make(map[string]int)}
+	if opts != nil {
+		c.opts = *opts
+	}
+	c.compare("", a, b)
+	return c.changes
+}
+
+type comparer struct {
+	opts    Options
+	changes []Change
+	// ids and keys memoize the fingerprints of nodes
+	ids  map[*yaml.Node]int
+	keys map[string]int
+}
+
+func (c *comparer) add(path string, s Status, from, to *yaml.Node) {
+	c.changes = append(c.changes, Change{Path: path, Status: s, From: from, To: to})
+}
+
+func (c *comparer) compare(path string, a, b *yaml.Node) {
+	a, b = resolve(a), resolve(b)
+	if a == nil || b == nil {
+		if a != b {
+			c.add(path, Modified, a, b)
+		}
+		return
+	}
+	if a.Kind != b.Kind || a.Kind == yaml.ScalarNode && !sameScalar(a, b) || !c.sameComments(a, b) {
+		c.add(path, Modified, a, b)
+		if a.Kind != b.Kind || a.Kind == yaml.ScalarNode {
+			return
+		}
+	}
+	switch a.Kind {
+	case yaml.MappingNode:
+		c.compareMappings(path, a, b)
+	case yaml.SequenceNode:
+		c.compareSequences(path, a, b)
+	}
+}
+
+// entry is a key and value of a mapping.
+type entry struct {
+	key, value *yaml.Node
+}
+
+func entries(n *yaml.Node) []entry {
+	res := make([]entry, 0, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		res = append(res, entry{n.Content[i], n.Content[i+1]})
+	}
+	return res
+}
+
+func (c *comparer) compareMappings(path string, a, b *yaml.Node) {
+	ea, eb := entries(a), entries(b)
+	moved := make(map[int]bool)
+	removed := make(map[int]bool)
+	added := make(map[int]bool)
+	for _, ch := range diff.Keyed(ea, eb, entryKey, c.sameEntry) {
+		switch {
+		case ch.Removed():
+			removed[ch.A] = true
+		case ch.Added():
+			added[ch.B] = true
+		case ch.Moved && !c.opts.IgnoreOrder:
+			moved[ch.B] = true
+		}
+	}
+	for i, e := range ea {
+		if removed[i] {
+			c.add(keyPath(path, e.key), Removed, e.value, nil)
+		}
+	}
+	// entries with unique keys are matched by key, the others were
+	// matched by the diff of the unkeyed entries and are equal
+	countA, countB := make(map[string]int), make(map[string]int)
+	ia := make(map[string]int, len(ea))
+	for i, e := range ea {
+		countA[entryKey(e)]++
+		ia[entryKey(e)] = i
+	}
+	for _, e := range eb {
+		countB[entryKey(e)]++
+	}
+	for j, e := range eb {
+		k := entryKey(e)
+		p := keyPath(path, e.key)
+		switch {
+		case added[j]:
+			c.add(p, Added, nil, e.value)
+		case k != "" && countA[k] == 1 && countB[k] == 1:
+			i := ia[k]
+			if moved[j] {
+				c.add(p, Moved, ea[i].value, e.value)
+			}
+			if !c.sameComments(ea[i].key, e.key) {
+				// comments above a key belong to the key node
+				c.add(p, Modified, ea[i].value, e.value)
+			}
+			c.compare(p, ea[i].value, e.value)
+		}
+	}
+}
+
+func entryKey(e entry) string {
+	k := resolve(e.key)
+	if k == nil || k.Kind != yaml.ScalarNode {
+		// complex keys are compared as unkeyed entries
+		return ""
+	}
+	return k.Value
+}
+
+func (c *comparer) sameEntry(a, b entry) bool {
+	return c.equal(a.key, b.key) && c.equal(a.value, b.value)
+}
+
+func (c *comparer) compareSequences(path string, a, b *yaml.Node) {
+	x, y := 0, 0
+	for _, ch := range diff.Diff(len(a.Content), len(b.Content), &nodes{a.Content, b.Content, c.equal}) {
+		for ; x < ch.A; x, y = x+1, y+1 {
+			// equal items may still differ in comments and order
+			c.compare(indexPath(path, y), a.Content[x], b.Content[y])
+		}
+		c.compareReplaced(path, a.Content[ch.A:ch.A+ch.Del], b.Content[ch.B:ch.B+ch.Ins], ch.A, ch.B)
+		x, y = ch.A+ch.Del, ch.B+ch.Ins
+	}
+	for ; x < len(a.Content); x, y = x+1, y+1 {
+		c.compare(indexPath(path, y), a.Content[x], b.Content[y])
+	}
+}
+
+// compareReplaced compares the items a that were replaced by the items b
+// at the indices x and y. Similar items are compared, the others are
+// paired in order and the rest was removed or added.
+func (c *comparer) compareReplaced(path string, a, b []*yaml.Node, x, y int) {
+	i, j := 0, 0
+	for _, ch := range diff.Diff(len(a), len(b), &nodes{a, b, similar}) {
+		for ; i < ch.A; i, j = i+1, j+1 {
+			c.compare(indexPath(path, y+j), a[i], b[j])
+		}
+		for k := 0; k < ch.Del || k < ch.Ins; k++ {
+			switch {
+			case k < ch.Del && k < ch.Ins:
+				c.compare(indexPath(path, y+ch.B+k), a[ch.A+k], b[ch.B+k])
+			case k < ch.Del:
+				c.add(indexPath(path, x+ch.A+k), Removed, a[ch.A+k], nil)
+			default:
+				c.add(indexPath(path, y+ch.B+k), Added, nil, b[ch.B+k])
+			}
+		}
+		i, j = ch.A+ch.Del, ch.B+ch.Ins
+	}
+	for ; i < len(a); i, j = i+1, j+1 {
+		c.compare(indexPath(path, y+j), a[i], b[j])
+	}
+}
+
+type nodes struct {
+	a, b  []*yaml.Node
+	equal func(a, b *yaml.Node) bool
+}
+
+func (d *nodes) Equal(i, j int) bool { return d.equal(d.a[i], d.b[j]) }
+
+// similar reports whether at least half of the scalar entries of two
+// mappings are equal, like the names of two versions of a container.
+func similar(a, b *yaml.Node) bool {
+	a, b = resolve(a), resolve(b)
+	if a == nil || b == nil || a.Kind != yaml.MappingNode || b.Kind != yaml.MappingNode {
+		return false
+	}
+	values := make(map[string]*yaml.Node)
+	for _, e := range entries(a) {
+		values[entryKey(e)] = resolve(e.value)
+	}
+	same := 0
+	for _, e := range entries(b) {
+		v, w := values[entryKey(e)], resolve(e.value)
+		if v != nil && w != nil && v.Kind == yaml.ScalarNode && w.Kind == yaml.ScalarNode && sameScalar(v, w) {
+			same++
+		}
+	}
+	n := len(a.Content)
+	if len(b.Content) > n {
+		n = len(b.Content)
+	}
+	return same > 0 && 2*same >= n/2
+}
+
+// equal reports whether the nodes are equal ignoring comments and key order
+// as configured, without recording changes.
+func (c *comparer) equal(a, b *yaml.Node) bool {
+	return c.fingerprint(a) == c.fingerprint(b)
+}
+
+// fingerprint returns a number that is the same for nodes without
+// differences. It is computed once per node from the fingerprints
+// of its children, so comparing nested nodes stays linear.
+func (c *comparer) fingerprint(n *yaml.Node) int {
+	n = resolve(n)
+	if id, ok := c.ids[n]; ok {
+		return id
+	}
+	var buf []byte
+	if n != nil {
+		buf = strconv.AppendUint(buf, uint64(n.Kind), 10)
+		if !c.opts.IgnoreComments {
+			buf = strconv.AppendQuote(buf, n.HeadComment)
+			buf = strconv.AppendQuote(buf, n.LineComment)
+			buf = strconv.AppendQuote(buf, n.FootComment)
+		}
+		switch n.Kind {
+		case yaml.ScalarNode:
+			buf = strconv.AppendQuote(buf, n.ShortTag())
+			if n.ShortTag() != "!!null" {
+				buf = strconv.AppendQuote(buf, n.Value)
+			}
+		case yaml.MappingNode:
+			items := make([]string, 0, len(n.Content)/2)
+			for _, e := range entries(n) {
+				items = append(items, fmt.Sprintf("%d:%d", c.fingerprint(e.key), c.fingerprint(e.value)))
+			}
+			if c.opts.IgnoreOrder {
+				sort.Strings(items)
+			}
+			buf = append(buf, strings.Join(items, ",")...)
+		case yaml.SequenceNode:
+			for _, item := range n.Content {
+				buf = strconv.AppendInt(append(buf, ','), int64(c.fingerprint(item)), 10)
+			}
+		}
+	}
+	id, ok := c.keys[string(buf)]
+	if !ok {
+		id = len(c.keys)
+		c.keys[string(buf)] = id
+	}
+	c.ids[n] = id
+	return id
+}
+
+func (c *comparer) sameComments(a, b *yaml.Node) bool {
+	return c.opts.IgnoreComments ||
+		a.HeadComment == b.HeadComment && a.LineComment == b.LineComment && a.FootComment == b.FootComment
+}
+
+func sameScalar(a, b *yaml.Node) bool {
+	if a.ShortTag() != b.ShortTag() {
+		return false
+	}
+	// null, ~ and an empty value are the same
+	return a.ShortTag() == "!!null" || a.Value == b.Value
+}
+
+// resolve returns the content of documents and the target of aliases.
+func resolve(n *yaml.Node) *yaml.Node {
+	for n != nil {
+		switch {
+		case n.Kind == yaml.DocumentNode && len(n.Content) > 0:
+			n = n.Content[0]
+		case n.Kind == yaml.DocumentNode:
+			return nil
+		case n.Kind == yaml.AliasNode:
+			n = n.Alias
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_/-]*$`)
+
+func keyPath(path string, key *yaml.Node) string {
+	k := entryKey(entry{key: key})
+	if !identifier.MatchString(k) {
+		return path + "[" + strconv.Quote(k) + "]"
+	}
+	if path == "" {
+		return k
+	}
+	return path + "." + k
+}
+
+func indexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
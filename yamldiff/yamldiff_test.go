@@ -0,0 +1,147 @@
+// Copyright 2012 Martin Schnabel. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package yamldiff_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/echlebek/diff/yamldiff"
+)
+
+const manifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+    tier: frontend
+spec:
+  replicas: 2 # scaled by hand
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.25
+        - name: sidecar
+          image: envoy:1.28
+      volumes: [config]
+`
+
+const drifted = `kind: Deployment
+apiVersion: apps/v1
+metadata:
+  name: web
+  labels:
+    tier: frontend
+    app: web
+    "team.io/owner": ops
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+        - name: init
+          image: busybox
+        - name: web
+          image: nginx:1.27
+        - name: sidecar
+          image: envoy:1.28
+`
+
+func changes(t *testing.T, a, b string, opts *yamldiff.Options) string {
+	t.Helper()
+	res, err := yamldiff.Compare([]byte(a), []byte(b), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := make([]string, len(res))
+	for i, c := range res {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestCompare(t *testing.T) {
+	expect := strings.Join([]string{
+		"V\tkind",
+		"V\tmetadata.labels.tier",
+		"A\tmetadata.labels[\"team.io/owner\"]",
+		"M\tspec.replicas",
+		"D\tspec.template.spec.volumes",
+		"A\tspec.template.spec.containers[0]",
+		"M\tspec.template.spec.containers[1].image",
+	}, "\n")
+	if res := changes(t, manifest, drifted, nil); res != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, res)
+	}
+	expect = strings.Join([]string{
+		"A\tmetadata.labels[\"team.io/owner\"]",
+		"M\tspec.replicas",
+		"D\tspec.template.spec.volumes",
+		"A\tspec.template.spec.containers[0]",
+		"M\tspec.template.spec.containers[1].image",
+	}, "\n")
+	if res := changes(t, manifest, drifted, &yamldiff.Options{IgnoreOrder: true, IgnoreComments: true}); res != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, res)
+	}
+}
+
+func TestCompareScalars(t *testing.T) {
+	for _, test := range []struct {
+		a, b   string
+		expect string
+	}{
+		{"a: 1", "a: '1'", "M\ta"},
+		{"a: x", "a: \"x\"", ""},
+		{"a: ~", "a: null", ""},
+		{"a: x # one", "a: x # two", "M\ta"},
+		{"# one\na: x", "# two\na: x", "M\ta"},
+		{"- &x 1\n- *x", "- 1\n- 1", ""},
+		{"[1, 2]", "{a: 1}", "M\t"},
+	} {
+		if res := changes(t, test.a, test.b, nil); res != test.expect {
+			t.Errorf("%q %q: expected %q, got %q", test.a, test.b, test.expect, res)
+		}
+	}
+}
+
+func TestCompareDeep(t *testing.T) {
+	// nested sequences used to be compared again at every level
+	a, b := "1", "2"
+	for i := 0; i < 30; i++ {
+		a, b = "[0, "+a+", 1]", "[0, "+b+", 1]"
+	}
+	start := time.Now()
+	res := changes(t, a, b, nil)
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("took %v", d)
+	}
+	if expect := "M\t" + strings.Repeat("[1]", 30); res != expect {
+		t.Errorf("expected %q, got %q", expect, res)
+	}
+}
+
+func TestCompareDocuments(t *testing.T) {
+	a := "kind: Service\n---\nkind: Deployment\nspec:\n  replicas: 2\n"
+	b := "kind: Service\n---\nkind: Deployment\nspec:\n  replicas: 3\n---\nkind: ConfigMap\n"
+	expect := "M\t1:spec.replicas\nA\t2:"
+	if res := changes(t, a, b, nil); res != expect {
+		t.Errorf("expected %q, got %q", expect, res)
+	}
+	if res := changes(t, b, a, nil); res != "M\t1:spec.replicas\nD\t2:" {
+		t.Errorf("expected the last document to be removed, got %q", res)
+	}
+	if res := changes(t, a, a, nil); res != "" {
+		t.Errorf("expected no changes, got %q", res)
+	}
+}
+
+func TestCompareErrors(t *testing.T) {
+	if _, err := yamldiff.Compare([]byte("a: [1"), nil, nil); err == nil {
+		t.Error("expected a syntax error")
+	}
+}